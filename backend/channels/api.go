@@ -0,0 +1,450 @@
+package channels
+
+import (
+	"errors"
+	"live-chat-server/interfaces"
+	"live-chat-server/listeners"
+	"live-chat-server/middleware"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Callback events delivered to the callback URL of API inboxes
+const (
+	APICallbackEventMessageCreated      = "message_created"
+	APICallbackEventConversationUpdated = "conversation_status_changed"
+)
+
+type APIConfigInput struct {
+	CallbackURL              string `json:"callback_url" validate:"omitempty,url"`
+	RegenerateToken          bool   `json:"regenerate_token"`
+	RegenerateCallbackSecret bool   `json:"regenerate_callback_secret"`
+}
+
+type APIContactInput struct {
+	Name    *string `json:"name" validate:"optional=min=2,max=255"`
+	Email   *string `json:"email" validate:"optional=email"`
+	Phone   *string `json:"phone" validate:"optional=min=5,max=50"`
+	Company *string `json:"company" validate:"optional=min=2,max=255"`
+}
+
+type APIChannelStartConversationInput struct {
+	ContactID string `json:"contact_id" validate:"required,uuid"`
+	Content   string `json:"content" validate:"omitempty,max=10000"`
+	Priority  string `json:"priority" validate:"omitempty,oneof=low medium high urgent"`
+}
+
+type APIChannelMessageInput struct {
+	Content string `json:"content" validate:"required,max=10000"`
+}
+
+type APIChannelPriorityInput struct {
+	Priority string `json:"priority" validate:"required,oneof=low medium high urgent"`
+}
+
+// APIChannel implements inboxes fed by an external system over the REST API. Agent replies
+// and status changes are delivered to the inbox's callback URL.
+type APIChannel struct {
+	inboxRepo        repositories.InboxRepository
+	contactRepo      repositories.ContactRepository
+	conversationRepo repositories.ConversationRepository
+	commandFactory   interfaces.CommandFactory
+	dispatcher       interfaces.Dispatcher
+	jobClient        interfaces.JobClient
+	logger           interfaces.Logger
+	langContext      interfaces.LanguageContext
+}
+
+func NewAPIChannel(inboxRepo repositories.InboxRepository, contactRepo repositories.ContactRepository, conversationRepo repositories.ConversationRepository, commandFactory interfaces.CommandFactory, dispatcher interfaces.Dispatcher, jobClient interfaces.JobClient, logger interfaces.Logger, langContext interfaces.LanguageContext) interfaces.Channel {
+	channel := &APIChannel{
+		inboxRepo:        inboxRepo,
+		contactRepo:      contactRepo,
+		conversationRepo: conversationRepo,
+		commandFactory:   commandFactory,
+		dispatcher:       dispatcher,
+		jobClient:        jobClient,
+		logger:           logger.Named("api_channel"),
+		langContext:      langContext,
+	}
+	channel.subscribe()
+	return channel
+}
+
+func (ch *APIChannel) subscribe() {
+	ch.dispatcher.Subscribe(interfaces.EventTypeConversationAssign, ch.HandleConversationStatusChanged)
+	ch.dispatcher.Subscribe(interfaces.EventTypeConversationClose, ch.HandleConversationStatusChanged)
+	ch.dispatcher.Subscribe(interfaces.EventTypeConversationResolve, ch.HandleConversationStatusChanged)
+	ch.dispatcher.Subscribe(interfaces.EventTypeConversationReopen, ch.HandleConversationStatusChanged)
+	ch.dispatcher.Subscribe(interfaces.EventTypeConversationPending, ch.HandleConversationStatusChanged)
+	ch.dispatcher.Subscribe(interfaces.EventTypeConversationSnooze, ch.HandleConversationStatusChanged)
+	ch.dispatcher.Subscribe(interfaces.EventTypeConversationWake, ch.HandleConversationStatusChanged)
+	ch.dispatcher.Subscribe(interfaces.EventTypeConversationPriority, ch.HandleConversationStatusChanged)
+}
+
+func (ch *APIChannel) Type() models.InboxType {
+	return models.InboxTypeAPI
+}
+
+func (ch *APIChannel) LoadConfig(inbox *models.Inbox) error {
+	return loadConfig(ch.inboxRepo, inbox, &models.InboxAPI{})
+}
+
+func (ch *APIChannel) ApplyToPayload(inbox *models.Inbox, payload *types.InboxPayload) {
+	config, ok := inbox.Config().(*models.InboxAPI)
+	if !ok {
+		return
+	}
+
+	payload.Config = types.InboxTypeConfig{
+		"api_callback_url": config.CallbackURL,
+	}
+}
+
+func (ch *APIChannel) ApplyToPublicPayload(inbox *models.Inbox, payload *types.InboxPayload) {}
+
+// ApplySecretsToPayload adds the inbox token and callback secret the external system needs
+func (ch *APIChannel) ApplySecretsToPayload(inbox *models.Inbox, payload *types.InboxPayload) {
+	config, ok := inbox.Config().(*models.InboxAPI)
+	if !ok {
+		return
+	}
+
+	if payload.Config == nil {
+		payload.Config = types.InboxTypeConfig{}
+	}
+	payload.Config["api_inbox_token"] = config.Token
+	payload.Config["api_callback_secret"] = config.CallbackSecret
+}
+
+func (ch *APIChannel) CreateConfig(c *fiber.Ctx) (models.InboxConfig, error) {
+	var input struct {
+		API *APIConfigInput `json:"api" validate:"omitempty"`
+	}
+	if err := parseConfigInput(c, &input); err != nil {
+		return nil, err
+	}
+
+	config := &models.InboxAPI{}
+	if input.API != nil {
+		config.CallbackURL = input.API.CallbackURL
+	}
+
+	if err := generateAPIInboxSecrets(config, true, true); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (ch *APIChannel) UpdateConfig(c *fiber.Ctx, inbox *models.Inbox) (models.InboxConfig, error) {
+	var input struct {
+		API *APIConfigInput `json:"api" validate:"omitempty"`
+	}
+	if err := parseConfigInput(c, &input); err != nil {
+		return nil, err
+	}
+
+	if input.API == nil {
+		return nil, nil
+	}
+
+	config, ok := inbox.Config().(*models.InboxAPI)
+	if !ok {
+		config = &models.InboxAPI{
+			InboxID: inbox.ID,
+		}
+	}
+
+	config.CallbackURL = input.API.CallbackURL
+
+	if err := generateAPIInboxSecrets(config, input.API.RegenerateToken || config.Token == "", input.API.RegenerateCallbackSecret || config.CallbackSecret == ""); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (ch *APIChannel) SampleConfig(inbox *models.Inbox) models.InboxConfig {
+	config := &models.InboxAPI{
+		CallbackURL: "https://example.com/talkdeskly/callback",
+	}
+
+	if err := generateAPIInboxSecrets(config, true, true); err != nil {
+		ch.logger.Error("Failed to generate API inbox secrets: %v", err)
+	}
+
+	return config
+}
+
+func (ch *APIChannel) Deliver(conversation *models.Conversation, message *models.Message) error {
+	return ch.enqueueCallback(conversation.InboxID, APICallbackEventMessageCreated, map[string]interface{}{
+		"conversation_id": conversation.ID,
+		"contact_id":      conversation.ContactID,
+		"message":         message.ToPayload(),
+	})
+}
+
+func (ch *APIChannel) RegisterRoutes(router fiber.Router) {
+	channelGroup := router.Group("/channel", middleware.InboxAPIAuth())
+	channelGroup.Post("/contacts", ch.HandleCreateContact)
+	channelGroup.Get("/contacts/:id", ch.HandleGetContact)
+	channelGroup.Post("/conversations", ch.HandleStartConversation)
+	channelGroup.Get("/conversations/:id", ch.HandleGetConversation)
+	channelGroup.Post("/conversations/:id/messages", ch.HandleCreateMessage)
+	channelGroup.Put("/conversations/:id/priority", ch.HandleSetPriority)
+}
+
+func (ch *APIChannel) HandleConversationStatusChanged(event interfaces.Event) {
+	conversation, ok := event.Payload.(*models.Conversation)
+	if !ok {
+		return
+	}
+
+	inboxType := conversation.Inbox.Type
+	if conversation.Inbox.ID == "" {
+		inbox, err := ch.inboxRepo.GetInboxByID(conversation.InboxID)
+		if err != nil {
+			ch.logger.Error("Failed to get inbox %s: %v", conversation.InboxID, err)
+			return
+		}
+		inboxType = inbox.Type
+	}
+
+	if inboxType != models.InboxTypeAPI {
+		return
+	}
+
+	if err := ch.enqueueCallback(conversation.InboxID, APICallbackEventConversationUpdated, map[string]interface{}{
+		"conversation_id": conversation.ID,
+		"contact_id":      conversation.ContactID,
+		"status":          conversation.Status,
+		"priority":        conversation.Priority,
+		"assigned_to_id":  conversation.AssignedToID,
+	}); err != nil {
+		ch.logger.Error("Failed to enqueue status callback for conversation %s: %v", conversation.ID, err)
+	}
+}
+
+func (ch *APIChannel) enqueueCallback(inboxID string, event string, data map[string]interface{}) error {
+	return ch.jobClient.Enqueue("deliver_api_callback", map[string]interface{}{
+		"inbox_id": inboxID,
+		"event":    event,
+		"data":     data,
+	})
+}
+
+// generateAPIInboxSecrets generates a new inbox token and/or callback secret for an API inbox
+func generateAPIInboxSecrets(api *models.InboxAPI, token bool, callbackSecret bool) error {
+	if token {
+		value, err := utils.GenerateSecureToken(24)
+		if err != nil {
+			return err
+		}
+		api.Token = value
+	}
+
+	if callbackSecret {
+		value, err := utils.GenerateSecureToken(32)
+		if err != nil {
+			return err
+		}
+		api.CallbackSecret = value
+	}
+
+	return nil
+}
+
+func (ch *APIChannel) HandleCreateContact(c *fiber.Ctx) error {
+	var input APIContactInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, ch.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	inbox := middleware.GetAPIInbox(c)
+
+	contact := models.Contact{
+		Name:      input.Name,
+		Email:     input.Email,
+		Phone:     input.Phone,
+		Company:   input.Company,
+		CompanyID: inbox.CompanyID,
+	}
+
+	if err := ch.contactRepo.CreateContact(&contact); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, ch.langContext.T(c, "failed_to_create_contact"), err)
+	}
+
+	ch.dispatcher.Dispatch(interfaces.EventTypeContactCreated, &listeners.ContactCreatedPayload{
+		Contact: &contact,
+	})
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, ch.langContext.T(c, "contact_created"), contact.ToResponse())
+}
+
+func (ch *APIChannel) HandleGetContact(c *fiber.Ctx) error {
+	inbox := middleware.GetAPIInbox(c)
+
+	contact, err := ch.contactRepo.GetContactByIDAndCompanyID(c.Params("id"), inbox.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, ch.langContext.T(c, "contact_not_found"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, ch.langContext.T(c, "contact_found"), contact.ToResponse())
+}
+
+func (ch *APIChannel) HandleStartConversation(c *fiber.Ctx) error {
+	var input APIChannelStartConversationInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, ch.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	inbox := middleware.GetAPIInbox(c)
+
+	contact, err := ch.contactRepo.GetContactByIDAndCompanyID(input.ContactID, inbox.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, ch.langContext.T(c, "contact_not_found"), err)
+	}
+
+	conversation := &models.Conversation{
+		InboxID:   inbox.ID,
+		ContactID: contact.ID,
+		CompanyID: inbox.CompanyID,
+		Status:    models.ConversationStatusPending,
+		Priority:  models.ConversationPriority(input.Priority),
+	}
+
+	if err := ch.conversationRepo.CreateConversation(conversation); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, ch.langContext.T(c, "failed_to_create_conversation"), err)
+	}
+
+	conversation, err = ch.conversationRepo.GetConversationByID(conversation.ID, "Inbox", "Contact", "AssignedTo")
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, ch.langContext.T(c, "failed_to_get_conversation"), err)
+	}
+
+	// The first message is saved before the inbox features run, so it comes before the auto-responder's
+	if input.Content != "" {
+		if _, err := ch.storeContactMessage(conversation, input.Content); err != nil {
+			return utils.ErrorResponse(c, fiber.StatusInternalServerError, ch.langContext.T(c, "failed_to_create_message"), err)
+		}
+	}
+
+	if _, err := ch.commandFactory.NewHandleInboxFeaturesCommand(conversation, inbox).Handle(); err != nil {
+		ch.logger.Error("Failed to handle inbox features: %v", err)
+	}
+
+	ch.dispatcher.Dispatch(interfaces.EventTypeConversationStart, conversation)
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, ch.langContext.T(c, "conversation_created"), conversation.ToPayloadWithoutPrivateMessages())
+}
+
+func (ch *APIChannel) HandleGetConversation(c *fiber.Ctx) error {
+	conversation, err := ch.getConversation(c, "Messages", "Inbox", "Contact", "AssignedTo")
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, ch.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, ch.langContext.T(c, "conversation_found"), conversation.ToPayloadWithoutPrivateMessages())
+}
+
+func (ch *APIChannel) HandleCreateMessage(c *fiber.Ctx) error {
+	var input APIChannelMessageInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, ch.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	conversation, err := ch.getConversation(c, "Inbox", "Contact", "AssignedTo")
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, ch.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	if conversation.IsClosed() {
+		return utils.ErrorResponse(c, fiber.StatusConflict, ch.langContext.T(c, "conversation_is_closed"), nil)
+	}
+
+	message, err := ch.storeContactMessage(conversation, input.Content)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, ch.langContext.T(c, "failed_to_create_message"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, ch.langContext.T(c, "message_created"), message.ToPayload())
+}
+
+func (ch *APIChannel) HandleSetPriority(c *fiber.Ctx) error {
+	var input APIChannelPriorityInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, ch.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	conversation, err := ch.getConversation(c, "Inbox", "Contact", "AssignedTo")
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, ch.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	if _, err := ch.commandFactory.NewSetConversationPriorityCommand(conversation, models.ConversationPriority(input.Priority), nil).Handle(); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, ch.langContext.T(c, "failed_to_update_conversation"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, ch.langContext.T(c, "conversation_priority_updated"), conversation.ToPayloadWithoutPrivateMessages())
+}
+
+// getConversation returns the conversation of the authenticated API inbox named in the route
+func (ch *APIChannel) getConversation(c *fiber.Ctx, preloads ...string) (*models.Conversation, error) {
+	inbox := middleware.GetAPIInbox(c)
+
+	conversation, err := ch.conversationRepo.GetConversationByID(c.Params("id"), preloads...)
+	if err != nil {
+		return nil, err
+	}
+
+	if conversation.InboxID != inbox.ID {
+		return nil, errors.New("conversation not found")
+	}
+
+	return conversation, nil
+}
+
+// storeContactMessage saves a message from the conversation's contact and announces it
+func (ch *APIChannel) storeContactMessage(conversation *models.Conversation, content string) (*models.Message, error) {
+	stored, err := ch.conversationRepo.CreateMessages([]*models.Message{{
+		ConversationID: conversation.ID,
+		SenderType:     models.SenderTypeContact,
+		SenderID:       &conversation.ContactID,
+		Content:        content,
+		Type:           models.MessageTypeText,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	message := stored[0]
+	conversation.LastMessage = message.Content
+	conversation.LastMessageAt = &message.CreatedAt
+
+	ch.dispatcher.Dispatch(interfaces.EventTypeConversationMessagesStored, &listeners.MessagesStoredPayload{
+		Conversation: conversation,
+		Messages:     stored,
+	})
+
+	return message, nil
+}
@@ -0,0 +1,46 @@
+package channels
+
+import (
+	"errors"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// parseConfigInput parses the channel settings of an inbox request into the input and validates them
+func parseConfigInput(c *fiber.Ctx, input interface{}) error {
+	if err := c.BodyParser(input); err != nil {
+		return &interfaces.ChannelConfigError{Key: "bad_request"}
+	}
+
+	if errs := utils.ValidateStruct(input); errs != nil {
+		return &interfaces.ChannelConfigError{Key: "validation_failed", Errors: errs}
+	}
+
+	return nil
+}
+
+// enqueueReply enqueues the job which sends an agent message through the channel's provider
+func enqueueReply(jobClient interfaces.JobClient, jobName string, messageID string) error {
+	return jobClient.Enqueue(jobName, map[string]interface{}{
+		"message_id": messageID,
+	})
+}
+
+// loadConfig loads the configuration of the inbox into config and sets it on the inbox,
+// leaving inboxes which have none without a configuration
+func loadConfig(inboxRepo repositories.InboxRepository, inbox *models.Inbox, config models.InboxConfig) error {
+	if err := inboxRepo.GetInboxConfig(inbox.ID, config); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	inbox.SetConfig(config)
+	return nil
+}
@@ -0,0 +1,136 @@
+package channels
+
+import (
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type EmailConfigInput struct {
+	ImapServer string `json:"imap_server" validate:"required,hostname_rfc1123|ip"`
+	ImapPort   int    `json:"imap_port" validate:"required,min=1,max=65535"`
+	SmtpServer string `json:"smtp_server" validate:"required,hostname_rfc1123|ip"`
+	SmtpPort   int    `json:"smtp_port" validate:"required,min=1,max=65535"`
+	Username   string `json:"username" validate:"required,email"`
+	Password   string `json:"password" validate:"omitempty,max=255"`
+}
+
+type EmailInboxInput struct {
+	Email *EmailConfigInput `json:"email" validate:"required"`
+}
+
+// EmailChannel implements inboxes backed by a mailbox. Inbound emails are fetched by the
+// poll_email_inboxes job and agent replies are sent through the inbox's SMTP server.
+type EmailChannel struct {
+	inboxRepo repositories.InboxRepository
+	jobClient interfaces.JobClient
+}
+
+func NewEmailChannel(inboxRepo repositories.InboxRepository, jobClient interfaces.JobClient) interfaces.Channel {
+	return &EmailChannel{
+		inboxRepo: inboxRepo,
+		jobClient: jobClient,
+	}
+}
+
+func (ch *EmailChannel) Type() models.InboxType {
+	return models.InboxTypeEmail
+}
+
+func (ch *EmailChannel) LoadConfig(inbox *models.Inbox) error {
+	return loadConfig(ch.inboxRepo, inbox, &models.InboxEmail{})
+}
+
+func (ch *EmailChannel) ApplyToPayload(inbox *models.Inbox, payload *types.InboxPayload) {
+	config, ok := inbox.Config().(*models.InboxEmail)
+	if !ok {
+		return
+	}
+
+	payload.Config = types.InboxTypeConfig{
+		"imap_server": config.ImapServer,
+		"imap_port":   config.ImapPort,
+		"smtp_server": config.SmtpServer,
+		"smtp_port":   config.SmtpPort,
+		"username":    config.Username,
+	}
+}
+
+func (ch *EmailChannel) ApplyToPublicPayload(inbox *models.Inbox, payload *types.InboxPayload) {
+	// Mail server details are only of use to agents
+}
+
+func (ch *EmailChannel) CreateConfig(c *fiber.Ctx) (models.InboxConfig, error) {
+	var input EmailInboxInput
+	if err := parseConfigInput(c, &input); err != nil {
+		return nil, err
+	}
+
+	if input.Email.Password == "" {
+		return nil, &interfaces.ChannelConfigError{Key: "email_config_required"}
+	}
+
+	config := &models.InboxEmail{}
+	applyEmailConfigInput(config, input.Email)
+
+	return config, nil
+}
+
+func (ch *EmailChannel) UpdateConfig(c *fiber.Ctx, inbox *models.Inbox) (models.InboxConfig, error) {
+	var input struct {
+		Email *EmailConfigInput `json:"email" validate:"omitempty"`
+	}
+	if err := parseConfigInput(c, &input); err != nil {
+		return nil, err
+	}
+
+	if input.Email == nil {
+		return nil, nil
+	}
+
+	config, ok := inbox.Config().(*models.InboxEmail)
+	if !ok {
+		config = &models.InboxEmail{
+			InboxID: inbox.ID,
+		}
+	}
+
+	applyEmailConfigInput(config, input.Email)
+
+	return config, nil
+}
+
+func (ch *EmailChannel) SampleConfig(inbox *models.Inbox) models.InboxConfig {
+	return &models.InboxEmail{
+		ImapServer: "imap.example.com",
+		ImapPort:   993,
+		Username:   fmt.Sprintf("inbox-%s@example.com", inbox.ID[:8]),
+		Password:   "encrypted_password",
+		SmtpServer: "smtp.example.com",
+		SmtpPort:   587,
+	}
+}
+
+func (ch *EmailChannel) Deliver(conversation *models.Conversation, message *models.Message) error {
+	return enqueueReply(ch.jobClient, "send_email_reply", message.ID)
+}
+
+func (ch *EmailChannel) RegisterRoutes(router fiber.Router) {}
+
+// applyEmailConfigInput copies the mail server settings to the configuration
+func applyEmailConfigInput(config *models.InboxEmail, input *EmailConfigInput) {
+	config.ImapServer = input.ImapServer
+	config.ImapPort = input.ImapPort
+	config.SmtpServer = input.SmtpServer
+	config.SmtpPort = input.SmtpPort
+	config.Username = input.Username
+
+	// The password is never sent to the client, so keep it unless a new one is given
+	if input.Password != "" {
+		config.Password = input.Password
+	}
+}
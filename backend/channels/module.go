@@ -0,0 +1,31 @@
+package channels
+
+import (
+	"log"
+
+	"go.uber.org/dig"
+)
+
+// RegisterChannels registers the built-in channels and the channel registry in the DI container
+func RegisterChannels(container *dig.Container) {
+	channels := []struct {
+		name        string
+		constructor interface{}
+	}{
+		{"web chat", NewWebChatChannel},
+		{"email", NewEmailChannel},
+		{"sms", NewSMSChannel},
+		{"whatsapp", NewWhatsAppChannel},
+		{"api", NewAPIChannel},
+	}
+
+	for _, channel := range channels {
+		if err := container.Provide(channel.constructor, dig.Group(ChannelGroup)); err != nil {
+			log.Fatalf("Failed to provide %s channel: %v", channel.name, err)
+		}
+	}
+
+	if err := container.Provide(NewRegistry); err != nil {
+		log.Fatalf("Failed to provide channel registry: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package channels
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/types"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/dig"
+)
+
+// ChannelGroup is the dig value group channels are provided in
+const ChannelGroup = "channels"
+
+// RegistryParams contains the channels provided to the container
+type RegistryParams struct {
+	dig.In
+	Channels []interfaces.Channel `group:"channels"`
+}
+
+// Registry holds the channels implementing each inbox type
+type Registry struct {
+	channels map[models.InboxType]interfaces.Channel
+	ordered  []interfaces.Channel
+}
+
+// NewRegistry creates the channel registry
+func NewRegistry(params RegistryParams) interfaces.ChannelRegistry {
+	registry := &Registry{
+		channels: make(map[models.InboxType]interfaces.Channel, len(params.Channels)),
+		ordered:  make([]interfaces.Channel, 0, len(params.Channels)),
+	}
+
+	for _, channel := range params.Channels {
+		registry.channels[channel.Type()] = channel
+		registry.ordered = append(registry.ordered, channel)
+	}
+
+	sort.Slice(registry.ordered, func(i, j int) bool {
+		return registry.ordered[i].Type() < registry.ordered[j].Type()
+	})
+
+	return registry
+}
+
+func (r *Registry) Get(inboxType models.InboxType) (interfaces.Channel, bool) {
+	channel, ok := r.channels[inboxType]
+	return channel, ok
+}
+
+func (r *Registry) Channels() []interfaces.Channel {
+	return r.ordered
+}
+
+func (r *Registry) RegisterRoutes(router fiber.Router) {
+	for _, channel := range r.ordered {
+		channel.RegisterRoutes(router)
+	}
+}
+
+func (r *Registry) LoadConfig(inbox *models.Inbox) error {
+	channel, ok := r.Get(inbox.Type)
+	if !ok {
+		return nil
+	}
+
+	return channel.LoadConfig(inbox)
+}
+
+func (r *Registry) InboxPayload(inbox *models.Inbox, admin bool) types.InboxPayload {
+	payload := inbox.ToResponse()
+
+	channel, ok := r.Get(inbox.Type)
+	if !ok {
+		return payload
+	}
+
+	channel.ApplyToPayload(inbox, &payload)
+
+	if secrets, ok := channel.(interfaces.ChannelSecrets); ok && admin {
+		secrets.ApplySecretsToPayload(inbox, &payload)
+	}
+
+	return payload
+}
+
+func (r *Registry) PublicInboxPayload(inbox *models.Inbox) types.InboxPayload {
+	payload := inbox.ToPublicPayload()
+
+	if channel, ok := r.Get(inbox.Type); ok {
+		channel.ApplyToPublicPayload(inbox, &payload)
+	}
+
+	return payload
+}
@@ -0,0 +1,185 @@
+package channels
+
+import (
+	"errors"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/sms"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SMSConfigInput struct {
+	Provider    string `json:"provider" validate:"omitempty,oneof=twilio"`
+	AccountSID  string `json:"account_sid" validate:"required,max=255"`
+	AuthToken   string `json:"auth_token" validate:"omitempty,max=255"`
+	PhoneNumber string `json:"phone_number" validate:"required,e164"`
+	APIBaseURL  string `json:"api_base_url" validate:"omitempty,url"`
+}
+
+// SMSChannel implements inboxes receiving text messages through a Twilio-compatible provider
+type SMSChannel struct {
+	inboxRepo      repositories.InboxRepository
+	commandFactory interfaces.CommandFactory
+	jobClient      interfaces.JobClient
+	logger         interfaces.Logger
+	langContext    interfaces.LanguageContext
+}
+
+func NewSMSChannel(inboxRepo repositories.InboxRepository, commandFactory interfaces.CommandFactory, jobClient interfaces.JobClient, logger interfaces.Logger, langContext interfaces.LanguageContext) interfaces.Channel {
+	return &SMSChannel{
+		inboxRepo:      inboxRepo,
+		commandFactory: commandFactory,
+		jobClient:      jobClient,
+		logger:         logger.Named("sms_channel"),
+		langContext:    langContext,
+	}
+}
+
+func (ch *SMSChannel) Type() models.InboxType {
+	return models.InboxTypeSMS
+}
+
+func (ch *SMSChannel) LoadConfig(inbox *models.Inbox) error {
+	return loadConfig(ch.inboxRepo, inbox, &models.InboxSMS{})
+}
+
+func (ch *SMSChannel) ApplyToPayload(inbox *models.Inbox, payload *types.InboxPayload) {
+	config, ok := inbox.Config().(*models.InboxSMS)
+	if !ok {
+		return
+	}
+
+	payload.Config = types.InboxTypeConfig{
+		"sms_provider":     config.Provider,
+		"sms_account_sid":  config.AccountSID,
+		"sms_phone_number": config.PhoneNumber,
+		"sms_api_base_url": config.APIBaseURL,
+		"sms_webhook_url":  config.WebhookURL(),
+	}
+}
+
+func (ch *SMSChannel) ApplyToPublicPayload(inbox *models.Inbox, payload *types.InboxPayload) {}
+
+func (ch *SMSChannel) CreateConfig(c *fiber.Ctx) (models.InboxConfig, error) {
+	var input struct {
+		SMS *SMSConfigInput `json:"sms" validate:"required"`
+	}
+	if err := parseConfigInput(c, &input); err != nil {
+		return nil, err
+	}
+
+	if input.SMS.AuthToken == "" {
+		return nil, &interfaces.ChannelConfigError{Key: "sms_config_required"}
+	}
+
+	config := &models.InboxSMS{
+		Provider: sms.ProviderTwilio,
+	}
+	applySMSConfigInput(config, input.SMS)
+
+	return config, nil
+}
+
+func (ch *SMSChannel) UpdateConfig(c *fiber.Ctx, inbox *models.Inbox) (models.InboxConfig, error) {
+	var input struct {
+		SMS *SMSConfigInput `json:"sms" validate:"omitempty"`
+	}
+	if err := parseConfigInput(c, &input); err != nil {
+		return nil, err
+	}
+
+	if input.SMS == nil {
+		return nil, nil
+	}
+
+	config, ok := inbox.Config().(*models.InboxSMS)
+	if !ok {
+		config = &models.InboxSMS{
+			InboxID:  inbox.ID,
+			Provider: sms.ProviderTwilio,
+		}
+	}
+
+	applySMSConfigInput(config, input.SMS)
+
+	return config, nil
+}
+
+func (ch *SMSChannel) SampleConfig(inbox *models.Inbox) models.InboxConfig {
+	return &models.InboxSMS{
+		Provider:    sms.ProviderTwilio,
+		AccountSID:  "AC" + inbox.ID[:8],
+		AuthToken:   "sample_auth_token",
+		PhoneNumber: "+15005550006",
+	}
+}
+
+func (ch *SMSChannel) Deliver(conversation *models.Conversation, message *models.Message) error {
+	return enqueueReply(ch.jobClient, "send_sms_reply", message.ID)
+}
+
+func (ch *SMSChannel) RegisterRoutes(router fiber.Router) {
+	router.Post("/public/sms/:id/webhook", ch.HandleWebhook)
+}
+
+// HandleWebhook ingests a text message posted by the inbox's SMS provider
+func (ch *SMSChannel) HandleWebhook(c *fiber.Ctx) error {
+	inbox, err := ch.inboxRepo.GetInboxByID(c.Params("id"))
+	if err != nil || inbox.Type != models.InboxTypeSMS || !inbox.Enabled {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, ch.langContext.T(c, "inbox_not_found"), errors.New("sms inbox not found"))
+	}
+
+	if err := ch.LoadConfig(inbox); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, ch.langContext.T(c, "failed_to_handle_webhook"), err.Error())
+	}
+
+	config, _ := inbox.Config().(*models.InboxSMS)
+	provider, err := sms.NewProvider(config, ch.logger)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, ch.langContext.T(c, "failed_to_handle_webhook"), err.Error())
+	}
+
+	params := url.Values{}
+	c.Request().PostArgs().VisitAll(func(key, value []byte) {
+		params.Add(string(key), string(value))
+	})
+
+	if !provider.VerifySignature(utils.APIURL(c.OriginalURL()), params, c.Get(provider.SignatureHeader())) {
+		ch.logger.Warn("Rejected SMS webhook for inbox %s with an invalid signature", inbox.ID)
+		return utils.ErrorResponse(c, fiber.StatusForbidden, ch.langContext.T(c, "invalid_webhook_signature"), nil)
+	}
+
+	message, err := provider.ParseInbound(params)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, ch.langContext.T(c, "bad_request"), err.Error())
+	}
+
+	if _, err := ch.commandFactory.NewHandleInboundSMSCommand(inbox, message).Handle(); err != nil {
+		ch.logger.Error("Failed to handle inbound SMS %s for inbox %s: %v", message.MessageID, inbox.ID, err)
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, ch.langContext.T(c, "failed_to_handle_webhook"), err.Error())
+	}
+
+	// Acknowledge with an empty TwiML response so no automatic reply is sent
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+	return c.Status(fiber.StatusOK).SendString("<Response></Response>")
+}
+
+// applySMSConfigInput copies the provider settings to the configuration
+func applySMSConfigInput(config *models.InboxSMS, input *SMSConfigInput) {
+	if input.Provider != "" {
+		config.Provider = input.Provider
+	}
+	config.AccountSID = input.AccountSID
+	config.PhoneNumber = input.PhoneNumber
+	config.APIBaseURL = input.APIBaseURL
+
+	// The auth token is never sent to the client, so keep it unless a new one is given
+	if input.AuthToken != "" {
+		config.AuthToken = input.AuthToken
+	}
+}
@@ -0,0 +1,172 @@
+package channels
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const testSMSAuthToken = "test_auth_token"
+
+func TestSMSWebhook(t *testing.T) {
+	form := url.Values{
+		"MessageSid": {"SM123"},
+		"From":       {"+15551234567"},
+		"To":         {"+15005550006"},
+		"Body":       {"Hello"},
+	}
+	webhookURL := utils.APIURL("/public/sms/inbox-1/webhook")
+	signed := webhookURL + "BodyHello" + "From+15551234567" + "MessageSidSM123" + "To+15005550006"
+
+	tests := []struct {
+		name       string
+		inboxID    string
+		form       url.Values
+		signature  string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "signed by the provider",
+			inboxID:    "inbox-1",
+			form:       form,
+			signature:  signSMSWebhook(signed),
+			wantStatus: fiber.StatusOK,
+			wantBody:   "Hello",
+		},
+		{
+			name:       "tampered body",
+			inboxID:    "inbox-1",
+			form:       url.Values{"MessageSid": {"SM123"}, "From": {"+15551234567"}, "To": {"+15005550006"}, "Body": {"Goodbye"}},
+			signature:  signSMSWebhook(signed),
+			wantStatus: fiber.StatusForbidden,
+		},
+		{
+			name:       "unsigned",
+			inboxID:    "inbox-1",
+			form:       form,
+			wantStatus: fiber.StatusForbidden,
+		},
+		{
+			name:       "unknown inbox",
+			inboxID:    "inbox-2",
+			form:       form,
+			signature:  signSMSWebhook(signed),
+			wantStatus: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commandFactory := &smsCommandFactory{}
+			channel := NewSMSChannel(smsInboxRepository{}, commandFactory, nil, nopLogger{}, keyLanguageContext{})
+
+			app := fiber.New()
+			channel.RegisterRoutes(app)
+
+			req := httptest.NewRequest(http.MethodPost, "/public/sms/"+tt.inboxID+"/webhook", strings.NewReader(tt.form.Encode()))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+			if tt.signature != "" {
+				req.Header.Set("X-Twilio-Signature", tt.signature)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			if tt.wantBody == "" {
+				if commandFactory.message != nil {
+					t.Errorf("rejected webhook reached the inbound command with %+v", commandFactory.message)
+				}
+				return
+			}
+
+			if commandFactory.message == nil {
+				t.Fatal("webhook did not reach the inbound command")
+			}
+			if commandFactory.message.Body != tt.wantBody || commandFactory.message.From != "+15551234567" || commandFactory.message.MessageID != "SM123" {
+				t.Errorf("inbound message = %+v", commandFactory.message)
+			}
+		})
+	}
+}
+
+func signSMSWebhook(data string) string {
+	mac := hmac.New(sha1.New, []byte(testSMSAuthToken))
+	mac.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// smsInboxRepository knows a single enabled SMS inbox
+type smsInboxRepository struct {
+	repositories.InboxRepository
+}
+
+func (smsInboxRepository) GetInboxByID(id string) (*models.Inbox, error) {
+	if id != "inbox-1" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.Inbox{ID: id, Type: models.InboxTypeSMS, Enabled: true}, nil
+}
+
+func (smsInboxRepository) GetInboxConfig(inboxID string, config models.InboxConfig) error {
+	*config.(*models.InboxSMS) = models.InboxSMS{
+		InboxID:     inboxID,
+		AccountSID:  "AC123",
+		AuthToken:   testSMSAuthToken,
+		PhoneNumber: "+15005550006",
+	}
+	return nil
+}
+
+// smsCommandFactory records the message handed to the inbound command
+type smsCommandFactory struct {
+	interfaces.CommandFactory
+	message *types.InboundSMS
+}
+
+func (f *smsCommandFactory) NewHandleInboundSMSCommand(inbox *models.Inbox, message *types.InboundSMS) interfaces.Command {
+	f.message = message
+	return nopCommand{}
+}
+
+type nopCommand struct{}
+
+func (nopCommand) Handle() (interface{}, error) { return nil, nil }
+
+// keyLanguageContext returns translation keys untranslated
+type keyLanguageContext struct{}
+
+func (keyLanguageContext) GetLanguage(c *fiber.Ctx) string { return "en" }
+func (keyLanguageContext) T(c *fiber.Ctx, key string, args ...interface{}) string {
+	return key
+}
+
+// nopLogger discards every log entry
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{})                  {}
+func (nopLogger) Info(msg string, args ...interface{})                   {}
+func (nopLogger) Warn(msg string, args ...interface{})                   {}
+func (nopLogger) Error(msg string, args ...interface{})                  {}
+func (nopLogger) Fatal(msg string, args ...interface{})                  {}
+func (l nopLogger) With(fields map[string]interface{}) interfaces.Logger { return l }
+func (l nopLogger) Named(name string) interfaces.Logger                  { return l }
@@ -0,0 +1,182 @@
+package channels
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebChatCreateInput struct {
+	WelcomeMessage string `json:"welcome_message" validate:"required,min=3,max=255"`
+}
+
+type WebChatUpdateInput struct {
+	WelcomeMessage      string                        `json:"welcome_message" validate:"required,min=3,max=255"`
+	WorkingHours        map[string]types.WorkingHours `json:"working_hours" validate:"omitempty,working_hours"`
+	OutsideHoursMessage string                        `json:"outside_hours_message" validate:"omitempty"`
+	WidgetCustomization types.WidgetCustomization     `json:"widget_customization" validate:"required"`
+	PreChatForm         *types.PreChatForm            `json:"pre_chat_form" validate:"omitempty"`
+}
+
+// WebChatChannel implements the chat widget embedded on the company's website.
+// Messages reach the widget over its WebSocket connection, so nothing is delivered here.
+type WebChatChannel struct {
+	inboxRepo   repositories.InboxRepository
+	langContext interfaces.LanguageContext
+}
+
+func NewWebChatChannel(inboxRepo repositories.InboxRepository, langContext interfaces.LanguageContext) interfaces.Channel {
+	return &WebChatChannel{
+		inboxRepo:   inboxRepo,
+		langContext: langContext,
+	}
+}
+
+func (ch *WebChatChannel) Type() models.InboxType {
+	return models.InboxTypeWebChat
+}
+
+func (ch *WebChatChannel) LoadConfig(inbox *models.Inbox) error {
+	return loadConfig(ch.inboxRepo, inbox, &models.InboxWebChat{})
+}
+
+func (ch *WebChatChannel) ApplyToPayload(inbox *models.Inbox, payload *types.InboxPayload) {
+	config, ok := inbox.Config().(*models.InboxWebChat)
+	if !ok {
+		return
+	}
+
+	workingHours := config.WorkingHours
+	if workingHours == nil {
+		workingHours = models.DefaultWorkingHours()
+	}
+
+	payload.Config = webChatPayloadConfig(config, workingHours)
+}
+
+func (ch *WebChatChannel) ApplyToPublicPayload(inbox *models.Inbox, payload *types.InboxPayload) {
+	config, ok := inbox.Config().(*models.InboxWebChat)
+	if !ok {
+		return
+	}
+
+	payload.Config = webChatPayloadConfig(config, config.WorkingHours)
+}
+
+func (ch *WebChatChannel) CreateConfig(c *fiber.Ctx) (models.InboxConfig, error) {
+	var input WebChatCreateInput
+	if err := parseConfigInput(c, &input); err != nil {
+		return nil, err
+	}
+
+	return newWebChatConfig(
+		input.WelcomeMessage,
+		ch.langContext.T(c, "pre_form_title"),
+		ch.langContext.T(c, "pre_form_description"),
+		ch.langContext.T(c, "pre_form_name_label"),
+		ch.langContext.T(c, "pre_form_name_placeholder"),
+	), nil
+}
+
+func (ch *WebChatChannel) UpdateConfig(c *fiber.Ctx, inbox *models.Inbox) (models.InboxConfig, error) {
+	var input WebChatUpdateInput
+	if err := parseConfigInput(c, &input); err != nil {
+		return nil, err
+	}
+
+	config, ok := inbox.Config().(*models.InboxWebChat)
+	if !ok {
+		// Create WebChat config if it doesn't exist
+		config = &models.InboxWebChat{
+			InboxID: inbox.ID,
+		}
+	}
+
+	config.WelcomeMessage = input.WelcomeMessage
+	config.WorkingHours = input.WorkingHours
+	config.OutsideHoursMessage = input.OutsideHoursMessage
+	config.WidgetCustomization = input.WidgetCustomization
+
+	// Update PreChatForm if provided
+	if input.PreChatForm != nil {
+		config.PreChatForm = *input.PreChatForm
+	}
+
+	return config, nil
+}
+
+func (ch *WebChatChannel) SampleConfig(inbox *models.Inbox) models.InboxConfig {
+	return newWebChatConfig(
+		"Hello! How can we help you today?",
+		"Contact Information",
+		"Please provide your contact information before starting the chat.",
+		"Full Name",
+		"Enter your full name",
+	)
+}
+
+func (ch *WebChatChannel) Deliver(conversation *models.Conversation, message *models.Message) error {
+	return nil
+}
+
+func (ch *WebChatChannel) RegisterRoutes(router fiber.Router) {}
+
+// newWebChatConfig creates the configuration of a new web chat inbox with the default
+// working hours and widget, and a disabled pre-chat form asking for the contact's name
+func newWebChatConfig(welcomeMessage, formTitle, formDescription, nameLabel, namePlaceholder string) *models.InboxWebChat {
+	return &models.InboxWebChat{
+		WelcomeMessage: welcomeMessage,
+		WorkingHours:   models.DefaultWorkingHours(),
+		WidgetCustomization: types.WidgetCustomization{
+			PrimaryColor: "#0A2540",
+			Position:     "bottom-right",
+		},
+		PreChatForm: types.PreChatForm{
+			Enabled:     false,
+			Title:       formTitle,
+			Description: formDescription,
+			Fields: []types.PreChatFormField{
+				{
+					ID:           "field-" + utils.GenerateRandomID(),
+					Type:         "text",
+					Label:        nameLabel,
+					Placeholder:  namePlaceholder,
+					Required:     true,
+					ContactField: "name",
+				},
+			},
+		},
+	}
+}
+
+// webChatPayloadConfig returns the fields the chat widget is rendered from, with the
+// working hours and whether any day has them enabled
+func webChatPayloadConfig(config *models.InboxWebChat, workingHours types.WorkingHoursMap) types.InboxTypeConfig {
+	payloadConfig := types.InboxTypeConfig{
+		"welcome_message":       config.WelcomeMessage,
+		"outside_hours_message": config.OutsideHoursMessage,
+		"widget_customization":  config.WidgetCustomization,
+		"pre_chat_form":         config.PreChatForm,
+	}
+
+	if workingHours == nil {
+		return payloadConfig
+	}
+
+	workingHoursEnabled := false
+	for _, wh := range workingHours {
+		if wh.Enabled {
+			workingHoursEnabled = true
+			break
+		}
+	}
+
+	payloadConfig["working_hours"] = workingHours
+	payloadConfig["working_hours_enabled"] = workingHoursEnabled
+
+	return payloadConfig
+}
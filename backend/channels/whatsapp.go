@@ -0,0 +1,213 @@
+package channels
+
+import (
+	"crypto/subtle"
+	"errors"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+	"live-chat-server/whatsapp"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WhatsAppConfigInput struct {
+	PhoneNumberID     string `json:"phone_number_id" validate:"required,numeric"`
+	BusinessAccountID string `json:"business_account_id" validate:"omitempty,numeric"`
+	AccessToken       string `json:"access_token" validate:"omitempty"`
+	AppSecret         string `json:"app_secret" validate:"omitempty"`
+	VerifyToken       string `json:"verify_token" validate:"omitempty,min=8,max=255"`
+	APIBaseURL        string `json:"api_base_url" validate:"omitempty,url"`
+}
+
+// WhatsAppChannel implements inboxes connected to a WhatsApp Business number through the Cloud API
+type WhatsAppChannel struct {
+	inboxRepo      repositories.InboxRepository
+	commandFactory interfaces.CommandFactory
+	jobClient      interfaces.JobClient
+	logger         interfaces.Logger
+	langContext    interfaces.LanguageContext
+}
+
+func NewWhatsAppChannel(inboxRepo repositories.InboxRepository, commandFactory interfaces.CommandFactory, jobClient interfaces.JobClient, logger interfaces.Logger, langContext interfaces.LanguageContext) interfaces.Channel {
+	return &WhatsAppChannel{
+		inboxRepo:      inboxRepo,
+		commandFactory: commandFactory,
+		jobClient:      jobClient,
+		logger:         logger.Named("whatsapp_channel"),
+		langContext:    langContext,
+	}
+}
+
+func (ch *WhatsAppChannel) Type() models.InboxType {
+	return models.InboxTypeWhatsApp
+}
+
+func (ch *WhatsAppChannel) LoadConfig(inbox *models.Inbox) error {
+	return loadConfig(ch.inboxRepo, inbox, &models.InboxWhatsApp{})
+}
+
+func (ch *WhatsAppChannel) ApplyToPayload(inbox *models.Inbox, payload *types.InboxPayload) {
+	config, ok := inbox.Config().(*models.InboxWhatsApp)
+	if !ok {
+		return
+	}
+
+	payload.Config = types.InboxTypeConfig{
+		"whatsapp_phone_number_id":     config.PhoneNumberID,
+		"whatsapp_business_account_id": config.BusinessAccountID,
+		"whatsapp_api_base_url":        config.APIBaseURL,
+		"whatsapp_webhook_url":         config.WebhookURL(),
+	}
+}
+
+func (ch *WhatsAppChannel) ApplyToPublicPayload(inbox *models.Inbox, payload *types.InboxPayload) {}
+
+func (ch *WhatsAppChannel) CreateConfig(c *fiber.Ctx) (models.InboxConfig, error) {
+	var input struct {
+		WhatsApp *WhatsAppConfigInput `json:"whatsapp" validate:"required"`
+	}
+	if err := parseConfigInput(c, &input); err != nil {
+		return nil, err
+	}
+
+	if input.WhatsApp.AccessToken == "" || input.WhatsApp.AppSecret == "" || input.WhatsApp.VerifyToken == "" {
+		return nil, &interfaces.ChannelConfigError{Key: "whatsapp_config_required"}
+	}
+
+	config := &models.InboxWhatsApp{}
+	applyWhatsAppConfigInput(config, input.WhatsApp)
+
+	return config, nil
+}
+
+func (ch *WhatsAppChannel) UpdateConfig(c *fiber.Ctx, inbox *models.Inbox) (models.InboxConfig, error) {
+	var input struct {
+		WhatsApp *WhatsAppConfigInput `json:"whatsapp" validate:"omitempty"`
+	}
+	if err := parseConfigInput(c, &input); err != nil {
+		return nil, err
+	}
+
+	if input.WhatsApp == nil {
+		return nil, nil
+	}
+
+	config, ok := inbox.Config().(*models.InboxWhatsApp)
+	if !ok {
+		config = &models.InboxWhatsApp{
+			InboxID: inbox.ID,
+		}
+	}
+
+	applyWhatsAppConfigInput(config, input.WhatsApp)
+
+	return config, nil
+}
+
+func (ch *WhatsAppChannel) SampleConfig(inbox *models.Inbox) models.InboxConfig {
+	return &models.InboxWhatsApp{
+		PhoneNumberID:     "100000000000000",
+		BusinessAccountID: "200000000000000",
+		AccessToken:       "sample_access_token",
+		AppSecret:         "sample_app_secret",
+		VerifyToken:       "verify-" + inbox.ID[:8],
+	}
+}
+
+func (ch *WhatsAppChannel) Deliver(conversation *models.Conversation, message *models.Message) error {
+	return enqueueReply(ch.jobClient, "send_whatsapp_reply", message.ID)
+}
+
+func (ch *WhatsAppChannel) RegisterRoutes(router fiber.Router) {
+	router.Get("/public/whatsapp/:id/webhook", ch.HandleVerify)
+	router.Post("/public/whatsapp/:id/webhook", ch.HandleWebhook)
+}
+
+// getInbox returns the enabled WhatsApp inbox the webhook was called for, with its configuration
+func (ch *WhatsAppChannel) getInbox(c *fiber.Ctx) (*models.Inbox, *models.InboxWhatsApp, error) {
+	inbox, err := ch.inboxRepo.GetInboxByID(c.Params("id"))
+	if err != nil || inbox.Type != models.InboxTypeWhatsApp || !inbox.Enabled {
+		return nil, nil, errors.New("whatsapp inbox not found")
+	}
+
+	if err := ch.LoadConfig(inbox); err != nil {
+		return nil, nil, err
+	}
+
+	config, ok := inbox.Config().(*models.InboxWhatsApp)
+	if !ok {
+		return nil, nil, errors.New("whatsapp inbox not found")
+	}
+
+	return inbox, config, nil
+}
+
+// HandleVerify answers the verification request sent when the webhook is subscribed
+func (ch *WhatsAppChannel) HandleVerify(c *fiber.Ctx) error {
+	_, config, err := ch.getInbox(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, ch.langContext.T(c, "inbox_not_found"), err)
+	}
+
+	verifyToken := c.Query("hub.verify_token")
+	if c.Query("hub.mode") != "subscribe" || config.VerifyToken == "" ||
+		subtle.ConstantTimeCompare([]byte(verifyToken), []byte(config.VerifyToken)) != 1 {
+		return utils.ErrorResponse(c, fiber.StatusForbidden, ch.langContext.T(c, "invalid_verify_token"), nil)
+	}
+
+	return c.Status(fiber.StatusOK).SendString(c.Query("hub.challenge"))
+}
+
+// HandleWebhook ingests the messages of a webhook notification
+func (ch *WhatsAppChannel) HandleWebhook(c *fiber.Ctx) error {
+	inbox, config, err := ch.getInbox(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, ch.langContext.T(c, "inbox_not_found"), err)
+	}
+
+	client, err := whatsapp.NewClient(config, ch.logger)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, ch.langContext.T(c, "failed_to_handle_webhook"), err.Error())
+	}
+
+	body := c.Body()
+	if !client.VerifySignature(body, c.Get("X-Hub-Signature-256")) {
+		ch.logger.Warn("Rejected WhatsApp webhook for inbox %s with an invalid signature", inbox.ID)
+		return utils.ErrorResponse(c, fiber.StatusForbidden, ch.langContext.T(c, "invalid_webhook_signature"), nil)
+	}
+
+	messages, err := whatsapp.ParseWebhook(body, config.PhoneNumberID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, ch.langContext.T(c, "bad_request"), err.Error())
+	}
+
+	for i := range messages {
+		if _, err := ch.commandFactory.NewHandleInboundWhatsAppCommand(inbox, &messages[i]).Handle(); err != nil {
+			ch.logger.Error("Failed to handle inbound WhatsApp message %s for inbox %s: %v", messages[i].ID, inbox.ID, err)
+			return utils.ErrorResponse(c, fiber.StatusInternalServerError, ch.langContext.T(c, "failed_to_handle_webhook"), err.Error())
+		}
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// applyWhatsAppConfigInput copies the Cloud API settings to the configuration
+func applyWhatsAppConfigInput(config *models.InboxWhatsApp, input *WhatsAppConfigInput) {
+	config.PhoneNumberID = input.PhoneNumberID
+	config.BusinessAccountID = input.BusinessAccountID
+	config.APIBaseURL = input.APIBaseURL
+
+	// Secrets are never sent to the client, so keep them unless new ones are given
+	if input.AccessToken != "" {
+		config.AccessToken = input.AccessToken
+	}
+	if input.AppSecret != "" {
+		config.AppSecret = input.AppSecret
+	}
+	if input.VerifyToken != "" {
+		config.VerifyToken = input.VerifyToken
+	}
+}
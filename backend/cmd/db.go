@@ -87,9 +87,10 @@ func showDatabaseInfo() {
 
 	// Check tables
 	tables := []string{
-		"companies", "users", "inboxes", "inbox_emails", "inbox_web_chats",
+		"companies", "users", "inboxes", "inbox_emails", "inbox_sms", "inbox_whatsapp", "inbox_api", "inbox_web_chats",
 		"contacts", "notification_settings", "conversations", "messages",
-		"contact_notes", "company_invites", "canned_responses", "user_notifications",
+		"contact_notes", "company_invites", "canned_responses", "labels", "conversation_labels", "user_notifications",
+		"sla_policies", "contact_merges", "message_edits", "conversation_reads", "saved_views", "conversation_participants",
 	}
 
 	fmt.Println("📋 Tables Status:")
@@ -74,16 +74,26 @@ func runMigrations() {
 	err := models.DB.AutoMigrate(
 		&models.Company{},
 		&models.User{},
+		&models.SLAPolicy{},
 		&models.Inbox{},
 		&models.InboxEmail{},
+		&models.InboxSMS{},
+		&models.InboxWhatsApp{},
+		&models.InboxAPI{},
 		&models.InboxWebChat{},
 		&models.Contact{},
 		&models.NotificationSettings{},
 		&models.Conversation{},
 		&models.Message{},
+		&models.MessageEdit{},
+		&models.ConversationRead{},
+		&models.ConversationParticipant{},
 		&models.ContactNote{},
+		&models.ContactMerge{},
 		&models.CompanyInvite{},
 		&models.CannedResponse{},
+		&models.Label{},
+		&models.SavedView{},
 		&models.UserNotification{},
 	)
 
@@ -102,9 +112,10 @@ func showMigrationStatus() {
 
 	// Check if tables exist
 	tables := []string{
-		"companies", "users", "inboxes", "inbox_emails", "inbox_web_chats",
+		"companies", "users", "inboxes", "inbox_emails", "inbox_sms", "inbox_whatsapp", "inbox_api", "inbox_web_chats",
 		"contacts", "notification_settings", "conversations", "messages",
-		"contact_notes", "company_invites", "canned_responses", "user_notifications",
+		"contact_notes", "company_invites", "canned_responses", "labels", "conversation_labels", "user_notifications",
+		"sla_policies", "contact_merges", "message_edits", "conversation_reads", "saved_views", "conversation_participants",
 	}
 
 	fmt.Println("\n📋 Migration Status:")
@@ -138,9 +149,9 @@ func resetMigrations(cmd *cobra.Command) {
 
 	// Drop all tables
 	tables := []string{
-		"user_notifications", "canned_responses", "company_invites", "contact_notes",
-		"messages", "conversations", "notification_settings", "contacts",
-		"inbox_web_chats", "inbox_emails", "inboxes", "users", "companies",
+		"saved_views", "user_notifications", "canned_responses", "conversation_labels", "labels", "company_invites", "contact_merges", "contact_notes",
+		"conversation_participants", "conversation_reads", "message_edits", "messages", "conversations", "notification_settings", "contacts",
+		"inbox_web_chats", "inbox_emails", "inbox_sms", "inbox_whatsapp", "inbox_api", "inboxes", "sla_policies", "users", "companies",
 	}
 
 	for _, table := range tables {
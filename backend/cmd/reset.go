@@ -74,9 +74,9 @@ func resetDatabase(cmd *cobra.Command) {
 
 	// Drop all tables in reverse dependency order
 	tables := []string{
-		"user_notifications", "canned_responses", "company_invites", "contact_notes",
-		"messages", "conversations", "notification_settings", "contacts",
-		"inbox_web_chats", "inbox_emails", "inboxes", "inbox_users", "users", "companies",
+		"saved_views", "user_notifications", "canned_responses", "conversation_labels", "labels", "company_invites", "contact_merges", "contact_notes",
+		"conversation_participants", "conversation_reads", "message_edits", "messages", "conversations", "notification_settings", "contacts",
+		"inbox_web_chats", "inbox_emails", "inbox_sms", "inbox_whatsapp", "inbox_api", "inboxes", "inbox_users", "sla_policies", "users", "companies",
 	}
 
 	fmt.Println("Dropping tables...")
@@ -159,14 +159,25 @@ func clearSeedDataSilent() {
 	models.ConnectDatabase(config.App.DatabaseDSN)
 
 	// Clear in reverse dependency order (children first, then parents)
+	models.DB.Exec("DELETE FROM saved_views")               // Delete saved_views before inboxes and users
+	models.DB.Exec("DELETE FROM conversation_participants") // Delete conversation_participants before conversations
+	models.DB.Exec("DELETE FROM conversation_reads")        // Delete conversation_reads before conversations
+	models.DB.Exec("DELETE FROM message_edits")             // Delete message_edits before messages
 	models.DB.Exec("DELETE FROM messages")
+	models.DB.Exec("DELETE FROM conversation_labels") // Delete conversation_labels before conversations and labels
 	models.DB.Exec("DELETE FROM conversations")
-	models.DB.Exec("DELETE FROM contact_notes") // Delete contact_notes before contacts
+	models.DB.Exec("DELETE FROM labels")
+	models.DB.Exec("DELETE FROM contact_merges") // Delete contact_merges before contacts
+	models.DB.Exec("DELETE FROM contact_notes")  // Delete contact_notes before contacts
 	models.DB.Exec("DELETE FROM contacts")
 	models.DB.Exec("DELETE FROM inbox_web_chats")
 	models.DB.Exec("DELETE FROM inbox_emails")
+	models.DB.Exec("DELETE FROM inbox_sms")
+	models.DB.Exec("DELETE FROM inbox_whatsapp")
+	models.DB.Exec("DELETE FROM inbox_api")
 	models.DB.Exec("DELETE FROM inbox_users")
 	models.DB.Exec("DELETE FROM inboxes")
+	models.DB.Exec("DELETE FROM sla_policies")          // Delete sla_policies after the inboxes using them
 	models.DB.Exec("DELETE FROM notification_settings") // Delete notification_settings before users
 	models.DB.Exec("DELETE FROM user_notifications")    // Delete user_notifications before users
 	models.DB.Exec("DELETE FROM canned_responses")      // Delete canned_responses before users
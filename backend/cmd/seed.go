@@ -3,9 +3,9 @@ package cmd
 import (
 	"fmt"
 	"live-chat-server/config"
+	"live-chat-server/container"
+	"live-chat-server/interfaces"
 	"live-chat-server/models"
-	"live-chat-server/types"
-	"live-chat-server/utils"
 	"math/rand"
 	"strings"
 	"time"
@@ -281,13 +281,13 @@ func createSampleInboxes(count int) {
 
 	inboxTypes := []struct {
 		Name string
-		Type string
+		Type models.InboxType
 	}{
-		{fmt.Sprintf("%s Support", gofakeit.JobTitle()), "email"},
-		{fmt.Sprintf("%s Inquiries", gofakeit.JobDescriptor()), "email"},
-		{fmt.Sprintf("%s Department", gofakeit.Noun()), "email"},
-		{"Live Chat", "webchat"},
-		{fmt.Sprintf("%s Team", gofakeit.Adjective()), "email"},
+		{fmt.Sprintf("%s Support", gofakeit.JobTitle()), models.InboxTypeEmail},
+		{fmt.Sprintf("%s Inquiries", gofakeit.JobDescriptor()), models.InboxTypeEmail},
+		{fmt.Sprintf("%s Department", gofakeit.Noun()), models.InboxTypeEmail},
+		{"Live Chat", models.InboxTypeWebChat},
+		{fmt.Sprintf("%s Team", gofakeit.Adjective()), models.InboxTypeEmail},
 	}
 
 	for i := 0; i < count; i++ {
@@ -305,7 +305,7 @@ func createSampleInboxes(count int) {
 			Name:        inboxName,
 			Description: gofakeit.Sentence(rand.Intn(8) + 5), // 5-12 words
 			CompanyID:   companyID,
-			Type:        models.InboxType(template.Type),
+			Type:        template.Type,
 			Enabled:     gofakeit.Bool(),
 			CreatedAt:   time.Now().AddDate(0, 0, -i),
 			UpdatedAt:   time.Now(),
@@ -316,69 +316,47 @@ func createSampleInboxes(count int) {
 		} else {
 			fmt.Printf("  ✓ Created inbox: %s (%s)\n", inbox.Name, inbox.Type)
 
-			// Create inbox-specific records based on type
-			if template.Type == "email" {
-				createInboxEmail(inbox.ID)
-			} else if template.Type == "webchat" {
-				createInboxWebChat(inbox.ID)
-			}
+			// Create the configuration of the inbox's channel
+			createInboxConfig(&inbox)
 		}
 	}
 }
 
-func createInboxEmail(inboxID string) {
-	inboxEmail := models.InboxEmail{
-		ID:         uuid.New().String(),
-		InboxID:    inboxID,
-		ImapServer: "imap.example.com",
-		ImapPort:   993,
-		Username:   fmt.Sprintf("inbox-%s@example.com", inboxID[:8]),
-		Password:   "encrypted_password",
-		SmtpServer: "smtp.example.com",
-		SmtpPort:   587,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-	}
-
-	models.DB.Create(&inboxEmail)
-}
-
-func createInboxWebChat(inboxID string) {
-	inboxWebChat := models.InboxWebChat{
-		ID:             uuid.New().String(),
-		InboxID:        inboxID,
-		WelcomeMessage: "Hello! How can we help you today?",
-		WorkingHours: types.WorkingHoursMap{
-			"monday":    types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-			"tuesday":   types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-			"wednesday": types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-			"thursday":  types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-			"friday":    types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-			"saturday":  types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: false},
-			"sunday":    types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: false},
-		},
-		WidgetCustomization: types.WidgetCustomization{
-			PrimaryColor: "#0A2540",
-			Position:     "bottom-right",
-		},
-		PreChatForm: types.PreChatForm{
-			Enabled:     false,
-			Title:       "Contact Information",
-			Description: "Please provide your contact information before starting the chat.",
-			Fields: []types.PreChatFormField{
-				{
-					ID:           "field-" + utils.GenerateRandomID(),
-					Type:         "text",
-					Label:        "Full Name",
-					Placeholder:  "Enter your full name",
-					Required:     true,
-					ContactField: "name",
-				},
-			},
-		},
+// seedChannelRegistry holds the channels the seeders create inbox configurations with
+var seedChannelRegistry interfaces.ChannelRegistry
+
+// seedChannels returns the registered channels, building them on first use
+func seedChannels() interfaces.ChannelRegistry {
+	if seedChannelRegistry == nil {
+		seedChannelRegistry = container.NewChannelRegistry(models.DB)
 	}
 
-	models.DB.Create(&inboxWebChat)
+	return seedChannelRegistry
+}
+
+// createInboxConfig creates the sample configuration of the inbox's channel, unless it already has one
+func createInboxConfig(inbox *models.Inbox) {
+	channel, ok := seedChannels().Get(inbox.Type)
+	if !ok {
+		fmt.Printf("Error creating config for inbox %s: no channel for type %s\n", inbox.Name, inbox.Type)
+		return
+	}
+
+	if err := channel.LoadConfig(inbox); err != nil {
+		fmt.Printf("Error loading config for inbox %s: %v\n", inbox.Name, err)
+		return
+	}
+
+	if inbox.Config() != nil {
+		return
+	}
+
+	config := channel.SampleConfig(inbox)
+	config.SetInboxID(inbox.ID)
+
+	if err := models.DB.Create(config).Error; err != nil {
+		fmt.Printf("Error creating config for inbox %s: %v\n", inbox.Name, err)
+	}
 }
 
 func createSampleContacts(count int) {
@@ -452,6 +430,13 @@ func createSampleConversations(count int) {
 		models.ConversationStatusResolved,
 	}
 
+	priorities := []models.ConversationPriority{
+		models.ConversationPriorityLow,
+		models.ConversationPriorityMedium,
+		models.ConversationPriorityHigh,
+		models.ConversationPriorityUrgent,
+	}
+
 	for i := 0; i < count; i++ {
 		if len(inboxes) == 0 || len(contacts) == 0 || len(companies) == 0 {
 			break
@@ -470,6 +455,7 @@ func createSampleConversations(count int) {
 			CompanyID:    companies[i%len(companies)].ID,
 			AssignedToID: assigneeID,
 			Status:       statuses[i%len(statuses)],
+			Priority:     priorities[i%len(priorities)],
 			CreatedAt:    time.Now().AddDate(0, 0, -i),
 			UpdatedAt:    time.Now(),
 		}
@@ -556,12 +542,23 @@ func clearSeedData(cmd *cobra.Command) {
 	initSeedDatabase()
 
 	// Clear in reverse dependency order
+	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.SavedView{})
+	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.ConversationParticipant{})
+	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.ConversationRead{})
+	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.MessageEdit{})
 	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Message{})
+	models.DB.Exec("DELETE FROM conversation_labels")
 	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Conversation{})
+	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Label{})
+	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.ContactMerge{})
 	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Contact{})
 	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.InboxWebChat{})
 	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.InboxEmail{})
+	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.InboxSMS{})
+	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.InboxWhatsApp{})
+	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.InboxAPI{})
 	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Inbox{})
+	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.SLAPolicy{})
 	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.User{})
 	models.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Company{})
 
@@ -701,15 +698,8 @@ func createCompanyInboxes(companyID string, count int) []models.Inbox {
 			fmt.Printf("  ✓ Created inbox: %s (%s)\n", inbox.Name, inbox.Type)
 			inboxes = append(inboxes, inbox)
 
-			createInboxWebChat(inbox.ID)
-
-			// Create inbox-specific records
-			// TODO; Finish implementing email chat
-			// if template.Type == models.InboxTypeEmail {
-			// 	createInboxEmail(inbox.ID)
-			// } else if template.Type == models.InboxTypeWebChat {
-			// 	createInboxWebChat(inbox.ID)
-			// }
+			// Create the configuration of the inbox's channel
+			createInboxConfig(&inbox)
 		}
 	}
 
@@ -759,6 +749,13 @@ func createCompanyConversations(inboxes []models.Inbox, contacts []models.Contac
 		models.ConversationStatusResolved,
 	}
 
+	priorities := []models.ConversationPriority{
+		models.ConversationPriorityLow,
+		models.ConversationPriorityMedium,
+		models.ConversationPriorityHigh,
+		models.ConversationPriorityUrgent,
+	}
+
 	for i := 0; i < count; i++ {
 		// Assign 80% of conversations to agents, 20% unassigned
 		var assigneeID *string
@@ -774,6 +771,7 @@ func createCompanyConversations(inboxes []models.Inbox, contacts []models.Contac
 			CompanyID:    inboxes[0].CompanyID, // All inboxes belong to same company
 			AssignedToID: assigneeID,
 			Status:       statuses[rand.Intn(len(statuses))],
+			Priority:     priorities[rand.Intn(len(priorities))],
 			CreatedAt:    time.Now().AddDate(0, 0, -rand.Intn(30)), // Random date within last 30 days
 			UpdatedAt:    time.Now(),
 		}
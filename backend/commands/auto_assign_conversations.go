@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"math/rand"
+)
+
+// autoAssignConversations hands the waiting conversations of the conversation's inbox to agents with
+// room for them, the most urgent first, so the conversation does not jump ahead of more urgent ones
+func autoAssignConversations(
+	conversation *models.Conversation,
+	maxAutoAssignments int,
+	inboxRepo repositories.InboxRepository,
+	conversationRepo repositories.ConversationRepository,
+	conversationHandler interfaces.ConversationHandler,
+	logger interfaces.Logger,
+) {
+	agents, err := inboxRepo.GetUsersForInbox(conversation.InboxID)
+	if err != nil {
+		logger.Error("Failed to get agents", "error", err)
+		return
+	}
+
+	availableAgents := []models.User{}
+	capacity := make(map[string]int)
+	for _, agent := range agents {
+		conversations, err := conversationRepo.GetActiveAssignedConversationsForUser(agent.ID)
+		if err != nil {
+			logger.Error("Failed to get conversations", "error", err)
+			continue
+		}
+
+		if len(conversations) >= maxAutoAssignments {
+			continue
+		}
+
+		availableAgents = append(availableAgents, agent)
+		capacity[agent.ID] = maxAutoAssignments - len(conversations)
+	}
+
+	if len(availableAgents) == 0 {
+		return
+	}
+
+	queue, err := conversationRepo.GetUnassignedPendingConversationsByInboxID(conversation.InboxID, "Contact", "Inbox")
+	if err != nil {
+		logger.Error("Failed to get pending conversations", "error", err)
+		return
+	}
+
+	for i := range queue {
+		if len(availableAgents) == 0 {
+			break
+		}
+
+		// Assign the given conversation in place so the caller sees the assignment
+		next := &queue[i]
+		if next.ID == conversation.ID {
+			next = conversation
+		}
+
+		index := rand.Intn(len(availableAgents))
+		agent := availableAgents[index]
+		if err := conversationHandler.AssignConversation(next, agent.ID, agent.GetFullName()); err != nil {
+			logger.Error("Failed to assign conversation", "error", err)
+			continue
+		}
+
+		capacity[agent.ID]--
+		if capacity[agent.ID] == 0 {
+			availableAgents = append(availableAgents[:index], availableAgents[index+1:]...)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/listeners"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"strings"
+	"time"
+)
+
+// EditMessageCommand changes the content of a message. Only the sender of a message may edit it,
+// within the message edit window of their company.
+type EditMessageCommand struct {
+	Message    *models.Message
+	Content    string
+	EditorType models.SenderType
+	EditorID   string
+
+	// DI dependencies
+	conversationRepo repositories.ConversationRepository
+	companyRepo      repositories.CompanyRepository
+	dispatcher       interfaces.Dispatcher
+	auditService     interfaces.AuditService
+	logger           interfaces.Logger
+}
+
+func (c *EditMessageCommand) Handle() (interface{}, error) {
+	message := c.Message
+
+	content := strings.TrimSpace(c.Content)
+	if content == "" {
+		return nil, models.ErrMessageContentRequired
+	}
+
+	if message.Type != models.MessageTypeText {
+		return nil, models.ErrMessageNotEditable
+	}
+
+	conversation, err := getConversationForMessageChange(c.conversationRepo, c.companyRepo, message, c.EditorType, c.EditorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.Content == content {
+		return message, nil
+	}
+
+	now := time.Now()
+	edit := &models.MessageEdit{
+		MessageID:       message.ID,
+		EditorType:      c.EditorType,
+		EditorID:        c.EditorID,
+		PreviousContent: message.Content,
+		Content:         content,
+	}
+
+	previousEditedAt := message.EditedAt
+	message.Content = content
+	message.EditedAt = &now
+
+	if err := c.conversationRepo.EditMessage(message, edit); err != nil {
+		message.Content = edit.PreviousContent
+		message.EditedAt = previousEditedAt
+		return nil, err
+	}
+
+	updateConversationLastMessage(c.conversationRepo, c.logger, conversation, message)
+
+	c.dispatcher.Dispatch(interfaces.EventTypeMessageUpdated, &listeners.MessageChangedPayload{
+		Message:      message,
+		Conversation: conversation,
+	})
+
+	auditMessageChange(c.auditService, c.logger, message, edit, models.AuditActionMessageEdit)
+
+	return message, nil
+}
+
+// DeleteMessageCommand turns a message into a tombstone. Only the sender of a message may delete it,
+// within the message edit window of their company.
+type DeleteMessageCommand struct {
+	Message    *models.Message
+	EditorType models.SenderType
+	EditorID   string
+
+	// DI dependencies
+	conversationRepo repositories.ConversationRepository
+	companyRepo      repositories.CompanyRepository
+	dispatcher       interfaces.Dispatcher
+	auditService     interfaces.AuditService
+	logger           interfaces.Logger
+}
+
+func (c *DeleteMessageCommand) Handle() (interface{}, error) {
+	message := c.Message
+
+	conversation, err := getConversationForMessageChange(c.conversationRepo, c.companyRepo, message, c.EditorType, c.EditorID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	edit := &models.MessageEdit{
+		MessageID:       message.ID,
+		EditorType:      c.EditorType,
+		EditorID:        c.EditorID,
+		PreviousContent: message.Content,
+	}
+
+	message.Content = ""
+	message.TombstonedAt = &now
+
+	if err := c.conversationRepo.EditMessage(message, edit); err != nil {
+		message.Content = edit.PreviousContent
+		message.TombstonedAt = nil
+		return nil, err
+	}
+
+	updateConversationLastMessage(c.conversationRepo, c.logger, conversation, message)
+
+	c.dispatcher.Dispatch(interfaces.EventTypeMessageDeleted, &listeners.MessageChangedPayload{
+		Message:      message,
+		Conversation: conversation,
+	})
+
+	auditMessageChange(c.auditService, c.logger, message, edit, models.AuditActionMessageDelete)
+
+	return message, nil
+}
+
+// getConversationForMessageChange loads the conversation of a message once the editor is allowed to change it
+func getConversationForMessageChange(conversationRepo repositories.ConversationRepository, companyRepo repositories.CompanyRepository, message *models.Message, editorType models.SenderType, editorID string) (*models.Conversation, error) {
+	conversation, err := conversationRepo.GetConversationByID(message.ConversationID, "Contact", "Inbox", "AssignedTo", "Labels")
+	if err != nil {
+		return nil, err
+	}
+
+	if conversation.IsClosed() {
+		return nil, models.ErrConversationClosed
+	}
+
+	company, err := companyRepo.GetCompanyByID(conversation.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := message.CanBeChangedBy(editorType, editorID, company.MessageEditWindow(), time.Now()); err != nil {
+		return nil, err
+	}
+
+	return conversation, nil
+}
+
+// updateConversationLastMessage keeps the conversation preview in line with its latest message
+func updateConversationLastMessage(conversationRepo repositories.ConversationRepository, logger interfaces.Logger, conversation *models.Conversation, message *models.Message) {
+	latest, err := conversationRepo.GetLatestMessage(conversation.ID, true)
+	if err != nil || latest.ID != message.ID {
+		return
+	}
+
+	conversation.LastMessage = message.Content
+	if err := conversationRepo.UpdateConversation(conversation); err != nil {
+		logger.Error("Failed to update last message of conversation %s: %v", conversation.ID, err)
+	}
+}
+
+// auditMessageChange logs an edit or deletion made by an agent or a contact
+func auditMessageChange(auditService interfaces.AuditService, logger interfaces.Logger, message *models.Message, edit *models.MessageEdit, action models.AuditAction) {
+	metadata := map[string]interface{}{
+		"conversation_id":  message.ConversationID,
+		"previous_content": edit.PreviousContent,
+		"content":          edit.Content,
+	}
+
+	var err error
+	if edit.EditorType == models.SenderTypeAgent {
+		err = auditService.LogMessageAction(edit.EditorID, message.ID, string(action), metadata)
+	} else {
+		metadata["message_id"] = message.ID
+		metadata["contact_id"] = edit.EditorID
+		err = auditService.LogSystemEvent(string(action), "message", "Message changed by the contact", metadata)
+	}
+
+	if err != nil {
+		logger.Error("Failed to audit %s of message %s: %v", action, message.ID, err)
+	}
+}
+
+func NewEditMessageCommand(
+	message *models.Message,
+	content string,
+	editorType models.SenderType,
+	editorID string,
+	conversationRepo repositories.ConversationRepository,
+	companyRepo repositories.CompanyRepository,
+	dispatcher interfaces.Dispatcher,
+	auditService interfaces.AuditService,
+	logger interfaces.Logger,
+) interfaces.Command {
+	return &EditMessageCommand{
+		Message:          message,
+		Content:          content,
+		EditorType:       editorType,
+		EditorID:         editorID,
+		conversationRepo: conversationRepo,
+		companyRepo:      companyRepo,
+		dispatcher:       dispatcher,
+		auditService:     auditService,
+		logger:           logger,
+	}
+}
+
+func NewDeleteMessageCommand(
+	message *models.Message,
+	editorType models.SenderType,
+	editorID string,
+	conversationRepo repositories.ConversationRepository,
+	companyRepo repositories.CompanyRepository,
+	dispatcher interfaces.Dispatcher,
+	auditService interfaces.AuditService,
+	logger interfaces.Logger,
+) interfaces.Command {
+	return &DeleteMessageCommand{
+		Message:          message,
+		EditorType:       editorType,
+		EditorID:         editorID,
+		conversationRepo: conversationRepo,
+		companyRepo:      companyRepo,
+		dispatcher:       dispatcher,
+		auditService:     auditService,
+		logger:           logger,
+	}
+}
@@ -0,0 +1,324 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"live-chat-server/email"
+	"live-chat-server/interfaces"
+	"live-chat-server/listeners"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// HandleInboundEmailCommand represents the command to ingest an email received by an email inbox
+type HandleInboundEmailCommand struct {
+	Inbox *models.Inbox
+	Email *types.InboundEmail
+
+	// DI dependencies
+	conversationRepo repositories.ConversationRepository
+	contactRepo      repositories.ContactRepository
+	uploadService    interfaces.UploadService
+	commandFactory   interfaces.CommandFactory
+	dispatcher       interfaces.Dispatcher
+	logger           interfaces.Logger
+}
+
+// Handle implements the Command interface
+func (c *HandleInboundEmailCommand) Handle() (interface{}, error) {
+	if c.Email.FromEmail == "" {
+		return nil, fmt.Errorf("email has no sender address")
+	}
+
+	// Ignore emails sent from the inbox's own mailbox to avoid reply loops
+	if config, ok := c.Inbox.Config().(*models.InboxEmail); ok && strings.EqualFold(c.Email.FromEmail, config.Username) {
+		return nil, nil
+	}
+
+	// Skip emails which have already been ingested
+	if c.Email.MessageID != "" {
+		if _, err := c.conversationRepo.GetMessageByEmailMessageID(c.Inbox.ID, c.Email.MessageID); err == nil {
+			return nil, nil
+		}
+	}
+
+	contact, err := c.findOrCreateContact()
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, isNew, err := c.findOrCreateConversation(contact)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := c.storeAttachments(conversation)
+
+	textBody, htmlBody := c.emailBodies(attachments)
+
+	content := textBody
+	if content == "" {
+		content = c.Email.Subject
+	}
+
+	messages := []*models.Message{contactMessage(conversation, contact, content, models.MessageTypeText, c.findRepliedMessageID(conversation), map[string]interface{}{
+		"email": types.EmailMessageMetadata{
+			MessageID:  c.Email.MessageID,
+			InReplyTo:  c.Email.InReplyTo,
+			References: c.Email.References,
+			Subject:    c.Email.Subject,
+			From:       c.Email.FromEmail,
+			TextBody:   textBody,
+			HTMLBody:   htmlBody,
+		},
+	})}
+
+	for _, attachment := range attachments {
+		// Inline images shown in the HTML body are part of the email's message
+		if attachment.embedded {
+			continue
+		}
+
+		messageType := models.MessageTypeFile
+		if attachment.upload.Type == "images" {
+			messageType = models.MessageTypeImage
+		}
+
+		messages = append(messages, contactMessage(conversation, contact, attachment.upload.Path, messageType, nil, attachment.upload))
+	}
+
+	// The messages are saved before returning, so a failed email isn't flagged as seen and
+	// the next emails of the same poll can be matched against this one
+	if _, err := storeContactMessages(c.conversationRepo, c.dispatcher, conversation, messages); err != nil {
+		return nil, err
+	}
+
+	if isNew {
+		if _, err := c.commandFactory.NewHandleInboxFeaturesCommand(conversation, c.Inbox).Handle(); err != nil {
+			c.logger.Error("Failed to handle inbox features: %v", err)
+		}
+
+		c.dispatcher.Dispatch(interfaces.EventTypeConversationStart, conversation)
+	}
+
+	return conversation, nil
+}
+
+// findOrCreateContact matches the sender to a contact of the inbox's company, creating one if needed
+func (c *HandleInboundEmailCommand) findOrCreateContact() (*models.Contact, error) {
+	contact, err := c.contactRepo.GetContactByEmailAndCompanyID(c.Email.FromEmail, c.Inbox.CompanyID)
+	if err == nil {
+		return contact, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	emailAddress := c.Email.FromEmail
+	contact = &models.Contact{
+		Email:     &emailAddress,
+		CompanyID: c.Inbox.CompanyID,
+	}
+
+	if c.Email.FromName != "" {
+		name := c.Email.FromName
+		contact.Name = &name
+	}
+
+	if err := c.contactRepo.CreateContact(contact); err != nil {
+		return nil, err
+	}
+
+	c.dispatcher.Dispatch(interfaces.EventTypeContactCreated, &listeners.ContactCreatedPayload{
+		Contact: contact,
+	})
+
+	return contact, nil
+}
+
+// findOrCreateConversation finds the conversation the email replies to, or starts a new one
+func (c *HandleInboundEmailCommand) findOrCreateConversation(contact *models.Contact) (*models.Conversation, bool, error) {
+	if threadIDs := c.Email.ThreadMessageIDs(); len(threadIDs) > 0 {
+		message, err := c.conversationRepo.GetMessageByEmailMessageID(c.Inbox.ID, threadIDs...)
+		if err == nil {
+			conversation, err := c.conversationRepo.GetConversationByID(message.ConversationID, "Inbox", "Contact", "AssignedTo")
+			if err != nil {
+				return nil, false, err
+			}
+
+			if !conversation.IsClosed() && conversation.ContactID == contact.ID {
+				return conversation, false, nil
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, err
+		}
+	}
+
+	metadataBytes, err := json.Marshal(map[string]interface{}{
+		"email": map[string]interface{}{
+			"subject": c.Email.Subject,
+		},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	metadata := json.RawMessage(metadataBytes)
+
+	conversation := &models.Conversation{
+		InboxID:   c.Inbox.ID,
+		ContactID: contact.ID,
+		CompanyID: c.Inbox.CompanyID,
+		Status:    models.ConversationStatusPending,
+		Metadata:  &metadata,
+	}
+
+	if err := c.conversationRepo.CreateConversation(conversation); err != nil {
+		return nil, false, err
+	}
+
+	conversation, err = c.conversationRepo.GetConversationByID(conversation.ID, "Inbox", "Contact", "AssignedTo")
+	if err != nil {
+		return nil, false, err
+	}
+
+	return conversation, true, nil
+}
+
+// storedAttachment is an email attachment which has been written to storage
+type storedAttachment struct {
+	contentID string
+	upload    *types.UploadResult
+	// embedded is set on inline images which are referenced by the HTML body
+	embedded bool
+}
+
+// storeAttachments stores the email's attachments and inline images with the conversation's attachments.
+// Files exceeding the upload limits are skipped. Inline images which the HTML body doesn't show are
+// posted like attachments.
+func (c *HandleInboundEmailCommand) storeAttachments(conversation *models.Conversation) []storedAttachment {
+	attachments := make([]storedAttachment, 0, len(c.Email.Attachments))
+	for _, attachment := range c.Email.Attachments {
+		upload, err := c.uploadService.StoreFile(
+			attachment.Filename,
+			int64(len(attachment.Data)),
+			bytes.NewReader(attachment.Data),
+			"conversation-attachments/"+conversation.ID,
+		)
+		if err != nil {
+			c.logger.Warn("Skipping attachment %s of email %s: %v", attachment.Filename, c.Email.MessageID, err)
+			continue
+		}
+
+		attachments = append(attachments, storedAttachment{
+			contentID: attachment.ContentID,
+			upload:    upload,
+			embedded:  attachment.Inline && attachment.ContentID != "" && strings.Contains(c.Email.HTMLBody, "cid:"+attachment.ContentID),
+		})
+	}
+
+	return attachments
+}
+
+// emailBodies returns the plain text body and the sanitized HTML body of the email,
+// with inline images pointing at their stored copies
+func (c *HandleInboundEmailCommand) emailBodies(attachments []storedAttachment) (string, string) {
+	htmlBody := c.Email.HTMLBody
+	if htmlBody != "" {
+		for _, attachment := range attachments {
+			if attachment.contentID != "" {
+				htmlBody = strings.ReplaceAll(htmlBody, "cid:"+attachment.contentID, utils.Asset(attachment.upload.Path))
+			}
+		}
+		htmlBody = email.SanitizeHTML(htmlBody)
+	}
+
+	textBody := strings.TrimSpace(c.Email.TextBody)
+	if textBody == "" && htmlBody != "" {
+		textBody = email.HTMLToText(htmlBody)
+	}
+
+	return textBody, htmlBody
+}
+
+// findRepliedMessageID returns the public message of the conversation the email is a direct reply to, if any
+func (c *HandleInboundEmailCommand) findRepliedMessageID(conversation *models.Conversation) *string {
+	if c.Email.InReplyTo == "" {
+		return nil
+	}
+
+	original, err := c.conversationRepo.GetMessageByEmailMessageID(c.Inbox.ID, c.Email.InReplyTo)
+	if err != nil {
+		return nil
+	}
+
+	reply := &models.Message{ConversationID: conversation.ID}
+	if reply.CanReplyTo(original) != nil {
+		return nil
+	}
+	return &original.ID
+}
+
+// contactMessage builds a message from the contact to the conversation
+func contactMessage(conversation *models.Conversation, contact *models.Contact, content string, messageType models.MessageType, replyToMessageID *string, metadata interface{}) *models.Message {
+	return &models.Message{
+		ConversationID:   conversation.ID,
+		SenderType:       models.SenderTypeContact,
+		SenderID:         &contact.ID,
+		Content:          content,
+		Type:             messageType,
+		Metadata:         metadata,
+		ReplyToMessageID: replyToMessageID,
+	}
+}
+
+// storeContactMessages saves the messages received from a channel and announces them once stored.
+// Inbound channels store their messages before acknowledging them, so one which fails is
+// received again and retried messages are recognized.
+func storeContactMessages(conversationRepo repositories.ConversationRepository, dispatcher interfaces.Dispatcher, conversation *models.Conversation, messages []*models.Message) ([]*models.Message, error) {
+	stored, err := conversationRepo.CreateMessages(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	last := stored[len(stored)-1]
+	conversation.LastMessage = last.Content
+	conversation.LastMessageAt = &last.CreatedAt
+
+	dispatcher.Dispatch(interfaces.EventTypeConversationMessagesStored, &listeners.MessagesStoredPayload{
+		Conversation: conversation,
+		Messages:     stored,
+	})
+
+	return stored, nil
+}
+
+// NewHandleInboundEmailCommand creates a new HandleInboundEmailCommand
+func NewHandleInboundEmailCommand(
+	inbox *models.Inbox,
+	email *types.InboundEmail,
+	conversationRepo repositories.ConversationRepository,
+	contactRepo repositories.ContactRepository,
+	uploadService interfaces.UploadService,
+	commandFactory interfaces.CommandFactory,
+	dispatcher interfaces.Dispatcher,
+	logger interfaces.Logger,
+) interfaces.Command {
+	return &HandleInboundEmailCommand{
+		Inbox:            inbox,
+		Email:            email,
+		conversationRepo: conversationRepo,
+		contactRepo:      contactRepo,
+		uploadService:    uploadService,
+		commandFactory:   commandFactory,
+		dispatcher:       dispatcher,
+		logger:           logger,
+	}
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/listeners"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// HandleInboundSMSCommand represents the command to ingest a text message received by an SMS inbox
+type HandleInboundSMSCommand struct {
+	Inbox   *models.Inbox
+	Message *types.InboundSMS
+
+	// DI dependencies
+	conversationRepo repositories.ConversationRepository
+	contactRepo      repositories.ContactRepository
+	commandFactory   interfaces.CommandFactory
+	dispatcher       interfaces.Dispatcher
+	logger           interfaces.Logger
+}
+
+// Handle implements the Command interface
+func (c *HandleInboundSMSCommand) Handle() (interface{}, error) {
+	if c.Message.From == "" {
+		return nil, fmt.Errorf("sms has no sender")
+	}
+
+	// Providers retry webhooks which were not acknowledged in time
+	if c.Message.MessageID != "" {
+		if _, err := c.conversationRepo.GetMessageByChannelMessageID(c.Inbox.ID, "sms", c.Message.MessageID); err == nil {
+			return nil, nil
+		}
+	}
+
+	contact, err := c.findOrCreateContact()
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, isNew, err := c.findOrCreateConversation(contact)
+	if err != nil {
+		return nil, err
+	}
+
+	// Media is linked from the provider rather than copied to storage
+	content := strings.TrimSpace(strings.Join(append([]string{c.Message.Body}, c.Message.MediaURLs...), "\n"))
+
+	message := contactMessage(conversation, contact, content, models.MessageTypeText, nil, map[string]interface{}{
+		"sms": types.SMSMessageMetadata{
+			MessageID: c.Message.MessageID,
+			From:      c.Message.From,
+			To:        c.Message.To,
+		},
+	})
+
+	// The webhook is only acknowledged once the message is saved, so the provider retries failed ones
+	if _, err := storeContactMessages(c.conversationRepo, c.dispatcher, conversation, []*models.Message{message}); err != nil {
+		return nil, err
+	}
+
+	if isNew {
+		if _, err := c.commandFactory.NewHandleInboxFeaturesCommand(conversation, c.Inbox).Handle(); err != nil {
+			c.logger.Error("Failed to handle inbox features: %v", err)
+		}
+
+		c.dispatcher.Dispatch(interfaces.EventTypeConversationStart, conversation)
+	}
+
+	return conversation, nil
+}
+
+// findOrCreateContact matches the sender's phone number to a contact of the inbox's company, creating one if needed
+func (c *HandleInboundSMSCommand) findOrCreateContact() (*models.Contact, error) {
+	contact, err := c.contactRepo.GetContactByPhoneAndCompanyID(c.Message.From, c.Inbox.CompanyID)
+	if err == nil {
+		return contact, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	phone := c.Message.From
+	name := c.Message.From
+	contact = &models.Contact{
+		Name:      &name,
+		Phone:     &phone,
+		CompanyID: c.Inbox.CompanyID,
+	}
+
+	if err := c.contactRepo.CreateContact(contact); err != nil {
+		return nil, err
+	}
+
+	c.dispatcher.Dispatch(interfaces.EventTypeContactCreated, &listeners.ContactCreatedPayload{
+		Contact: contact,
+	})
+
+	return contact, nil
+}
+
+// findOrCreateConversation continues the contact's open conversation in the inbox, or starts a new one
+func (c *HandleInboundSMSCommand) findOrCreateConversation(contact *models.Contact) (*models.Conversation, bool, error) {
+	conversation, err := c.conversationRepo.GetLatestOpenConversationForContact(c.Inbox.ID, contact.ID, "Inbox", "Contact", "AssignedTo")
+	if err == nil {
+		return conversation, false, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	conversation = &models.Conversation{
+		InboxID:   c.Inbox.ID,
+		ContactID: contact.ID,
+		CompanyID: c.Inbox.CompanyID,
+		Status:    models.ConversationStatusPending,
+	}
+
+	if err := c.conversationRepo.CreateConversation(conversation); err != nil {
+		return nil, false, err
+	}
+
+	conversation, err = c.conversationRepo.GetConversationByID(conversation.ID, "Inbox", "Contact", "AssignedTo")
+	if err != nil {
+		return nil, false, err
+	}
+
+	return conversation, true, nil
+}
+
+// NewHandleInboundSMSCommand creates a new HandleInboundSMSCommand
+func NewHandleInboundSMSCommand(
+	inbox *models.Inbox,
+	message *types.InboundSMS,
+	conversationRepo repositories.ConversationRepository,
+	contactRepo repositories.ContactRepository,
+	commandFactory interfaces.CommandFactory,
+	dispatcher interfaces.Dispatcher,
+	logger interfaces.Logger,
+) interfaces.Command {
+	return &HandleInboundSMSCommand{
+		Inbox:            inbox,
+		Message:          message,
+		conversationRepo: conversationRepo,
+		contactRepo:      contactRepo,
+		commandFactory:   commandFactory,
+		dispatcher:       dispatcher,
+		logger:           logger,
+	}
+}
@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/listeners"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+	"live-chat-server/whatsapp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// HandleInboundWhatsAppCommand represents the command to ingest a message received by a WhatsApp inbox
+type HandleInboundWhatsAppCommand struct {
+	Inbox   *models.Inbox
+	Message *types.InboundWhatsAppMessage
+
+	// DI dependencies
+	conversationRepo repositories.ConversationRepository
+	contactRepo      repositories.ContactRepository
+	uploadService    interfaces.UploadService
+	commandFactory   interfaces.CommandFactory
+	dispatcher       interfaces.Dispatcher
+	logger           interfaces.Logger
+}
+
+// Handle implements the Command interface
+func (c *HandleInboundWhatsAppCommand) Handle() (interface{}, error) {
+	if c.Message.From == "" {
+		return nil, fmt.Errorf("whatsapp message has no sender")
+	}
+
+	config, ok := c.Inbox.Config().(*models.InboxWhatsApp)
+	if !ok {
+		return nil, fmt.Errorf("inbox %s has no whatsapp configuration", c.Inbox.ID)
+	}
+
+	if c.Message.Type != "text" && c.Message.Type != "image" && c.Message.Type != "document" {
+		c.logger.Warn("Skipping unsupported WhatsApp %s message %s", c.Message.Type, c.Message.ID)
+		return nil, nil
+	}
+
+	// Meta redelivers notifications which were not acknowledged in time
+	if _, err := c.conversationRepo.GetMessageByChannelMessageID(c.Inbox.ID, "whatsapp", c.Message.ID); err == nil {
+		return nil, nil
+	}
+
+	contact, err := c.findOrCreateContact()
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, isNew, err := c.findOrCreateConversation(contact)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := types.WhatsAppMessageMetadata{
+		MessageID: c.Message.ID,
+		From:      c.Message.From,
+		To:        config.PhoneNumberID,
+	}
+
+	var messages []*models.Message
+	if c.Message.Type == "text" {
+		messages = append(messages, contactMessage(conversation, contact, c.Message.Text, models.MessageTypeText, nil, map[string]interface{}{
+			"whatsapp": metadata,
+		}))
+	} else {
+		upload, err := c.storeMedia(config, conversation)
+		if err != nil {
+			return nil, err
+		}
+
+		messageType := models.MessageTypeFile
+		if upload.Type == "images" {
+			messageType = models.MessageTypeImage
+		}
+
+		// File messages carry the upload as their metadata, alongside the WhatsApp details
+		messages = append(messages, contactMessage(conversation, contact, upload.Path, messageType, nil, map[string]interface{}{
+			"filename":  upload.Filename,
+			"path":      upload.Path,
+			"size":      upload.Size,
+			"type":      upload.Type,
+			"extension": upload.Extension,
+			"whatsapp":  metadata,
+		}))
+
+		if caption := strings.TrimSpace(c.Message.Caption); caption != "" {
+			messages = append(messages, contactMessage(conversation, contact, caption, models.MessageTypeText, nil, nil))
+		}
+	}
+
+	// The notification is only acknowledged once the messages are saved, so Meta redelivers failed ones
+	if _, err := storeContactMessages(c.conversationRepo, c.dispatcher, conversation, messages); err != nil {
+		return nil, err
+	}
+
+	if isNew {
+		if _, err := c.commandFactory.NewHandleInboxFeaturesCommand(conversation, c.Inbox).Handle(); err != nil {
+			c.logger.Error("Failed to handle inbox features: %v", err)
+		}
+
+		c.dispatcher.Dispatch(interfaces.EventTypeConversationStart, conversation)
+	}
+
+	return conversation, nil
+}
+
+// findOrCreateContact matches the sender's WhatsApp number to a contact of the inbox's company, creating one if needed
+func (c *HandleInboundWhatsAppCommand) findOrCreateContact() (*models.Contact, error) {
+	phone := "+" + strings.TrimPrefix(c.Message.From, "+")
+
+	contact, err := c.contactRepo.GetContactByPhoneAndCompanyID(phone, c.Inbox.CompanyID)
+	if err == nil {
+		return contact, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	name := c.Message.ProfileName
+	if name == "" {
+		name = phone
+	}
+
+	contact = &models.Contact{
+		Name:      &name,
+		Phone:     &phone,
+		CompanyID: c.Inbox.CompanyID,
+	}
+
+	if err := c.contactRepo.CreateContact(contact); err != nil {
+		return nil, err
+	}
+
+	c.dispatcher.Dispatch(interfaces.EventTypeContactCreated, &listeners.ContactCreatedPayload{
+		Contact: contact,
+	})
+
+	return contact, nil
+}
+
+// findOrCreateConversation continues the contact's open conversation in the inbox, or starts a new one
+func (c *HandleInboundWhatsAppCommand) findOrCreateConversation(contact *models.Contact) (*models.Conversation, bool, error) {
+	conversation, err := c.conversationRepo.GetLatestOpenConversationForContact(c.Inbox.ID, contact.ID, "Inbox", "Contact", "AssignedTo")
+	if err == nil {
+		return conversation, false, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	conversation = &models.Conversation{
+		InboxID:   c.Inbox.ID,
+		ContactID: contact.ID,
+		CompanyID: c.Inbox.CompanyID,
+		Status:    models.ConversationStatusPending,
+	}
+
+	if err := c.conversationRepo.CreateConversation(conversation); err != nil {
+		return nil, false, err
+	}
+
+	conversation, err = c.conversationRepo.GetConversationByID(conversation.ID, "Inbox", "Contact", "AssignedTo")
+	if err != nil {
+		return nil, false, err
+	}
+
+	return conversation, true, nil
+}
+
+// storeMedia downloads the message's image or document and stores it with the conversation's attachments
+func (c *HandleInboundWhatsAppCommand) storeMedia(config *models.InboxWhatsApp, conversation *models.Conversation) (*types.UploadResult, error) {
+	client, err := whatsapp.NewClient(config, c.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := client.DownloadMedia(c.Message.MediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := c.Message.Filename
+	if filename == "" {
+		filename = c.Message.Type + utils.ExtensionForContentType(strings.TrimSpace(strings.Split(c.Message.MimeType, ";")[0]))
+	}
+
+	return c.uploadService.StoreFile(
+		filename,
+		int64(len(data)),
+		bytes.NewReader(data),
+		"conversation-attachments/"+conversation.ID,
+	)
+}
+
+// NewHandleInboundWhatsAppCommand creates a new HandleInboundWhatsAppCommand
+func NewHandleInboundWhatsAppCommand(
+	inbox *models.Inbox,
+	message *types.InboundWhatsAppMessage,
+	conversationRepo repositories.ConversationRepository,
+	contactRepo repositories.ContactRepository,
+	uploadService interfaces.UploadService,
+	commandFactory interfaces.CommandFactory,
+	dispatcher interfaces.Dispatcher,
+	logger interfaces.Logger,
+) interfaces.Command {
+	return &HandleInboundWhatsAppCommand{
+		Inbox:            inbox,
+		Message:          message,
+		conversationRepo: conversationRepo,
+		contactRepo:      contactRepo,
+		uploadService:    uploadService,
+		commandFactory:   commandFactory,
+		dispatcher:       dispatcher,
+		logger:           logger,
+	}
+}
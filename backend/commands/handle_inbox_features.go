@@ -6,7 +6,6 @@ import (
 	"live-chat-server/models"
 	"live-chat-server/repositories"
 	"live-chat-server/types"
-	"math/rand"
 )
 
 // HandleInboxFeaturesCommand represents the command to handle inbox-specific features
@@ -57,36 +56,9 @@ func (c *HandleInboxFeaturesCommand) SendBotMessage(content string) (interface{}
 	return nil, nil
 }
 
-// assignConversationToAgent assigns the conversation to an available agent
+// assignConversationToAgent assigns the conversation, or a more urgent one waiting in the inbox
 func (c *HandleInboxFeaturesCommand) assignConversationToAgent(maxAutoAssignments int) {
-	agents, err := c.inboxRepo.GetUsersForInbox(c.Conversation.InboxID)
-	if err != nil {
-		c.logger.Error("Failed to get agents", "error", err)
-		return
-	}
-
-	availableAgents := []models.User{}
-	for _, agent := range agents {
-		conversations, err := c.conversationRepo.GetActiveAssignedConversationsForUser(agent.ID)
-		if err != nil {
-			c.logger.Error("Failed to get conversations", "error", err)
-			continue
-		}
-
-		if len(conversations) >= maxAutoAssignments {
-			continue
-		}
-
-		availableAgents = append(availableAgents, agent)
-	}
-
-	if len(availableAgents) > 0 {
-		randomAgent := availableAgents[rand.Intn(len(availableAgents))]
-		err := c.conversationHandler.AssignConversation(c.Conversation, randomAgent.ID, randomAgent.GetFullName())
-		if err != nil {
-			c.logger.Error("Failed to assign conversation", "error", err)
-		}
-	}
+	autoAssignConversations(c.Conversation, maxAutoAssignments, c.inboxRepo, c.conversationRepo, c.conversationHandler, c.logger)
 }
 
 // NewHandleInboxFeaturesCommand creates a new HandleInboxFeaturesCommand
@@ -4,14 +4,16 @@ import (
 	"live-chat-server/interfaces"
 	"live-chat-server/models"
 	"live-chat-server/repositories"
-	"regexp"
-	"strings"
+	"live-chat-server/types"
+	"live-chat-server/utils"
 )
 
 type HandleMessageNotificationCommand struct {
 	conversation        *models.Conversation
 	message             *models.Message
 	conversationRepo    repositories.ConversationRepository
+	readRepo            repositories.ConversationReadRepository
+	participantRepo     repositories.ConversationParticipantRepository
 	userRepo            repositories.UserRepository
 	notificationService interfaces.NotificationService
 	pubSub              interfaces.PubSub
@@ -23,7 +25,7 @@ func (c *HandleMessageNotificationCommand) Handle() (interface{}, error) {
 		if c.conversation.AssignedToID != nil {
 			// We can use the pubsub service to identify if the user (agent) is online
 			// and is listening to their channel
-			if len(c.pubSub.GetSubscribers("user:"+*c.conversation.AssignedToID)) == 0 {
+			if len(c.pubSub.GetSubscribers("user:"+*c.conversation.AssignedToID)) == 0 && !c.hasRead(*c.conversation.AssignedToID) {
 				user, err := c.userRepo.GetUserByID(*c.conversation.AssignedToID)
 				if err != nil {
 					c.logger.Error("Error getting user by ID:", err)
@@ -44,50 +46,104 @@ func (c *HandleMessageNotificationCommand) Handle() (interface{}, error) {
 		}
 	}
 
-	// It's a private message.
-	// Let's check if the message contains a mention
-	if c.message.Private && strings.Contains(c.message.Content, "@") {
-		users, err := c.userRepo.GetUsersByCompanyID(c.conversation.CompanyID)
-		usersKeyedByName := make(map[string]models.User)
+	// Let everyone mentioned in a private note know about it
+	mentionedUserIDs := c.message.GetMentionedUserIDs()
 
-		for _, user := range users {
-			usersKeyedByName[user.GetFullName()] = user
+	c.notifyParticipants(mentionedUserIDs)
+
+	if len(mentionedUserIDs) == 0 {
+		return nil, nil
+	}
+
+	// The payload is built from a copy as it rewrites attachment paths on the message
+	note := *c.message
+	notePayload := note.ToPayload()
+
+	for _, userID := range mentionedUserIDs {
+		if c.message.SenderID != nil && *c.message.SenderID == userID {
+			continue
 		}
 
-		if err == nil {
-			// TODO This can be moved into a function
-			regex := regexp.MustCompile(`@([A-Za-z]+ [A-Za-z]+)`)
-			matches := regex.FindStringSubmatch(c.message.Content)
-			if len(matches) > 1 {
-				for _, match := range matches {
-					if user, ok := usersKeyedByName[match]; ok {
-						c.logger.Info("Mentioned user", "user", user.GetFullName())
-
-						if user.NotificationSettings.Mentions {
-
-							c.notificationService.CreateNotification(
-								&user,
-								models.UserNotificationTypeMention,
-								map[string]interface{}{
-									"conversation_id": c.conversation.ID,
-								},
-							)
-						}
-					}
-				}
-			}
-		} else {
-			c.logger.Error("Error getting users by company ID:", err)
+		user, err := c.userRepo.GetUserByID(userID)
+		if err != nil {
+			c.logger.Error("Failed to get mentioned user %s: %v", userID, err)
+			continue
+		}
+
+		c.pubSub.Publish("user:"+user.ID, types.EventTypeMention, &types.OutgoingMentionPayload{
+			ConversationID: c.conversation.ID,
+			MessageID:      c.message.ID,
+			Message:        notePayload,
+		})
+
+		if user.NotificationSettings.Mentions && !c.hasRead(user.ID) {
+			c.notificationService.CreateNotification(
+				user,
+				models.UserNotificationTypeMention,
+				map[string]interface{}{
+					"conversation_id": c.conversation.ID,
+					"message_id":      c.message.ID,
+				},
+			)
 		}
 	}
 
 	return nil, nil
 }
 
+// notifyParticipants lets the agents following or collaborating on the conversation know about new contact
+// messages and mentions. The assignee, the sender and the mentioned agents are notified on their own.
+func (c *HandleMessageNotificationCommand) notifyParticipants(mentionedUserIDs []string) {
+	var notificationType models.UserNotificationType
+	switch {
+	case c.message.SenderType == models.SenderTypeContact:
+		notificationType = models.UserNotificationTypeFollowedMessage
+	case len(mentionedUserIDs) > 0:
+		notificationType = models.UserNotificationTypeFollowedMention
+	default:
+		return
+	}
+
+	participants, err := c.participantRepo.GetParticipants(c.conversation.ID)
+	if err != nil {
+		c.logger.Error("Failed to get participants of conversation %s: %v", c.conversation.ID, err)
+		return
+	}
+
+	for _, participant := range participants {
+		if participant.User == nil ||
+			(c.conversation.AssignedToID != nil && *c.conversation.AssignedToID == participant.UserID) ||
+			(c.message.SenderID != nil && *c.message.SenderID == participant.UserID) ||
+			utils.Contains(mentionedUserIDs, participant.UserID) ||
+			c.hasRead(participant.UserID) {
+			continue
+		}
+
+		data := map[string]interface{}{
+			"conversation_id": c.conversation.ID,
+			"message_id":      c.message.ID,
+		}
+		if err := c.notificationService.CreateNotification(participant.User, notificationType, data); err != nil {
+			c.logger.Error("Failed to notify participant %s of conversation %s: %v", participant.UserID, c.conversation.ID, err)
+		}
+	}
+}
+
+// hasRead returns whether the agent has already read the message, so there is nothing to notify them about
+func (c *HandleMessageNotificationCommand) hasRead(userID string) bool {
+	read, err := c.readRepo.GetConversationRead(c.conversation.ID, models.SenderTypeAgent, userID)
+	if err != nil {
+		return false
+	}
+	return read.HasRead(c.message)
+}
+
 func NewHandleMessageNotificationCommand(
 	conversation *models.Conversation,
 	message *models.Message,
 	conversationRepo repositories.ConversationRepository,
+	readRepo repositories.ConversationReadRepository,
+	participantRepo repositories.ConversationParticipantRepository,
 	userRepo repositories.UserRepository,
 	notificationService interfaces.NotificationService,
 	pubSub interfaces.PubSub,
@@ -96,6 +152,8 @@ func NewHandleMessageNotificationCommand(
 	return &HandleMessageNotificationCommand{
 		conversation:        conversation,
 		conversationRepo:    conversationRepo,
+		readRepo:            readRepo,
+		participantRepo:     participantRepo,
 		userRepo:            userRepo,
 		notificationService: notificationService,
 		message:             message,
@@ -19,8 +19,10 @@ type HandlePreChatFormCommand struct {
 	conversationRepo repositories.ConversationRepository
 	contactRepo      repositories.ContactRepository
 	inboxRepo        repositories.InboxRepository
+	channels         interfaces.ChannelRegistry
 	logger           interfaces.Logger
 	dispatcher       interfaces.Dispatcher
+	commandFactory   interfaces.CommandFactory
 }
 
 // Handle implements the Command interface
@@ -59,6 +61,8 @@ func (c *HandlePreChatFormCommand) Handle() (interface{}, error) {
 		return nil, err
 	}
 
+	c.updatePriorityFromFormData(mappedFormData)
+
 	// Now re-fetch the conversation as the contact may of been updated
 	conversation, err := c.conversationRepo.GetConversationByID(c.Conversation.ID, "Messages", "Inbox", "Contact", "AssignedTo")
 	if err != nil {
@@ -96,16 +100,28 @@ func (c *HandlePreChatFormCommand) mapContactFromFormData() ([]types.PreChatForm
 		return nil, err
 	}
 
+	if err := c.channels.LoadConfig(inbox); err != nil {
+		return nil, err
+	}
+
 	mappedFormData := make([]types.PreChatFormField, 0)
 
+	webChat, ok := inbox.Config().(*models.InboxWebChat)
+	if !ok {
+		return mappedFormData, nil
+	}
+
 	// Map the formdata into the inbox fields
-	for _, field := range inbox.WebChat.PreChatForm.Fields {
+	for _, field := range webChat.PreChatForm.Fields {
 		if value, ok := c.FormData[field.ID]; ok {
 			mappedFormData = append(mappedFormData, types.PreChatFormField{
 				ID:           field.ID,
 				Type:         field.Type,
 				Value:        value.(string),
 				ContactField: field.ContactField,
+				// Kept so the priority the answer maps to can be resolved
+				ConversationField: field.ConversationField,
+				PriorityOptions:   field.PriorityOptions,
 			})
 		}
 	}
@@ -140,6 +156,21 @@ func (c *HandlePreChatFormCommand) updateContactFromFormData(mappedFormData []ty
 	return contact, nil
 }
 
+// updatePriorityFromFormData sets the conversation's priority from the answers mapped to it.
+// An answer which is not a known priority is ignored rather than failing the form.
+func (c *HandlePreChatFormCommand) updatePriorityFromFormData(mappedFormData []types.PreChatFormField) {
+	for _, field := range mappedFormData {
+		priority := models.ConversationPriority(field.Priority())
+		if priority == "" {
+			continue
+		}
+
+		if _, err := c.commandFactory.NewSetConversationPriorityCommand(c.Conversation, priority, nil).Handle(); err != nil {
+			c.logger.Error("Failed to set priority %s of conversation %s from pre-chat form: %v", priority, c.Conversation.ID, err)
+		}
+	}
+}
+
 // NewHandlePreChatFormCommand creates a new HandlePreChatFormCommand
 func NewHandlePreChatFormCommand(
 	client *types.WebSocketClient,
@@ -148,8 +179,10 @@ func NewHandlePreChatFormCommand(
 	conversationRepo repositories.ConversationRepository,
 	contactRepo repositories.ContactRepository,
 	inboxRepo repositories.InboxRepository,
+	channels interfaces.ChannelRegistry,
 	logger interfaces.Logger,
 	dispatcher interfaces.Dispatcher,
+	commandFactory interfaces.CommandFactory,
 ) interfaces.Command {
 	return &HandlePreChatFormCommand{
 		Client:           client,
@@ -158,7 +191,9 @@ func NewHandlePreChatFormCommand(
 		conversationRepo: conversationRepo,
 		contactRepo:      contactRepo,
 		inboxRepo:        inboxRepo,
+		channels:         channels,
 		logger:           logger,
 		dispatcher:       dispatcher,
+		commandFactory:   commandFactory,
 	}
 }
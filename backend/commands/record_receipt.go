@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"errors"
+	"live-chat-server/interfaces"
+	"live-chat-server/listeners"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+
+	"gorm.io/gorm"
+)
+
+// RecordReceiptCommand moves the delivered or read pointer of an agent or the contact up to a message.
+// Without a message ID the pointer moves to the latest message the reader can see.
+type RecordReceiptCommand struct {
+	Conversation *models.Conversation
+	Receipt      models.ReceiptType
+	ReaderType   models.SenderType
+	ReaderID     string
+	MessageID    string
+
+	// DI dependencies
+	conversationRepo repositories.ConversationRepository
+	readRepo         repositories.ConversationReadRepository
+	dispatcher       interfaces.Dispatcher
+}
+
+func (c *RecordReceiptCommand) Handle() (interface{}, error) {
+	// Private notes are never shown to contacts
+	includePrivate := c.ReaderType == models.SenderTypeAgent
+
+	var message *models.Message
+	var err error
+	if c.MessageID == "" {
+		message, err = c.conversationRepo.GetLatestMessage(c.Conversation.ID, includePrivate)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+	} else {
+		message, err = c.conversationRepo.GetMessageByID(c.MessageID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrReceiptMessageNotFound
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if message.ConversationID != c.Conversation.ID || (message.Private && !includePrivate) {
+		return nil, models.ErrReceiptMessageNotFound
+	}
+
+	read, advanced, err := c.readRepo.AdvanceConversationRead(c.Conversation.ID, c.ReaderType, c.ReaderID, c.Receipt, message)
+	if err != nil {
+		return nil, err
+	}
+
+	if advanced {
+		c.dispatcher.Dispatch(interfaces.EventTypeConversationReceipt, &listeners.ConversationReceiptPayload{
+			Conversation: c.Conversation,
+			Read:         read,
+			Message:      message,
+		})
+	}
+
+	return read, nil
+}
+
+func NewRecordReceiptCommand(
+	conversation *models.Conversation,
+	receipt models.ReceiptType,
+	readerType models.SenderType,
+	readerID string,
+	messageID string,
+	conversationRepo repositories.ConversationRepository,
+	readRepo repositories.ConversationReadRepository,
+	dispatcher interfaces.Dispatcher,
+) interfaces.Command {
+	return &RecordReceiptCommand{
+		Conversation:     conversation,
+		Receipt:          receipt,
+		ReaderType:       readerType,
+		ReaderID:         readerID,
+		MessageID:        messageID,
+		conversationRepo: conversationRepo,
+		readRepo:         readRepo,
+		dispatcher:       dispatcher,
+	}
+}
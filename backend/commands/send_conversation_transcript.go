@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/transcript"
+	"live-chat-server/utils"
+	"time"
+)
+
+// defaultTranscriptColor is the accent of transcript emails for inboxes without a widget colour
+const defaultTranscriptColor = "#2563eb"
+
+// transcriptRequestInterval is how long a contact waits between transcripts asked for from the widget
+const transcriptRequestInterval = 10 * time.Minute
+
+// SendConversationTranscriptCommand emails the contact a transcript of their conversation,
+// without the private notes, and records the attempt in the audit trail.
+// Contacts asking for one from the widget get at most one per transcriptRequestInterval.
+type SendConversationTranscriptCommand struct {
+	ConversationID string
+	Trigger        models.TranscriptTrigger
+	// Timezone is the IANA name of the contact's timezone, UTC when empty or unknown
+	Timezone string
+
+	// DI dependencies
+	conversationRepo repositories.ConversationRepository
+	channels         interfaces.ChannelRegistry
+	emailService     interfaces.EmailService
+	auditService     interfaces.AuditService
+	logger           interfaces.Logger
+}
+
+func (c *SendConversationTranscriptCommand) Handle() (interface{}, error) {
+	conversation, err := c.conversationRepo.GetConversationByID(c.ConversationID, "Contact", "Inbox.Company", "Messages")
+	if err != nil {
+		return nil, err
+	}
+
+	email := utils.GetStringValue(conversation.Contact.Email)
+	if email == "" {
+		return nil, models.ErrContactHasNoEmail
+	}
+
+	// The address comes from the contact, so the company's mail setup can't be used to flood it
+	if c.Trigger == models.TranscriptTriggerRequest {
+		marked, err := c.conversationRepo.MarkTranscriptRequested(conversation.ID, transcriptRequestInterval)
+		if err != nil {
+			return nil, err
+		}
+		if !marked {
+			return nil, models.ErrTranscriptRecentlyRequested
+		}
+	}
+
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil || c.Timezone == "" {
+		loc = time.UTC
+	}
+
+	t := transcript.New(conversation, conversation.Messages, loc, false)
+
+	inbox := &conversation.Inbox
+	if err := c.channels.LoadConfig(inbox); err != nil {
+		c.logger.Warn("Failed to load configuration of inbox %s: %v", inbox.ID, err)
+	}
+
+	primaryColor := defaultTranscriptColor
+	if webChat, ok := inbox.Config().(*models.InboxWebChat); ok && webChat.WidgetCustomization.PrimaryColor != "" {
+		primaryColor = webChat.WidgetCustomization.PrimaryColor
+	}
+
+	companyLogo := ""
+	if inbox.Company.Logo != "" {
+		companyLogo = utils.Asset(inbox.Company.Logo)
+	}
+
+	contactName := t.ContactName
+	if contactName == "" {
+		contactName = "there"
+	}
+
+	subject := fmt.Sprintf("Your conversation with %s", inbox.Company.Name)
+	err = c.emailService.SendTemplatedEmailAsync(email, subject, "conversation_transcript.html", map[string]interface{}{
+		"CompanyName":  inbox.Company.Name,
+		"CompanyLogo":  companyLogo,
+		"InboxName":    inbox.Name,
+		"PrimaryColor": primaryColor,
+		"ContactName":  contactName,
+		"Timezone":     loc.String(),
+		"Entries":      t.Entries,
+	})
+
+	c.audit(conversation, email, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return conversation, nil
+}
+
+// audit records the queued email as a system event, along with why it could not be queued.
+// The email itself is sent in the background.
+func (c *SendConversationTranscriptCommand) audit(conversation *models.Conversation, email string, sendErr error) {
+	action := models.AuditActionConversationTranscriptQueued
+	description := "Conversation transcript queued for emailing to the contact"
+	metadata := map[string]interface{}{
+		"conversation_id": conversation.ID,
+		"contact_id":      conversation.ContactID,
+		"email":           email,
+		"trigger":         c.Trigger,
+	}
+
+	if sendErr != nil {
+		action = models.AuditActionConversationTranscriptFailed
+		description = "Conversation transcript could not be queued for emailing to the contact"
+		metadata["error"] = sendErr.Error()
+	}
+
+	if err := c.auditService.LogSystemEvent(string(action), "conversation", description, metadata); err != nil {
+		c.logger.Error("Failed to audit transcript of conversation %s: %v", conversation.ID, err)
+	}
+}
+
+func NewSendConversationTranscriptCommand(
+	conversationID string,
+	trigger models.TranscriptTrigger,
+	timezone string,
+	conversationRepo repositories.ConversationRepository,
+	channels interfaces.ChannelRegistry,
+	emailService interfaces.EmailService,
+	auditService interfaces.AuditService,
+	logger interfaces.Logger,
+) interfaces.Command {
+	return &SendConversationTranscriptCommand{
+		ConversationID:   conversationID,
+		Trigger:          trigger,
+		Timezone:         timezone,
+		conversationRepo: conversationRepo,
+		channels:         channels,
+		emailService:     emailService,
+		auditService:     auditService,
+		logger:           logger,
+	}
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+)
+
+// SetConversationPriorityCommand changes how urgently a conversation needs an agent.
+// ActorID is the agent making the change, or nil when an integration or the pre-chat form does.
+type SetConversationPriorityCommand struct {
+	Conversation *models.Conversation
+	Priority     models.ConversationPriority
+	ActorID      *string
+
+	// DI dependencies
+	conversationRepo repositories.ConversationRepository
+	dispatcher       interfaces.Dispatcher
+	auditService     interfaces.AuditService
+	logger           interfaces.Logger
+}
+
+func (c *SetConversationPriorityCommand) Handle() (interface{}, error) {
+	conversation := c.Conversation
+
+	if !c.Priority.IsValid() {
+		return nil, models.ErrInvalidConversationPriority
+	}
+
+	if conversation.Priority == c.Priority {
+		return conversation, nil
+	}
+
+	previousPriority := conversation.Priority
+	conversation.Priority = c.Priority
+
+	if err := c.conversationRepo.UpdateConversation(conversation); err != nil {
+		conversation.Priority = previousPriority
+		return nil, err
+	}
+
+	c.dispatcher.Dispatch(interfaces.EventTypeConversationPriority, conversation)
+
+	auditConversationChange(c.auditService, c.logger, conversation, c.ActorID, models.AuditActionConversationPriority, "Conversation priority changed by the system", map[string]interface{}{
+		"from": previousPriority,
+		"to":   conversation.Priority,
+	})
+
+	return conversation, nil
+}
+
+func NewSetConversationPriorityCommand(
+	conversation *models.Conversation,
+	priority models.ConversationPriority,
+	actorID *string,
+	conversationRepo repositories.ConversationRepository,
+	dispatcher interfaces.Dispatcher,
+	auditService interfaces.AuditService,
+	logger interfaces.Logger,
+) interfaces.Command {
+	return &SetConversationPriorityCommand{
+		Conversation:     conversation,
+		Priority:         priority,
+		ActorID:          actorID,
+		conversationRepo: conversationRepo,
+		dispatcher:       dispatcher,
+		auditService:     auditService,
+		logger:           logger,
+	}
+}
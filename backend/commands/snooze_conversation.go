@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/jobs"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"time"
+)
+
+// SnoozeConversationCommand parks a conversation until the given time, or until the contact replies.
+// Snoozing an already snoozed conversation moves its wake up time.
+type SnoozeConversationCommand struct {
+	Conversation *models.Conversation
+	Until        time.Time
+	ActorID      *string
+
+	// DI dependencies
+	conversationRepo    repositories.ConversationRepository
+	conversationHandler interfaces.ConversationHandler
+	dispatcher          interfaces.Dispatcher
+	jobClient           interfaces.JobClient
+	auditService        interfaces.AuditService
+	logger              interfaces.Logger
+}
+
+func (c *SnoozeConversationCommand) Handle() (interface{}, error) {
+	conversation := c.Conversation
+
+	if !c.Until.After(time.Now()) {
+		return nil, models.ErrSnoozeUntilInPast
+	}
+
+	if !conversation.IsSnoozed() && !conversation.CanTransition(models.ConversationTransitionSnooze) {
+		return nil, models.ErrInvalidConversationTransition
+	}
+
+	previousStatus := conversation.Status
+	previousSnoozedUntil := conversation.SnoozedUntil
+	until := c.Until.UTC()
+	conversation.Status = models.ConversationStatusSnoozed
+	conversation.SnoozedUntil = &until
+
+	if err := c.conversationRepo.UpdateConversation(conversation); err != nil {
+		conversation.Status = previousStatus
+		conversation.SnoozedUntil = previousSnoozedUntil
+		return nil, err
+	}
+
+	// The wake job checks the snooze time, so a job left over from an earlier snooze does nothing
+	if err := c.jobClient.EnqueueWithOptions("wake_snoozed_conversation", jobs.WakeSnoozedConversationJobPayload{
+		ConversationID: conversation.ID,
+	}, jobs.ProcessAt(until)...); err != nil {
+		c.logger.Error("Failed to schedule wake up of conversation %s: %v", conversation.ID, err)
+	}
+
+	if err := c.conversationHandler.SendSystemMessage(conversation, fmt.Sprintf("This conversation has been snoozed until %s.", until.UTC().Format(time.RFC3339))); err != nil {
+		c.logger.Error("Failed to send snooze system message for conversation %s: %v", conversation.ID, err)
+	}
+
+	c.dispatcher.Dispatch(interfaces.EventTypeConversationSnooze, conversation)
+
+	auditConversationChange(c.auditService, c.logger, conversation, c.ActorID, models.AuditActionConversationSnooze, "Conversation snoozed by the system", map[string]interface{}{
+		"from":          previousStatus,
+		"snoozed_until": until,
+	})
+
+	return conversation, nil
+}
+
+func NewSnoozeConversationCommand(
+	conversation *models.Conversation,
+	until time.Time,
+	actorID *string,
+	conversationRepo repositories.ConversationRepository,
+	conversationHandler interfaces.ConversationHandler,
+	dispatcher interfaces.Dispatcher,
+	jobClient interfaces.JobClient,
+	auditService interfaces.AuditService,
+	logger interfaces.Logger,
+) interfaces.Command {
+	return &SnoozeConversationCommand{
+		Conversation:        conversation,
+		Until:               until,
+		ActorID:             actorID,
+		conversationRepo:    conversationRepo,
+		conversationHandler: conversationHandler,
+		dispatcher:          dispatcher,
+		jobClient:           jobClient,
+		auditService:        auditService,
+		logger:              logger,
+	}
+}
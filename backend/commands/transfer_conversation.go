@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/listeners"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/utils"
+)
+
+// TransferConversationCommand moves a conversation to another inbox of the same company and channel.
+// AssigneeID hands it to a member of the new inbox; without one the current assignee keeps it
+// if they are a member, and AutoAssign lets the new inbox's auto-assignment pick an agent.
+type TransferConversationCommand struct {
+	Conversation *models.Conversation
+	Inbox        *models.Inbox
+	AssigneeID   *string
+	AutoAssign   bool
+	ActorID      *string
+
+	// DI dependencies
+	conversationRepo    repositories.ConversationRepository
+	inboxRepo           repositories.InboxRepository
+	conversationHandler interfaces.ConversationHandler
+	dispatcher          interfaces.Dispatcher
+	auditService        interfaces.AuditService
+	logger              interfaces.Logger
+}
+
+func (c *TransferConversationCommand) Handle() (interface{}, error) {
+	conversation := c.Conversation
+
+	if conversation.InboxID == c.Inbox.ID {
+		return nil, models.ErrConversationAlreadyInInbox
+	}
+
+	if conversation.IsClosed() {
+		return nil, models.ErrConversationClosed
+	}
+
+	fromInbox := conversation.Inbox
+	if fromInbox.ID == "" {
+		inbox, err := c.inboxRepo.GetInboxByID(conversation.InboxID)
+		if err != nil {
+			return nil, err
+		}
+		fromInbox = *inbox
+	}
+
+	// Replies are sent through the inbox's channel, which can only reach the contact the way they wrote in
+	if fromInbox.Type != c.Inbox.Type {
+		return nil, models.ErrInboxTypeMismatch
+	}
+
+	agents, err := c.inboxRepo.GetUsersForInbox(c.Inbox.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	agentIDs := make([]string, len(agents))
+	var assignee *models.User
+	for i := range agents {
+		agentIDs[i] = agents[i].ID
+		if c.AssigneeID != nil && agents[i].ID == *c.AssigneeID {
+			assignee = &agents[i]
+		}
+	}
+
+	if c.AssigneeID != nil && assignee == nil {
+		return nil, models.ErrAssigneeNotInboxMember
+	}
+
+	conversation.InboxID = c.Inbox.ID
+	conversation.Inbox = *c.Inbox
+
+	// An assignee who cannot see the new inbox would lose the conversation, so it waits for an agent again
+	if conversation.AssignedToID != nil && !utils.Contains(agentIDs, *conversation.AssignedToID) {
+		conversation.AssignedToID = nil
+		conversation.AssignedTo = nil
+		if conversation.Status == models.ConversationStatusActive {
+			conversation.Status = models.ConversationStatusPending
+		}
+	}
+
+	if err := c.conversationRepo.UpdateConversation(conversation); err != nil {
+		return nil, err
+	}
+
+	if err := c.conversationHandler.SendSystemMessage(conversation, fmt.Sprintf("This conversation has been transferred from %s to %s.", fromInbox.Name, c.Inbox.Name)); err != nil {
+		c.logger.Error("Failed to send transfer system message for conversation %s: %v", conversation.ID, err)
+	}
+
+	if assignee != nil {
+		if err := c.conversationHandler.AssignConversation(conversation, assignee.ID, assignee.GetFullName()); err != nil {
+			c.logger.Error("Failed to assign transferred conversation %s: %v", conversation.ID, err)
+		}
+	} else if c.AutoAssign && c.Inbox.AutoAssignmentEnabled && conversation.AssignedToID == nil {
+		autoAssignConversations(conversation, c.Inbox.MaxAutoAssignments, c.inboxRepo, c.conversationRepo, c.conversationHandler, c.logger)
+	}
+
+	c.dispatcher.Dispatch(interfaces.EventTypeConversationTransfer, &listeners.ConversationTransferPayload{
+		Conversation: conversation,
+		FromInbox:    &fromInbox,
+		AgentIDs:     agentIDs,
+	})
+
+	auditConversationChange(c.auditService, c.logger, conversation, c.ActorID, models.AuditActionConversationTransfer, "Conversation transferred by the system", map[string]interface{}{
+		"from_inbox_id":  fromInbox.ID,
+		"to_inbox_id":    c.Inbox.ID,
+		"assigned_to_id": conversation.AssignedToID,
+	})
+
+	return conversation, nil
+}
+
+func NewTransferConversationCommand(
+	conversation *models.Conversation,
+	inbox *models.Inbox,
+	assigneeID *string,
+	autoAssign bool,
+	actorID *string,
+	conversationRepo repositories.ConversationRepository,
+	inboxRepo repositories.InboxRepository,
+	conversationHandler interfaces.ConversationHandler,
+	dispatcher interfaces.Dispatcher,
+	auditService interfaces.AuditService,
+	logger interfaces.Logger,
+) interfaces.Command {
+	return &TransferConversationCommand{
+		Conversation:        conversation,
+		Inbox:               inbox,
+		AssigneeID:          assigneeID,
+		AutoAssign:          autoAssign,
+		ActorID:             actorID,
+		conversationRepo:    conversationRepo,
+		inboxRepo:           inboxRepo,
+		conversationHandler: conversationHandler,
+		dispatcher:          dispatcher,
+		auditService:        auditService,
+		logger:              logger,
+	}
+}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+)
+
+// transitionSystemMessages holds the system message posted for each transition
+var transitionSystemMessages = map[models.ConversationTransition]string{
+	models.ConversationTransitionResolve: "This conversation has been resolved.",
+	models.ConversationTransitionReopen:  "This conversation has been reopened.",
+	models.ConversationTransitionPending: "This conversation has been marked as pending.",
+	models.ConversationTransitionClose:   "This conversation has been closed.",
+	models.ConversationTransitionWake:    "This conversation is no longer snoozed.",
+}
+
+// transitionEvents holds the event dispatched for each transition
+var transitionEvents = map[models.ConversationTransition]interfaces.EventType{
+	models.ConversationTransitionResolve: interfaces.EventTypeConversationResolve,
+	models.ConversationTransitionReopen:  interfaces.EventTypeConversationReopen,
+	models.ConversationTransitionPending: interfaces.EventTypeConversationPending,
+	models.ConversationTransitionClose:   interfaces.EventTypeConversationClose,
+	models.ConversationTransitionWake:    interfaces.EventTypeConversationWake,
+}
+
+// transitionAuditActions holds the audit action recorded for each transition
+var transitionAuditActions = map[models.ConversationTransition]models.AuditAction{
+	models.ConversationTransitionResolve: models.AuditActionConversationResolve,
+	models.ConversationTransitionReopen:  models.AuditActionConversationReopen,
+	models.ConversationTransitionPending: models.AuditActionConversationPending,
+	models.ConversationTransitionClose:   models.AuditActionConversationClose,
+	models.ConversationTransitionWake:    models.AuditActionConversationWake,
+}
+
+// transitionSystemDescriptions holds the audit description of each transition made by the system
+var transitionSystemDescriptions = map[models.ConversationTransition]string{
+	models.ConversationTransitionResolve: "Conversation resolved by the system",
+	models.ConversationTransitionReopen:  "Conversation reopened by the system",
+	models.ConversationTransitionPending: "Conversation marked as pending by the system",
+	models.ConversationTransitionClose:   "Conversation closed by the system",
+	models.ConversationTransitionWake:    "Snoozed conversation woken up by the system",
+}
+
+// TransitionConversationCommand moves a conversation to a new status.
+// ActorID is the agent making the change, or nil when the system does.
+type TransitionConversationCommand struct {
+	Conversation *models.Conversation
+	Transition   models.ConversationTransition
+	ActorID      *string
+
+	// DI dependencies
+	conversationRepo    repositories.ConversationRepository
+	conversationHandler interfaces.ConversationHandler
+	dispatcher          interfaces.Dispatcher
+	auditService        interfaces.AuditService
+	logger              interfaces.Logger
+}
+
+func (c *TransitionConversationCommand) Handle() (interface{}, error) {
+	conversation := c.Conversation
+
+	// Checked first, so a transition which doesn't apply isn't mistaken for one already made
+	if !conversation.CanTransition(c.Transition) {
+		return nil, models.ErrInvalidConversationTransition
+	}
+
+	status := conversation.TransitionStatus(c.Transition)
+	if conversation.Status == status {
+		return conversation, nil
+	}
+
+	previousStatus := conversation.Status
+	previousSnoozedUntil := conversation.SnoozedUntil
+	conversation.Status = status
+	conversation.SnoozedUntil = nil
+
+	if err := c.conversationRepo.UpdateConversation(conversation); err != nil {
+		conversation.Status = previousStatus
+		conversation.SnoozedUntil = previousSnoozedUntil
+		return nil, err
+	}
+
+	if err := c.conversationHandler.SendSystemMessage(conversation, transitionSystemMessages[c.Transition]); err != nil {
+		c.logger.Error("Failed to send %s system message for conversation %s: %v", c.Transition, conversation.ID, err)
+	}
+
+	c.dispatcher.Dispatch(transitionEvents[c.Transition], conversation)
+
+	c.audit(previousStatus)
+
+	return conversation, nil
+}
+
+// audit records the transition against the agent who made it, or as a system event
+func (c *TransitionConversationCommand) audit(previousStatus models.ConversationStatus) {
+	auditConversationChange(c.auditService, c.logger, c.Conversation, c.ActorID, transitionAuditActions[c.Transition], transitionSystemDescriptions[c.Transition], map[string]interface{}{
+		"transition": c.Transition,
+		"from":       previousStatus,
+		"to":         c.Conversation.Status,
+	})
+}
+
+// auditConversationChange records a change of a conversation against the agent who made it,
+// or as a system event with the given description when actorID is nil
+func auditConversationChange(auditService interfaces.AuditService, logger interfaces.Logger, conversation *models.Conversation, actorID *string, action models.AuditAction, systemDescription string, metadata map[string]interface{}) {
+	var err error
+	if actorID != nil {
+		err = auditService.LogConversationAction(*actorID, conversation.ID, string(action), metadata)
+	} else {
+		metadata["conversation_id"] = conversation.ID
+		err = auditService.LogSystemEvent(string(action), "conversation", systemDescription, metadata)
+	}
+
+	if err != nil {
+		logger.Error("Failed to audit %s of conversation %s: %v", action, conversation.ID, err)
+	}
+}
+
+func NewTransitionConversationCommand(
+	conversation *models.Conversation,
+	transition models.ConversationTransition,
+	actorID *string,
+	conversationRepo repositories.ConversationRepository,
+	conversationHandler interfaces.ConversationHandler,
+	dispatcher interfaces.Dispatcher,
+	auditService interfaces.AuditService,
+	logger interfaces.Logger,
+) interfaces.Command {
+	return &TransitionConversationCommand{
+		Conversation:        conversation,
+		Transition:          transition,
+		ActorID:             actorID,
+		conversationRepo:    conversationRepo,
+		conversationHandler: conversationHandler,
+		dispatcher:          dispatcher,
+		auditService:        auditService,
+		logger:              logger,
+	}
+}
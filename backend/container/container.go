@@ -1,6 +1,7 @@
 package container
 
 import (
+	"live-chat-server/channels"
 	"live-chat-server/config"
 	"live-chat-server/context"
 	"live-chat-server/disk"
@@ -69,6 +70,24 @@ func (c *DIContainer) GetCompanyRepo() repositories.CompanyRepository {
 	return repo
 }
 
+// GetConversationReadRepo retrieves the conversation read repository
+func (c *DIContainer) GetConversationReadRepo() repositories.ConversationReadRepository {
+	var repo repositories.ConversationReadRepository
+	c.dig.Invoke(func(r repositories.ConversationReadRepository) {
+		repo = r
+	})
+	return repo
+}
+
+// GetConversationParticipantRepo retrieves the conversation participant repository
+func (c *DIContainer) GetConversationParticipantRepo() repositories.ConversationParticipantRepository {
+	var repo repositories.ConversationParticipantRepository
+	c.dig.Invoke(func(r repositories.ConversationParticipantRepository) {
+		repo = r
+	})
+	return repo
+}
+
 // GetCannedResponseRepo retrieves the canned response repository
 func (c *DIContainer) GetCannedResponseRepo() repositories.CannedResponseRepository {
 	var repo repositories.CannedResponseRepository
@@ -105,6 +124,15 @@ func (c *DIContainer) GetDiskManager() storage.Manager {
 	return manager
 }
 
+// GetPrivateDiskManager retrieves the disk manager of files which aren't served publicly
+func (c *DIContainer) GetPrivateDiskManager() storage.PrivateManager {
+	var manager storage.PrivateManager
+	c.dig.Invoke(func(m storage.PrivateManager) {
+		manager = m
+	})
+	return manager
+}
+
 // GetJobClient retrieves the job client
 func (c *DIContainer) GetJobClient() interfaces.JobClient {
 	var client interfaces.JobClient
@@ -195,6 +223,15 @@ func (c *DIContainer) GetCommandFactory() interfaces.CommandFactory {
 	return factory
 }
 
+// GetChannelRegistry retrieves the channel registry
+func (c *DIContainer) GetChannelRegistry() interfaces.ChannelRegistry {
+	var registry interfaces.ChannelRegistry
+	c.dig.Invoke(func(r interfaces.ChannelRegistry) {
+		registry = r
+	})
+	return registry
+}
+
 // GetDig returns the underlying dig container
 func (c *DIContainer) GetDig() *dig.Container {
 	return c.dig
@@ -227,6 +264,15 @@ func (c *DIContainer) GetHealthService() interfaces.HealthService {
 	return service
 }
 
+// GetAuditService retrieves the audit service
+func (c *DIContainer) GetAuditService() interfaces.AuditService {
+	var service interfaces.AuditService
+	c.dig.Invoke(func(s interfaces.AuditService) {
+		service = s
+	})
+	return service
+}
+
 // GetResponseFactory retrieves the response factory
 func (c *DIContainer) GetResponseFactory() interfaces.ResponseFactory {
 	var factory interfaces.ResponseFactory
@@ -238,6 +284,22 @@ func (c *DIContainer) GetResponseFactory() interfaces.ResponseFactory {
 
 // NewContainer creates a new container with DI
 func NewContainer(db *gorm.DB, app *fiber.App) interfaces.Container {
+	containerImpl := newCoreContainer(db, app)
+
+	handler.RegisterHandlers(containerImpl.dig)
+	listeners.RegisterListeners(containerImpl.dig)
+
+	return containerImpl
+}
+
+// NewChannelRegistry creates the channel registry without the handlers and listeners
+// of the server, for commands such as the seeders
+func NewChannelRegistry(db *gorm.DB) interfaces.ChannelRegistry {
+	return newCoreContainer(db, fiber.New()).GetChannelRegistry()
+}
+
+// newCoreContainer creates a container with the services, repositories and channels registered
+func newCoreContainer(db *gorm.DB, app *fiber.App) *DIContainer {
 	digContainer := dig.New()
 
 	// Register DB
@@ -278,8 +340,7 @@ func NewContainer(db *gorm.DB, app *fiber.App) interfaces.Container {
 	disk.RegisterStorageServices(digContainer)
 	i18n.RegisterI18nServices(digContainer)
 	context.RegisterContexts(digContainer)
-	handler.RegisterHandlers(digContainer)
-	listeners.RegisterListeners(digContainer)
+	channels.RegisterChannels(digContainer)
 
 	return containerImpl
 }
@@ -287,12 +348,10 @@ func NewContainer(db *gorm.DB, app *fiber.App) interfaces.Container {
 // StartJobServer initializes and starts the job server
 // This should be called after the container is fully initialized
 func StartJobServer(container interfaces.Container) *jobs.Server {
-	emailService := container.GetEmailService()
 	config := container.GetConfig()
 	jobServer := jobs.RegisterJobServer(
 		config.GetConfig().RedisAddr,
-		emailService,
-		container.GetLogger(),
+		container,
 	)
 	return jobServer
 }
@@ -23,4 +23,18 @@ func RegisterStorageServices(container *dig.Container) {
 	if err := container.Provide(func() storage.Manager { return diskManager }); err != nil {
 		log.Fatalf("Failed to provide disk manager: %v", err)
 	}
+
+	// Private files are kept outside of the publicly served uploads directory
+	privateDiskManager := NewDiskManager()
+	err = privateDiskManager.CreateStorage(storage.Config{
+		Type:     storage.LocalType,
+		BasePath: "./private",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := container.Provide(func() storage.PrivateManager { return privateDiskManager }); err != nil {
+		log.Fatalf("Failed to provide private disk manager: %v", err)
+	}
 }
@@ -0,0 +1,39 @@
+package email
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+var (
+	// htmlPolicy allows the formatting commonly found in emails while removing scripts,
+	// styles and event handlers
+	htmlPolicy = bluemonday.UGCPolicy()
+
+	// textPolicy strips every tag, leaving only the text content
+	textPolicy = bluemonday.StrictPolicy()
+
+	lineBreakPattern  = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6])>`)
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// SanitizeHTML removes any unsafe markup from an HTML email body
+func SanitizeHTML(body string) string {
+	return htmlPolicy.Sanitize(body)
+}
+
+// HTMLToText converts an HTML email body to plain text
+func HTMLToText(body string) string {
+	text := lineBreakPattern.ReplaceAllString(body, "\n")
+	text = html.UnescapeString(textPolicy.Sanitize(text))
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
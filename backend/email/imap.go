@@ -0,0 +1,263 @@
+package email
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"live-chat-server/interfaces"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+	"strings"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/emersion/go-message"
+	_ "github.com/emersion/go-message/charset"
+	"github.com/emersion/go-message/mail"
+)
+
+// IMAPConfig holds the connection settings of an email inbox mailbox
+type IMAPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// IMAPReceiver fetches unread emails from an IMAP mailbox
+type IMAPReceiver struct {
+	config IMAPConfig
+	logger interfaces.Logger
+}
+
+// NewIMAPReceiver creates a new IMAP receiver
+func NewIMAPReceiver(config IMAPConfig, logger interfaces.Logger) *IMAPReceiver {
+	return &IMAPReceiver{
+		config: config,
+		logger: logger,
+	}
+}
+
+// FetchUnseen fetches every unread email in the INBOX folder and passes it to the handler.
+// Emails are only flagged as seen once the handler returns without an error, so failed
+// emails are retried on the next poll.
+func (r *IMAPReceiver) FetchUnseen(handler func(email *types.InboundEmail) error) error {
+	c, err := r.connect()
+	if err != nil {
+		return err
+	}
+	defer c.Logout()
+
+	if _, err := c.Select(imap.InboxName, false); err != nil {
+		return fmt.Errorf("failed to select inbox: %w", err)
+	}
+
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{imap.SeenFlag}
+
+	uids, err := c.UidSearch(criteria)
+	if err != nil {
+		return fmt.Errorf("failed to search mailbox: %w", err)
+	}
+
+	if len(uids) == 0 {
+		return nil
+	}
+
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uids...)
+
+	// Peek so the server doesn't flag the emails as seen before they are handled
+	section := &imap.BodySectionName{Peek: true}
+	items := []imap.FetchItem{section.FetchItem(), imap.FetchUid}
+
+	messages := make(chan *imap.Message, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.UidFetch(seqSet, items, messages)
+	}()
+
+	// Read the bodies before issuing any other command on the connection
+	rawEmails := make(map[uint32][]byte)
+	for msg := range messages {
+		body := msg.GetBody(section)
+		if body == nil {
+			continue
+		}
+
+		raw, err := io.ReadAll(body)
+		if err != nil {
+			r.logger.Error("Failed to read body of email %d: %v", msg.Uid, err)
+			continue
+		}
+		rawEmails[msg.Uid] = raw
+	}
+
+	if err := <-done; err != nil {
+		return fmt.Errorf("failed to fetch emails: %w", err)
+	}
+
+	handled := new(imap.SeqSet)
+	for _, uid := range uids {
+		raw, ok := rawEmails[uid]
+		if !ok {
+			continue
+		}
+
+		email, err := ParseEmail(bytes.NewReader(raw))
+		if err != nil {
+			r.logger.Error("Failed to parse email %d: %v", uid, err)
+			continue
+		}
+		email.UID = uid
+
+		if err := handler(email); err != nil {
+			r.logger.Error("Failed to handle email %d: %v", uid, err)
+			continue
+		}
+
+		handled.AddNum(uid)
+	}
+
+	if handled.Empty() {
+		return nil
+	}
+
+	flags := []interface{}{imap.SeenFlag}
+	if err := c.UidStore(handled, imap.FormatFlagsOp(imap.AddFlags, true), flags, nil); err != nil {
+		return fmt.Errorf("failed to flag emails as seen: %w", err)
+	}
+
+	return nil
+}
+
+// connect dials the IMAP server and logs in, using implicit TLS on port 993
+// and STARTTLS elsewhere when the server supports it
+func (r *IMAPReceiver) connect() (*client.Client, error) {
+	addr := fmt.Sprintf("%s:%d", r.config.Host, r.config.Port)
+	tlsConfig := &tls.Config{ServerName: r.config.Host}
+
+	var c *client.Client
+	var err error
+	if r.config.Port == 993 {
+		c, err = client.DialTLS(addr, tlsConfig)
+	} else {
+		c, err = client.Dial(addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to IMAP server %s: %w", addr, err)
+	}
+
+	if r.config.Port != 993 {
+		if ok, _ := c.SupportStartTLS(); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				c.Logout()
+				return nil, fmt.Errorf("failed to start TLS: %w", err)
+			}
+		}
+	}
+
+	if err := c.Login(r.config.Username, r.config.Password); err != nil {
+		c.Logout()
+		return nil, fmt.Errorf("failed to login to IMAP server: %w", err)
+	}
+
+	return c, nil
+}
+
+// ParseEmail parses a raw RFC 5322 message into an InboundEmail
+func ParseEmail(raw io.Reader) (*types.InboundEmail, error) {
+	mr, err := mail.CreateReader(raw)
+	if err != nil && !message.IsUnknownCharset(err) {
+		return nil, err
+	}
+
+	email := &types.InboundEmail{}
+
+	header := mr.Header
+	email.Subject, _ = header.Subject()
+	email.MessageID, _ = header.MessageID()
+	email.Date, _ = header.Date()
+
+	if inReplyTo, err := header.MsgIDList("In-Reply-To"); err == nil && len(inReplyTo) > 0 {
+		email.InReplyTo = inReplyTo[0]
+	}
+
+	if references, err := header.MsgIDList("References"); err == nil {
+		email.References = references
+	}
+
+	if from, err := header.AddressList("From"); err == nil && len(from) > 0 {
+		email.FromName = from[0].Name
+		email.FromEmail = strings.ToLower(from[0].Address)
+	}
+
+	if to, err := header.AddressList("To"); err == nil {
+		for _, address := range to {
+			email.To = append(email.To, strings.ToLower(address.Address))
+		}
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !message.IsUnknownCharset(err) {
+			return nil, err
+		}
+		if part == nil {
+			break
+		}
+
+		body, err := io.ReadAll(part.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		switch h := part.Header.(type) {
+		case *mail.InlineHeader:
+			contentType, _, _ := h.ContentType()
+			switch {
+			case contentType == "text/plain" && email.TextBody == "":
+				email.TextBody = string(body)
+			case contentType == "text/html" && email.HTMLBody == "":
+				email.HTMLBody = string(body)
+			case !strings.HasPrefix(contentType, "text/") && !strings.HasPrefix(contentType, "multipart/"):
+				// Inline parts which aren't a body are embedded files, usually images
+				email.Attachments = append(email.Attachments, types.InboundAttachment{
+					Filename:    attachmentFilename(h.Header, contentType),
+					ContentType: contentType,
+					ContentID:   strings.Trim(h.Get("Content-Id"), "<>"),
+					Inline:      true,
+					Data:        body,
+				})
+			}
+		case *mail.AttachmentHeader:
+			contentType, _, _ := h.ContentType()
+			email.Attachments = append(email.Attachments, types.InboundAttachment{
+				Filename:    attachmentFilename(h.Header, contentType),
+				ContentType: contentType,
+				ContentID:   strings.Trim(h.Get("Content-Id"), "<>"),
+				Data:        body,
+			})
+		}
+	}
+
+	return email, nil
+}
+
+// attachmentFilename returns the filename of an email part, falling back to a
+// name derived from its content type
+func attachmentFilename(header message.Header, contentType string) string {
+	if _, params, err := header.ContentDisposition(); err == nil && params["filename"] != "" {
+		return params["filename"]
+	}
+
+	if _, params, err := header.ContentType(); err == nil && params["name"] != "" {
+		return params["name"]
+	}
+
+	return "attachment" + utils.ExtensionForContentType(contentType)
+}
@@ -0,0 +1,184 @@
+package email
+
+import (
+	"errors"
+	"live-chat-server/interfaces"
+	"live-chat-server/types"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend/memory"
+	"github.com/emersion/go-imap/server"
+)
+
+const multipartEmail = "From: \"Jane Doe\" <Jane@Example.com>\r\n" +
+	"To: support@example.com\r\n" +
+	"Subject: Broken order\r\n" +
+	"Message-ID: <reply-2@example.com>\r\n" +
+	"In-Reply-To: <reply-1@example.com>\r\n" +
+	"References: <original@example.com> <reply-1@example.com>\r\n" +
+	"Date: Mon, 02 Jan 2006 15:04:05 +0000\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"mixed\"\r\n" +
+	"\r\n" +
+	"--mixed\r\n" +
+	"Content-Type: multipart/related; boundary=\"related\"\r\n" +
+	"\r\n" +
+	"--related\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"alternative\"\r\n" +
+	"\r\n" +
+	"--alternative\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"My order arrived broken.\r\n" +
+	"--alternative\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>My order arrived broken.</p><img src=\"cid:photo@example.com\">\r\n" +
+	"--alternative--\r\n" +
+	"--related\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-Disposition: inline\r\n" +
+	"Content-Id: <photo@example.com>\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"iVBORw0KGgo=\r\n" +
+	"--related--\r\n" +
+	"--mixed\r\n" +
+	"Content-Type: application/pdf\r\n" +
+	"Content-Disposition: attachment; filename=\"invoice.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0=\r\n" +
+	"--mixed--\r\n"
+
+func TestParseEmail(t *testing.T) {
+	email, err := ParseEmail(strings.NewReader(multipartEmail))
+	if err != nil {
+		t.Fatalf("ParseEmail() error = %v", err)
+	}
+
+	if email.FromName != "Jane Doe" || email.FromEmail != "jane@example.com" {
+		t.Errorf("sender = %q <%s>, want \"Jane Doe\" <jane@example.com>", email.FromName, email.FromEmail)
+	}
+	if len(email.To) != 1 || email.To[0] != "support@example.com" {
+		t.Errorf("To = %v, want [support@example.com]", email.To)
+	}
+	if email.Subject != "Broken order" {
+		t.Errorf("Subject = %q, want %q", email.Subject, "Broken order")
+	}
+	if email.MessageID != "reply-2@example.com" || email.InReplyTo != "reply-1@example.com" {
+		t.Errorf("MessageID = %q, InReplyTo = %q", email.MessageID, email.InReplyTo)
+	}
+	if len(email.References) != 2 || email.References[0] != "original@example.com" {
+		t.Errorf("References = %v", email.References)
+	}
+	if strings.TrimSpace(email.TextBody) != "My order arrived broken." {
+		t.Errorf("TextBody = %q", email.TextBody)
+	}
+	if !strings.Contains(email.HTMLBody, "cid:photo@example.com") {
+		t.Errorf("HTMLBody = %q", email.HTMLBody)
+	}
+
+	if len(email.Attachments) != 2 {
+		t.Fatalf("len(Attachments) = %d, want 2", len(email.Attachments))
+	}
+
+	image := email.Attachments[0]
+	if !image.Inline || image.ContentID != "photo@example.com" || image.ContentType != "image/png" {
+		t.Errorf("inline image = %+v", image)
+	}
+	if image.Filename != "attachment.png" {
+		t.Errorf("inline image Filename = %q, want attachment.png", image.Filename)
+	}
+
+	file := email.Attachments[1]
+	if file.Inline || file.Filename != "invoice.pdf" || file.ContentType != "application/pdf" {
+		t.Errorf("attachment = %+v", file)
+	}
+	if string(file.Data) != "%PDF-" {
+		t.Errorf("attachment Data = %q, want %q", file.Data, "%PDF-")
+	}
+}
+
+func TestFetchUnseenFlagsHandledEmailsOnly(t *testing.T) {
+	be := memory.New()
+	user, err := be.Login(nil, "username", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mailbox, err := user.GetMailbox(imap.InboxName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"handled@example.com", "failed@example.com"} {
+		body := "From: jane@example.com\r\n" +
+			"To: support@example.com\r\n" +
+			"Subject: Hello\r\n" +
+			"Message-ID: <" + id + ">\r\n" +
+			"\r\n" +
+			"Hello\r\n"
+		if err := mailbox.CreateMessage(nil, time.Now(), strings.NewReader(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := server.New(be)
+	s.AllowInsecureAuth = true
+	go s.Serve(listener)
+	defer s.Close()
+
+	receiver := NewIMAPReceiver(IMAPConfig{
+		Host:     "127.0.0.1",
+		Port:     listener.Addr().(*net.TCPAddr).Port,
+		Username: "username",
+		Password: "password",
+	}, nopLogger{})
+
+	var received []string
+	err = receiver.FetchUnseen(func(email *types.InboundEmail) error {
+		received = append(received, email.MessageID)
+		if email.MessageID == "failed@example.com" {
+			return errors.New("storage unavailable")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FetchUnseen() error = %v", err)
+	}
+	if len(received) != 2 {
+		t.Fatalf("first poll received %v, want both unseen emails", received)
+	}
+
+	received = nil
+	err = receiver.FetchUnseen(func(email *types.InboundEmail) error {
+		received = append(received, email.MessageID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FetchUnseen() error = %v", err)
+	}
+	if len(received) != 1 || received[0] != "failed@example.com" {
+		t.Fatalf("second poll received %v, want only the failed email", received)
+	}
+}
+
+// nopLogger discards every log entry
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{})                  {}
+func (nopLogger) Info(msg string, args ...interface{})                   {}
+func (nopLogger) Warn(msg string, args ...interface{})                   {}
+func (nopLogger) Error(msg string, args ...interface{})                  {}
+func (nopLogger) Fatal(msg string, args ...interface{})                  {}
+func (l nopLogger) With(fields map[string]interface{}) interfaces.Logger { return l }
+func (l nopLogger) Named(name string) interfaces.Logger                  { return l }
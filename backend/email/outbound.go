@@ -0,0 +1,91 @@
+package email
+
+import (
+	"fmt"
+	"live-chat-server/interfaces"
+	"strings"
+
+	"github.com/go-gomail/gomail"
+	"github.com/google/uuid"
+)
+
+// OutboundEmail represents a reply sent from an email inbox to a contact
+type OutboundEmail struct {
+	FromName   string
+	To         string
+	Subject    string
+	TextBody   string
+	MessageID  string
+	InReplyTo  string
+	References []string
+}
+
+// InboxSMTPSender sends emails through the SMTP server of an email inbox
+// rather than the platform wide sender used for system emails
+type InboxSMTPSender struct {
+	config interfaces.EmailConfig
+	logger interfaces.Logger
+}
+
+// NewInboxSMTPSender creates a new inbox SMTP sender
+func NewInboxSMTPSender(config interfaces.EmailConfig, logger interfaces.Logger) *InboxSMTPSender {
+	return &InboxSMTPSender{
+		config: config,
+		logger: logger,
+	}
+}
+
+// Send sends the email, threading it with the In-Reply-To and References headers
+func (s *InboxSMTPSender) Send(email *OutboundEmail) error {
+	msg := gomail.NewMessage()
+	msg.SetAddressHeader("From", s.config.From, email.FromName)
+	msg.SetHeader("To", email.To)
+	msg.SetHeader("Subject", email.Subject)
+	msg.SetHeader("Message-ID", formatMessageID(email.MessageID))
+
+	if email.InReplyTo != "" {
+		msg.SetHeader("In-Reply-To", formatMessageID(email.InReplyTo))
+	}
+
+	if len(email.References) > 0 {
+		references := make([]string, len(email.References))
+		for i, reference := range email.References {
+			references[i] = formatMessageID(reference)
+		}
+		msg.SetHeader("References", strings.Join(references, " "))
+	}
+
+	msg.SetBody("text/plain", email.TextBody)
+
+	dialer := gomail.NewDialer(s.config.Host, s.config.Port, s.config.Username, s.config.Password)
+	if err := dialer.DialAndSend(msg); err != nil {
+		s.logger.Error("Failed to send email to %s via %s:%d: %v", email.To, s.config.Host, s.config.Port, err)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// GenerateMessageID creates a new unique Message-ID (without angle brackets) for the given address
+func GenerateMessageID(address string) string {
+	domain := address
+	if at := strings.LastIndex(address, "@"); at != -1 {
+		domain = address[at+1:]
+	}
+
+	return fmt.Sprintf("%s@%s", uuid.New().String(), domain)
+}
+
+// ReplySubject prefixes the subject with "Re:" unless it already is a reply
+func ReplySubject(subject string) string {
+	if strings.HasPrefix(strings.ToLower(subject), "re:") {
+		return subject
+	}
+
+	return "Re: " + subject
+}
+
+// formatMessageID wraps a Message-ID in angle brackets
+func formatMessageID(id string) string {
+	return "<" + strings.Trim(id, "<>") + ">"
+}
@@ -5,6 +5,7 @@ import (
 	"live-chat-server/interfaces"
 	"live-chat-server/models"
 	"live-chat-server/types"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -39,8 +40,10 @@ func (f *CommandFactoryImpl) NewHandlePreChatFormCommand(client *types.WebSocket
 		f.container.GetConversationRepo(),
 		f.container.GetContactRepo(),
 		f.container.GetInboxRepo(),
+		f.container.GetChannelRegistry(),
 		f.container.GetLogger(),
 		f.container.GetDispatcher(),
+		f,
 	)
 }
 
@@ -74,9 +77,157 @@ func (f *CommandFactoryImpl) NewHandleMessageNotificationCommand(conversation *m
 		conversation,
 		message,
 		f.container.GetConversationRepo(),
+		f.container.GetConversationReadRepo(),
+		f.container.GetConversationParticipantRepo(),
 		f.container.GetUserRepo(),
 		f.container.GetNotificationService(),
 		f.container.GetPubSubService(),
 		f.container.GetLogger(),
 	)
 }
+
+func (f *CommandFactoryImpl) NewHandleInboundEmailCommand(inbox *models.Inbox, email *types.InboundEmail) interfaces.Command {
+	return commands.NewHandleInboundEmailCommand(
+		inbox,
+		email,
+		f.container.GetConversationRepo(),
+		f.container.GetContactRepo(),
+		f.container.GetUploadService(),
+		f,
+		f.container.GetDispatcher(),
+		f.container.GetLogger(),
+	)
+}
+
+func (f *CommandFactoryImpl) NewHandleInboundSMSCommand(inbox *models.Inbox, message *types.InboundSMS) interfaces.Command {
+	return commands.NewHandleInboundSMSCommand(
+		inbox,
+		message,
+		f.container.GetConversationRepo(),
+		f.container.GetContactRepo(),
+		f,
+		f.container.GetDispatcher(),
+		f.container.GetLogger(),
+	)
+}
+
+func (f *CommandFactoryImpl) NewHandleInboundWhatsAppCommand(inbox *models.Inbox, message *types.InboundWhatsAppMessage) interfaces.Command {
+	return commands.NewHandleInboundWhatsAppCommand(
+		inbox,
+		message,
+		f.container.GetConversationRepo(),
+		f.container.GetContactRepo(),
+		f.container.GetUploadService(),
+		f,
+		f.container.GetDispatcher(),
+		f.container.GetLogger(),
+	)
+}
+
+func (f *CommandFactoryImpl) NewTransitionConversationCommand(conversation *models.Conversation, transition models.ConversationTransition, actorID *string) interfaces.Command {
+	return commands.NewTransitionConversationCommand(
+		conversation,
+		transition,
+		actorID,
+		f.container.GetConversationRepo(),
+		f.container.GetConversationHandler(),
+		f.container.GetDispatcher(),
+		f.container.GetAuditService(),
+		f.container.GetLogger(),
+	)
+}
+
+func (f *CommandFactoryImpl) NewSnoozeConversationCommand(conversation *models.Conversation, until time.Time, actorID *string) interfaces.Command {
+	return commands.NewSnoozeConversationCommand(
+		conversation,
+		until,
+		actorID,
+		f.container.GetConversationRepo(),
+		f.container.GetConversationHandler(),
+		f.container.GetDispatcher(),
+		f.container.GetJobClient(),
+		f.container.GetAuditService(),
+		f.container.GetLogger(),
+	)
+}
+
+func (f *CommandFactoryImpl) NewSetConversationPriorityCommand(conversation *models.Conversation, priority models.ConversationPriority, actorID *string) interfaces.Command {
+	return commands.NewSetConversationPriorityCommand(
+		conversation,
+		priority,
+		actorID,
+		f.container.GetConversationRepo(),
+		f.container.GetDispatcher(),
+		f.container.GetAuditService(),
+		f.container.GetLogger(),
+	)
+}
+
+func (f *CommandFactoryImpl) NewTransferConversationCommand(conversation *models.Conversation, inbox *models.Inbox, assigneeID *string, autoAssign bool, actorID *string) interfaces.Command {
+	return commands.NewTransferConversationCommand(
+		conversation,
+		inbox,
+		assigneeID,
+		autoAssign,
+		actorID,
+		f.container.GetConversationRepo(),
+		f.container.GetInboxRepo(),
+		f.container.GetConversationHandler(),
+		f.container.GetDispatcher(),
+		f.container.GetAuditService(),
+		f.container.GetLogger(),
+	)
+}
+
+func (f *CommandFactoryImpl) NewEditMessageCommand(message *models.Message, content string, editorType models.SenderType, editorID string) interfaces.Command {
+	return commands.NewEditMessageCommand(
+		message,
+		content,
+		editorType,
+		editorID,
+		f.container.GetConversationRepo(),
+		f.container.GetCompanyRepo(),
+		f.container.GetDispatcher(),
+		f.container.GetAuditService(),
+		f.container.GetLogger(),
+	)
+}
+
+func (f *CommandFactoryImpl) NewDeleteMessageCommand(message *models.Message, editorType models.SenderType, editorID string) interfaces.Command {
+	return commands.NewDeleteMessageCommand(
+		message,
+		editorType,
+		editorID,
+		f.container.GetConversationRepo(),
+		f.container.GetCompanyRepo(),
+		f.container.GetDispatcher(),
+		f.container.GetAuditService(),
+		f.container.GetLogger(),
+	)
+}
+
+func (f *CommandFactoryImpl) NewRecordReceiptCommand(conversation *models.Conversation, receipt models.ReceiptType, readerType models.SenderType, readerID string, messageID string) interfaces.Command {
+	return commands.NewRecordReceiptCommand(
+		conversation,
+		receipt,
+		readerType,
+		readerID,
+		messageID,
+		f.container.GetConversationRepo(),
+		f.container.GetConversationReadRepo(),
+		f.container.GetDispatcher(),
+	)
+}
+
+func (f *CommandFactoryImpl) NewSendConversationTranscriptCommand(conversationID string, trigger models.TranscriptTrigger, timezone string) interfaces.Command {
+	return commands.NewSendConversationTranscriptCommand(
+		conversationID,
+		trigger,
+		timezone,
+		f.container.GetConversationRepo(),
+		f.container.GetChannelRegistry(),
+		f.container.GetEmailService(),
+		f.container.GetAuditService(),
+		f.container.GetLogger(),
+	)
+}
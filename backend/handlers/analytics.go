@@ -115,6 +115,23 @@ func (h *AnalyticsHandler) HandleGetStatusStats(c *fiber.Ctx) error {
 	return h.responseFactory.SuccessResponse(c, fiber.StatusOK, "Status statistics fetched successfully", stats)
 }
 
+// HandleGetSLAStats gets SLA breach statistics
+func (h *AnalyticsHandler) HandleGetSLAStats(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	startDate, endDate, err := parseDateRange(c)
+	if err != nil {
+		return h.responseFactory.ErrorResponse(c, fiber.StatusBadRequest, "Failed to parse date range", err)
+	}
+
+	stats, err := h.analyticsService.GetSLAStats(*user.User.CompanyID, startDate, endDate)
+	if err != nil {
+		return h.responseFactory.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch SLA statistics", err)
+	}
+
+	return h.responseFactory.SuccessResponse(c, fiber.StatusOK, "SLA statistics fetched successfully", stats)
+}
+
 // parseDateRange parses start_date and end_date from query parameters
 // Returns default range of last 7 days if not provided
 func parseDateRange(c *fiber.Ctx) (time.Time, time.Time, error) {
@@ -24,6 +24,8 @@ type CompanyInput struct {
 	Phone   string `json:"phone" validate:"omitempty,min=5,max=50"`
 	Address string `json:"address" validate:"omitempty,min=5,max=500"`
 	Logo    string `json:"logo"`
+	// MessageEditWindowMinutes is left unchanged when omitted
+	MessageEditWindowMinutes *int `json:"message_edit_window_minutes" validate:"omitempty,min=0,max=10080"`
 }
 
 type SendInviteInput struct {
@@ -109,6 +111,9 @@ func (h *CompanyHandler) UpdateCompany(c *fiber.Ctx) error {
 	company.Website = input.Website
 	company.Phone = input.Phone
 	company.Address = input.Address
+	if input.MessageEditWindowMinutes != nil {
+		company.MessageEditWindowMinutes = *input.MessageEditWindowMinutes
+	}
 
 	if err := h.repo.UpdateCompany(company); err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_company"), err)
@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"live-chat-server/interfaces"
 	"live-chat-server/listeners"
 	"live-chat-server/models"
 	"live-chat-server/repositories"
 	"live-chat-server/types"
 	"live-chat-server/utils"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -22,20 +24,26 @@ type ContactNoteInput struct {
 	Content string `json:"content" validate:"required"`
 }
 
+type ContactMergeInput struct {
+	SecondaryContactID string `json:"secondary_contact_id" validate:"required,uuid"`
+}
+
 type ContactHandler struct {
 	repo             repositories.ContactRepository
 	conversationRepo repositories.ConversationRepository
+	mergeRepo        repositories.ContactMergeRepository
 	securityContext  interfaces.SecurityContext
 	dispatcher       interfaces.Dispatcher
 	logger           interfaces.Logger
 	langContext      interfaces.LanguageContext
 }
 
-func NewContactHandler(repo repositories.ContactRepository, conversationRepo repositories.ConversationRepository, securityContext interfaces.SecurityContext, dispatcher interfaces.Dispatcher, logger interfaces.Logger, langContext interfaces.LanguageContext) *ContactHandler {
+func NewContactHandler(repo repositories.ContactRepository, conversationRepo repositories.ConversationRepository, mergeRepo repositories.ContactMergeRepository, securityContext interfaces.SecurityContext, dispatcher interfaces.Dispatcher, logger interfaces.Logger, langContext interfaces.LanguageContext) *ContactHandler {
 	handlerLogger := logger.Named("contact_handler")
 	return &ContactHandler{
 		repo:             repo,
 		conversationRepo: conversationRepo,
+		mergeRepo:        mergeRepo,
 		securityContext:  securityContext,
 		dispatcher:       dispatcher,
 		logger:           handlerLogger,
@@ -228,3 +236,131 @@ func (h *ContactHandler) HandleGetContactConversations(c *fiber.Ctx) error {
 
 	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "conversations_fetched"), responses)
 }
+
+// HandleGetContactDuplicates suggests other contacts that share the contact's email or phone number
+func (h *ContactHandler) HandleGetContactDuplicates(c *fiber.Ctx) error {
+	contactID := c.Params("id")
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	contact, err := h.repo.GetContactByIDAndCompanyID(contactID, *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "contact_not_found"), err)
+	}
+
+	duplicates, err := h.mergeRepo.GetDuplicateContacts(contact)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_fetch_contact_duplicates"), err)
+	}
+
+	responses := make([]types.ContactDuplicatePayload, len(duplicates))
+	for i, duplicate := range duplicates {
+		responses[i] = types.ContactDuplicatePayload{
+			Contact:   duplicate.ToResponse(),
+			MatchedOn: models.DuplicateMatches(contact, &duplicate),
+		}
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "contact_duplicates_fetched"), responses)
+}
+
+// HandleMergeContact merges the secondary contact in the request into the contact in the path
+func (h *ContactHandler) HandleMergeContact(c *fiber.Ctx) error {
+	contactID := c.Params("id")
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	var input ContactMergeInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	if input.SecondaryContactID == contactID {
+		return utils.ErrorResponse(c, fiber.StatusUnprocessableEntity, h.langContext.T(c, "contact_merge_same_contact"), models.ErrContactMergeSameContact)
+	}
+
+	primary, err := h.repo.GetContactByIDAndCompanyID(contactID, *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "contact_not_found"), err)
+	}
+
+	secondary, err := h.repo.GetContactByIDAndCompanyID(input.SecondaryContactID, *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "contact_not_found"), err)
+	}
+
+	merge := &models.ContactMerge{
+		CompanyID:          *user.User.CompanyID,
+		PrimaryContactID:   primary.ID,
+		SecondaryContactID: secondary.ID,
+		UserID:             user.User.ID,
+		UndoableUntil:      time.Now().Add(models.ContactMergeUndoWindow),
+	}
+
+	if err := h.mergeRepo.MergeContacts(merge, primary, secondary); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_merge_contacts"), err)
+	}
+
+	merge.PrimaryContact = primary
+	merge.SecondaryContact = secondary
+
+	h.dispatcher.Dispatch(interfaces.EventTypeContactMerged, &listeners.ContactMergedPayload{
+		Merge: merge,
+		User:  user.User,
+	})
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "contacts_merged"), merge.ToResponse())
+}
+
+// HandleListContactMerges lists the merges the contact took part in
+func (h *ContactHandler) HandleListContactMerges(c *fiber.Ctx) error {
+	contactID := c.Params("id")
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	merges, err := h.mergeRepo.GetContactMergesByContactID(contactID, *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_fetch_contact_merges"), err)
+	}
+
+	responses := make([]types.ContactMergePayload, len(merges))
+	for i, merge := range merges {
+		responses[i] = merge.ToResponse()
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "contact_merges_fetched"), responses)
+}
+
+// HandleUndoContactMerge restores the secondary contact of a merge within the undo window
+func (h *ContactHandler) HandleUndoContactMerge(c *fiber.Ctx) error {
+	mergeID := c.Params("mergeId")
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	merge, err := h.mergeRepo.GetContactMergeByIDAndCompanyID(mergeID, *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "contact_merge_not_found"), err)
+	}
+
+	if err := merge.CanUndo(time.Now()); err != nil {
+		if errors.Is(err, models.ErrContactMergeAlreadyUndone) {
+			return utils.ErrorResponse(c, fiber.StatusConflict, h.langContext.T(c, "contact_merge_already_undone"), err)
+		}
+		return utils.ErrorResponse(c, fiber.StatusConflict, h.langContext.T(c, "contact_merge_not_undoable"), err)
+	}
+
+	if merge.PrimaryContact.DeletedAt.Valid {
+		return utils.ErrorResponse(c, fiber.StatusConflict, h.langContext.T(c, "contact_merge_not_undoable"), errors.New("primary contact has been deleted"))
+	}
+
+	if err := h.mergeRepo.UndoContactMerge(merge); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_undo_contact_merge"), err)
+	}
+
+	h.dispatcher.Dispatch(interfaces.EventTypeContactMergeUndone, &listeners.ContactMergeUndonePayload{
+		Merge: merge,
+		User:  user.User,
+	})
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "contact_merge_undone"), merge.ToResponse())
+}
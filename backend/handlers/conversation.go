@@ -1,17 +1,54 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"live-chat-server/interfaces"
+	"live-chat-server/jobs"
 	"live-chat-server/listeners"
 	"live-chat-server/models"
 	"live-chat-server/repositories"
+	"live-chat-server/storage"
+	"live-chat-server/transcript"
 	"live-chat-server/types"
 	"live-chat-server/utils"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+type ConversationListResponse struct {
+	Conversations []types.ConversationPayload `json:"conversations"`
+	// Counts holds how many conversations match the other filters for each status
+	Counts     map[models.ConversationStatus]int64 `json:"counts"`
+	NextCursor string                              `json:"next_cursor"`
+	Limit      int                                 `json:"limit"`
+}
+
+type ParticipantInput struct {
+	// UserID defaults to the authenticated agent, so agents follow a conversation by adding themselves
+	UserID string `json:"user_id" validate:"omitempty,uuid"`
+	Role   string `json:"role" validate:"omitempty,oneof=follower collaborator"`
+}
+
+type TranscriptInput struct {
+	Format string `json:"format" validate:"required,oneof=html text pdf"`
+	// Timezone is the IANA name of the requester's timezone the message times are shown in, UTC by default
+	Timezone       string `json:"timezone"`
+	IncludePrivate bool   `json:"include_private"`
+}
+
+type MentionListResponse struct {
+	Mentions []types.MentionPayload `json:"mentions"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
+	Limit    int                    `json:"limit"`
+}
+
 // ConversationHandler implements interfaces.ConversationHandler
 type ConversationHandler struct {
 	repo            repositories.ConversationRepository
@@ -25,6 +62,10 @@ type ConversationHandler struct {
 	uploadService   interfaces.UploadService
 	pubSub          interfaces.PubSub
 	commandFactory  interfaces.CommandFactory
+	readRepo        repositories.ConversationReadRepository
+	participantRepo repositories.ConversationParticipantRepository
+	jobClient       interfaces.JobClient
+	privateStorage  storage.PrivateManager
 }
 
 func NewConversationHandler(repo repositories.ConversationRepository, contactRepo repositories.ContactRepository,
@@ -32,7 +73,9 @@ func NewConversationHandler(repo repositories.ConversationRepository, contactRep
 	inboxRepo repositories.InboxRepository, logger interfaces.Logger,
 	userRepo repositories.UserRepository, langContext interfaces.LanguageContext,
 	uploadService interfaces.UploadService, pubSub interfaces.PubSub,
-	commandFactory interfaces.CommandFactory,
+	commandFactory interfaces.CommandFactory, readRepo repositories.ConversationReadRepository,
+	participantRepo repositories.ConversationParticipantRepository, jobClient interfaces.JobClient,
+	privateStorage storage.PrivateManager,
 ) *ConversationHandler {
 	handlerLogger := logger.Named("conversation_handler")
 	return &ConversationHandler{
@@ -47,23 +90,153 @@ func NewConversationHandler(repo repositories.ConversationRepository, contactRep
 		uploadService:   uploadService,
 		pubSub:          pubSub,
 		commandFactory:  commandFactory,
+		readRepo:        readRepo,
+		participantRepo: participantRepo,
+		jobClient:       jobClient,
+		privateStorage:  privateStorage,
 	}
 }
 
 func (h *ConversationHandler) HandleListConversations(c *fiber.Ctx) error {
 	user := h.securityContext.GetAuthenticatedUser(c)
 
-	conversations, err := h.repo.GetConversationsForUser(user.User.ID, "Contact", "Inbox", "AssignedTo")
-	if err != nil {
+	definition := models.ConversationFilterDefinition{
+		Status:    c.Query("status"),
+		InboxID:   c.Query("inbox_id"),
+		ContactID: c.Query("contact_id"),
+		Assignee:  c.Query("assignee"),
+		From:      c.Query("from"),
+		To:        c.Query("to"),
+		Sort:      c.Query("sort"),
+	}
+	if labels := c.Query("labels"); labels != "" {
+		definition.LabelIDs = strings.Split(labels, ",")
+	}
+	if err := utils.ValidateStruct(definition); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	filter := repositories.NewConversationFilter(definition, user.User.ID)
+	if err := parseConversationPage(c, &filter); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	response, err := listConversations(h.repo, h.readRepo, user.User.ID, filter)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), nil)
+	} else if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_list_conversations"), err)
 	}
 
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "conversations_listed"), response)
+}
+
+// parseConversationPage sets the cursor and limit of the page of conversations asked for
+func parseConversationPage(c *fiber.Ctx, filter *repositories.ConversationFilter) []utils.ValidationError {
+	page := struct {
+		Cursor string `validate:"omitempty,uuid"`
+		Limit  int    `validate:"gte=1,lte=100"`
+	}{
+		Cursor: c.Query("cursor"),
+		Limit:  c.QueryInt("limit", repositories.DefaultConversationPageLimit),
+	}
+	if err := utils.ValidateStruct(page); err != nil {
+		return err
+	}
+
+	filter.Cursor = page.Cursor
+	filter.Limit = page.Limit
+	return nil
+}
+
+// listConversations lists a page of the agent's conversations matching the filter, with the count of each status.
+// It returns gorm.ErrRecordNotFound when the cursor is not one of the conversations.
+func listConversations(repo repositories.ConversationRepository, readRepo repositories.ConversationReadRepository, userID string, filter repositories.ConversationFilter) (*ConversationListResponse, error) {
+	conversations, nextCursor, err := repo.GetConversationsForUser(userID, filter, "Contact", "Inbox", "AssignedTo", "Labels", "Participants.User")
+	if err != nil {
+		return nil, err
+	}
+
+	statusCounts, err := repo.CountConversationsByStatusForUser(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[models.ConversationStatus]int64{
+		models.ConversationStatusActive:   0,
+		models.ConversationStatusPending:  0,
+		models.ConversationStatusClosed:   0,
+		models.ConversationStatusResolved: 0,
+		models.ConversationStatusSnoozed:  0,
+	}
+	for status, count := range statusCounts {
+		counts[status] = count
+	}
+
+	conversationIDs := make([]string, len(conversations))
+	for i, conversation := range conversations {
+		conversationIDs[i] = conversation.ID
+	}
+
+	unreadCounts, err := readRepo.GetUnreadCounts(conversationIDs, models.SenderTypeAgent, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
 	payload := make([]types.ConversationPayload, 0)
 	for _, conversation := range conversations {
+		unreadCount := unreadCounts[conversation.ID]
+		conversation.UnreadCount = &unreadCount
 		payload = append(payload, *conversation.ToPayloadWithoutMessages())
 	}
 
-	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "conversations_listed"), payload)
+	return &ConversationListResponse{
+		Conversations: payload,
+		Counts:        counts,
+		NextCursor:    nextCursor,
+		Limit:         filter.Limit,
+	}, nil
+}
+
+// HandleListMentions lists the private notes mentioning the authenticated agent, newest first
+func (h *ConversationHandler) HandleListMentions(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	messages, total, err := h.repo.GetMentionsForUser(user.User.ID, *user.User.CompanyID, limit, (page-1)*limit)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_fetch_mentions"), err)
+	}
+
+	reads := make(map[string]*models.ConversationRead)
+	mentions := make([]types.MentionPayload, len(messages))
+	for i, message := range messages {
+		read, ok := reads[message.ConversationID]
+		if !ok {
+			read, _ = h.readRepo.GetConversationRead(message.ConversationID, models.SenderTypeAgent, user.User.ID)
+			reads[message.ConversationID] = read
+		}
+
+		mentions[i] = types.MentionPayload{
+			Message: message.ToPayload(),
+			Read:    read != nil && read.HasRead(&message),
+		}
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "mentions_fetched"), MentionListResponse{
+		Mentions: mentions,
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+	})
 }
 
 func (h *ConversationHandler) HandleGetConversation(c *fiber.Ctx) error {
@@ -74,11 +247,18 @@ func (h *ConversationHandler) HandleGetConversation(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "conversation_id_is_required"), nil)
 	}
 
-	conversation, err := h.repo.GetConversationByIdAndCompanyID(c.Params("id"), *user.User.CompanyID, "Inbox", "Contact", "AssignedTo")
+	conversation, err := h.repo.GetConversationByIdAndCompanyID(c.Params("id"), *user.User.CompanyID, "Inbox", "Contact", "AssignedTo", "Labels", "Reads", "Participants.User")
 	if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_get_conversation"), err)
 	}
 
+	unreadCounts, err := h.readRepo.GetUnreadCounts([]string{conversation.ID}, models.SenderTypeAgent, user.User.ID, true)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_get_conversation"), err)
+	}
+	unreadCount := unreadCounts[conversation.ID]
+	conversation.UnreadCount = &unreadCount
+
 	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "conversation_retrieved"), conversation.ToPayload())
 }
 
@@ -127,12 +307,41 @@ func (h *ConversationHandler) HandleGetConversationMessages(c *fiber.Ctx) error
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "conversation_id_is_required"), nil)
 	}
 
-	conversation, err := h.repo.GetConversationByIdAndCompanyID(id, *authUser.User.CompanyID, "Messages")
+	input := struct {
+		Before string `validate:"omitempty,uuid"`
+		After  string `validate:"omitempty,uuid,excluded_with=Before"`
+		Limit  int    `validate:"min=1,max=200"`
+	}{
+		Before: c.Query("before"),
+		After:  c.Query("after"),
+		Limit:  c.QueryInt("limit", repositories.DefaultMessagePageLimit),
+	}
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	conversation, err := h.repo.GetConversationByIdAndCompanyID(id, *authUser.User.CompanyID)
 	if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_get_conversation"), err)
 	}
 
-	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "conversation_messages_retrieved"), conversation.GetMessages())
+	err = h.repo.LoadMessagesPage(conversation, repositories.MessagePage{
+		Before:         input.Before,
+		After:          input.After,
+		Limit:          input.Limit,
+		IncludePrivate: true,
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "message_not_found"), nil)
+	} else if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_get_conversation"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "conversation_messages_retrieved"), types.MessagePagePayload{
+		Messages: conversation.GetMessages(),
+		Before:   conversation.MessagesBefore,
+		After:    conversation.MessagesAfter,
+	})
 }
 
 // AssignConversation implements interfaces.ConversationHandler
@@ -230,42 +439,484 @@ func (h *ConversationHandler) HandleGetAssignableAgents(c *fiber.Ctx) error {
 	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "agents_retrieved"), payload)
 }
 
-func (h *ConversationHandler) handleCloseConversation(id string) (*models.Conversation, error) {
-	conversation, err := h.repo.GetConversationByID(id, "Contact", "Inbox", "AssignedTo")
+// HandleGetParticipants lists the agents following or collaborating on the conversation
+func (h *ConversationHandler) HandleGetParticipants(c *fiber.Ctx) error {
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+
+	conversation, err := h.repo.GetConversationByIdAndCompanyID(c.Params("id"), *authUser.User.CompanyID)
 	if err != nil {
-		return nil, err
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	participants, err := h.participantRepo.GetParticipants(conversation.ID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_get_participants"), err)
 	}
 
-	if conversation.IsClosed() {
-		return conversation, nil
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "participants_retrieved"), models.ParticipantsToPayload(participants))
+}
+
+// HandleAddParticipant adds a member of the conversation's inbox as a follower or collaborator,
+// or changes their role when they already take part in the conversation
+func (h *ConversationHandler) HandleAddParticipant(c *fiber.Ctx) error {
+	var input ParticipantInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+		}
 	}
 
-	conversation.Status = models.ConversationStatusClosed
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
 
-	if err := h.repo.UpdateConversation(conversation); err != nil {
-		return nil, err
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+	conversation, err := h.repo.GetConversationByIdAndCompanyID(c.Params("id"), *authUser.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	if input.UserID == "" {
+		input.UserID = authUser.User.ID
+	}
+	if input.Role == "" {
+		input.Role = string(models.ParticipantRoleFollower)
+	}
+
+	members, err := h.inboxRepo.GetUsersForInbox(conversation.InboxID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_get_users"), err)
+	}
+
+	isMember := false
+	for _, member := range members {
+		if member.ID == input.UserID {
+			isMember = true
+			break
+		}
+	}
+	if !isMember {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "participant_not_inbox_member"), nil)
+	}
+
+	participant := &models.ConversationParticipant{
+		ConversationID: conversation.ID,
+		UserID:         input.UserID,
+		Role:           models.ParticipantRole(input.Role),
+		AddedByID:      &authUser.User.ID,
+	}
+	if err := h.participantRepo.SaveParticipant(participant); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_participants"), err)
+	}
+
+	return h.participantsUpdated(c, conversation, participant, false, "participant_added")
+}
+
+// HandleRemoveParticipant removes an agent from the conversation's participants.
+// Agents remove themselves, the assignee and admins remove anyone.
+func (h *ConversationHandler) HandleRemoveParticipant(c *fiber.Ctx) error {
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+
+	conversation, err := h.repo.GetConversationByIdAndCompanyID(c.Params("id"), *authUser.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	participant, err := h.participantRepo.GetParticipant(conversation.ID, c.Params("userId"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "participant_not_found"), err)
+	}
+
+	isAssignee := conversation.AssignedToID != nil && *conversation.AssignedToID == authUser.User.ID
+	isAdmin := authUser.User.Role == string(models.RoleAdmin) || authUser.User.Role == string(models.RoleSuperAdmin)
+	if participant.UserID != authUser.User.ID && !isAssignee && !isAdmin {
+		return utils.ErrorResponse(c, fiber.StatusForbidden, h.langContext.T(c, "participant_not_removable"), nil)
+	}
+
+	if err := h.participantRepo.DeleteParticipant(participant); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_participants"), err)
+	}
+
+	return h.participantsUpdated(c, conversation, participant, true, "participant_removed")
+}
+
+// participantsUpdated lets the agents know about the conversation's participants and responds with them
+func (h *ConversationHandler) participantsUpdated(c *fiber.Ctx, conversation *models.Conversation, participant *models.ConversationParticipant, removed bool, successKey string) error {
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+
+	participants, err := h.participantRepo.GetParticipants(conversation.ID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_get_participants"), err)
+	}
+	conversation.Participants = participants
+
+	h.dispatcher.Dispatch(interfaces.EventTypeConversationParticipants, &listeners.ConversationParticipantsPayload{
+		Conversation: conversation,
+		Participant:  participant,
+		User:         authUser.User,
+		Removed:      removed,
+	})
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, successKey), models.ParticipantsToPayload(participants))
+}
+
+// HandleExportTranscript queues the rendering of the conversation's transcript, the agent is notified
+// with a download link once the file is stored
+func (h *ConversationHandler) HandleExportTranscript(c *fiber.Ctx) error {
+	var input TranscriptInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	if input.Timezone == "" {
+		input.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(input.Timezone); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "invalid_timezone"), nil)
+	}
+
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+
+	isAdmin := authUser.User.Role == string(models.RoleAdmin) || authUser.User.Role == string(models.RoleSuperAdmin)
+	if input.IncludePrivate && !isAdmin {
+		return utils.ErrorResponse(c, fiber.StatusForbidden, h.langContext.T(c, "transcript_private_admin_only"), nil)
+	}
+
+	conversation, err := h.repo.GetConversationByIdAndCompanyID(c.Params("id"), *authUser.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	payload := jobs.ExportConversationTranscriptJobPayload{
+		ConversationID: conversation.ID,
+		UserID:         authUser.User.ID,
+		Format:         transcript.Format(input.Format),
+		Timezone:       input.Timezone,
+		IncludePrivate: input.IncludePrivate,
+	}
+	if err := h.jobClient.Enqueue("export_conversation_transcript", payload); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_export_transcript"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusAccepted, h.langContext.T(c, "transcript_export_queued"), nil)
+}
+
+// HandleDownloadTranscript sends an exported transcript of a conversation of the agent's company.
+// Transcripts including private notes are only sent to admins.
+func (h *ConversationHandler) HandleDownloadTranscript(c *fiber.Ctx) error {
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+
+	conversation, err := h.repo.GetConversationByIdAndCompanyID(c.Params("id"), *authUser.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	scope := c.Params("scope")
+	filename := c.Params("filename")
+	if (scope != jobs.TranscriptScopePublic && scope != jobs.TranscriptScopePrivate) || filename != filepath.Base(filename) || strings.HasPrefix(filename, ".") {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "transcript_not_found"), nil)
+	}
+
+	isAdmin := authUser.User.Role == string(models.RoleAdmin) || authUser.User.Role == string(models.RoleSuperAdmin)
+	if scope == jobs.TranscriptScopePrivate && !isAdmin {
+		return utils.ErrorResponse(c, fiber.StatusForbidden, h.langContext.T(c, "transcript_private_admin_only"), nil)
+	}
+
+	file, err := h.privateStorage.Get(jobs.TranscriptPath(conversation.ID, scope, filename))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "transcript_not_found"), nil)
 	}
 
-	h.SendSystemMessage(conversation, "This conversation has been closed.")
+	// The stream is closed once it has been sent
+	c.Attachment(filename)
+	return c.SendStream(file)
+}
 
-	h.dispatcher.Dispatch(interfaces.EventTypeConversationClose, conversation)
+// getConversationForStatusChange loads the conversation the authenticated agent is changing the status of
+func (h *ConversationHandler) getConversationForStatusChange(c *fiber.Ctx) (*models.Conversation, error) {
+	authUser := h.securityContext.GetAuthenticatedUser(c)
 
-	return conversation, nil
+	return h.repo.GetConversationByIdAndCompanyID(c.Params("id"), *authUser.User.CompanyID, "Contact", "Inbox", "AssignedTo", "Labels", "Participants.User")
+}
+
+// statusChangeResponse responds with the conversation once a status command has run
+func (h *ConversationHandler) statusChangeResponse(c *fiber.Ctx, conversation *models.Conversation, err error, successKey string) error {
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidConversationTransition):
+			return utils.ErrorResponse(c, fiber.StatusConflict, h.langContext.T(c, "invalid_status_transition"), nil)
+		case errors.Is(err, models.ErrSnoozeUntilInPast):
+			return utils.ErrorResponse(c, fiber.StatusUnprocessableEntity, h.langContext.T(c, "snooze_until_in_past"), nil)
+		case errors.Is(err, models.ErrInvalidConversationPriority):
+			return utils.ErrorResponse(c, fiber.StatusUnprocessableEntity, h.langContext.T(c, "invalid_conversation_priority"), nil)
+		case errors.Is(err, models.ErrConversationAlreadyInInbox):
+			return utils.ErrorResponse(c, fiber.StatusConflict, h.langContext.T(c, "conversation_already_in_inbox"), nil)
+		case errors.Is(err, models.ErrConversationClosed):
+			return utils.ErrorResponse(c, fiber.StatusConflict, h.langContext.T(c, "conversation_is_closed"), nil)
+		case errors.Is(err, models.ErrAssigneeNotInboxMember):
+			return utils.ErrorResponse(c, fiber.StatusUnprocessableEntity, h.langContext.T(c, "assignee_not_inbox_member"), nil)
+		case errors.Is(err, models.ErrInboxTypeMismatch):
+			return utils.ErrorResponse(c, fiber.StatusUnprocessableEntity, h.langContext.T(c, "inbox_type_mismatch"), nil)
+		}
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_conversation"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, successKey), conversation.ToPayload())
+}
+
+// handleTransitionConversation applies a status transition requested by the authenticated agent
+func (h *ConversationHandler) handleTransitionConversation(c *fiber.Ctx, transition models.ConversationTransition, successKey string) error {
+	conversation, err := h.getConversationForStatusChange(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+	_, err = h.commandFactory.NewTransitionConversationCommand(conversation, transition, &authUser.User.ID).Handle()
+
+	return h.statusChangeResponse(c, conversation, err, successKey)
 }
 
 func (h *ConversationHandler) HandleCloseConversation(c *fiber.Ctx) error {
-	id := c.Params("id")
+	return h.handleTransitionConversation(c, models.ConversationTransitionClose, "conversation_closed")
+}
 
-	if id == "" {
-		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "conversation_id_is_required"), nil)
+func (h *ConversationHandler) HandleResolveConversation(c *fiber.Ctx) error {
+	return h.handleTransitionConversation(c, models.ConversationTransitionResolve, "conversation_resolved")
+}
+
+func (h *ConversationHandler) HandleReopenConversation(c *fiber.Ctx) error {
+	return h.handleTransitionConversation(c, models.ConversationTransitionReopen, "conversation_reopened")
+}
+
+func (h *ConversationHandler) HandlePendingConversation(c *fiber.Ctx) error {
+	return h.handleTransitionConversation(c, models.ConversationTransitionPending, "conversation_marked_pending")
+}
+
+func (h *ConversationHandler) HandleWakeConversation(c *fiber.Ctx) error {
+	return h.handleTransitionConversation(c, models.ConversationTransitionWake, "conversation_woken")
+}
+
+func (h *ConversationHandler) HandleSnoozeConversation(c *fiber.Ctx) error {
+	var input struct {
+		SnoozedUntil time.Time `json:"snoozed_until" validate:"required"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
 	}
 
-	conversation, err := h.handleCloseConversation(id)
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	conversation, err := h.getConversationForStatusChange(c)
 	if err != nil {
-		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_get_conversation"), err)
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+	_, err = h.commandFactory.NewSnoozeConversationCommand(conversation, input.SnoozedUntil, &authUser.User.ID).Handle()
+
+	return h.statusChangeResponse(c, conversation, err, "conversation_snoozed")
+}
+
+func (h *ConversationHandler) HandleSetConversationPriority(c *fiber.Ctx) error {
+	var input struct {
+		Priority string `json:"priority" validate:"required,oneof=low medium high urgent"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	conversation, err := h.getConversationForStatusChange(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+	_, err = h.commandFactory.NewSetConversationPriorityCommand(conversation, models.ConversationPriority(input.Priority), &authUser.User.ID).Handle()
+
+	return h.statusChangeResponse(c, conversation, err, "conversation_priority_updated")
+}
+
+func (h *ConversationHandler) HandleTransferConversation(c *fiber.Ctx) error {
+	var input struct {
+		InboxID      string  `json:"inbox_id" validate:"required,uuid"`
+		AssignedToID *string `json:"assigned_to_id" validate:"omitempty,uuid"`
+		AutoAssign   bool    `json:"auto_assign"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	conversation, err := h.getConversationForStatusChange(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+	inbox, err := h.inboxRepo.GetInboxByIDAndCompanyID(input.InboxID, *authUser.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "inbox_not_found"), err)
+	}
+
+	_, err = h.commandFactory.NewTransferConversationCommand(conversation, inbox, input.AssignedToID, input.AutoAssign, &authUser.User.ID).Handle()
+
+	return h.statusChangeResponse(c, conversation, err, "conversation_transferred")
+}
+
+// HandleMarkConversationRead moves the authenticated agent's read pointer up to a message,
+// or to the latest message of the conversation when none is given
+func (h *ConversationHandler) HandleMarkConversationRead(c *fiber.Ctx) error {
+	var input struct {
+		MessageID string `json:"message_id" validate:"omitempty,uuid"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+		}
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+	conversation, err := h.repo.GetConversationByIdAndCompanyID(c.Params("id"), *authUser.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	read, err := h.commandFactory.NewRecordReceiptCommand(conversation, models.ReceiptTypeRead, models.SenderTypeAgent, authUser.User.ID, input.MessageID).Handle()
+	if err != nil {
+		if errors.Is(err, models.ErrReceiptMessageNotFound) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "message_not_found"), nil)
+		}
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_mark_conversation_read"), err)
+	}
+
+	var receipt *types.ConversationReceiptPayload
+	if read != nil {
+		payload := read.(*models.ConversationRead).ToPayload()
+		receipt = &payload
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "conversation_marked_read"), receipt)
+}
+
+// getMessageForChange loads a message of a conversation of the authenticated agent's company
+func (h *ConversationHandler) getMessageForChange(c *fiber.Ctx) (*models.Message, error) {
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+
+	conversation, err := h.repo.GetConversationByIdAndCompanyID(c.Params("id"), *authUser.User.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := h.repo.GetMessageByID(c.Params("messageId"))
+	if err != nil {
+		return nil, err
+	}
+
+	if message.ConversationID != conversation.ID {
+		return nil, errors.New("message does not belong to the conversation")
+	}
+
+	return message, nil
+}
+
+// messageChangeResponse responds with the message once an edit or delete command has run
+func (h *ConversationHandler) messageChangeResponse(c *fiber.Ctx, message *models.Message, err error, successKey string) error {
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrMessageNotOwnMessage):
+			return utils.ErrorResponse(c, fiber.StatusForbidden, h.langContext.T(c, "message_not_own_message"), nil)
+		case errors.Is(err, models.ErrMessageEditWindowExpired):
+			return utils.ErrorResponse(c, fiber.StatusConflict, h.langContext.T(c, "message_edit_window_expired"), nil)
+		case errors.Is(err, models.ErrMessageDeleted):
+			return utils.ErrorResponse(c, fiber.StatusConflict, h.langContext.T(c, "message_already_deleted"), nil)
+		case errors.Is(err, models.ErrMessageNotEditable):
+			return utils.ErrorResponse(c, fiber.StatusUnprocessableEntity, h.langContext.T(c, "message_not_editable"), nil)
+		case errors.Is(err, models.ErrMessageContentRequired):
+			return utils.ErrorResponse(c, fiber.StatusUnprocessableEntity, h.langContext.T(c, "message_content_required"), nil)
+		case errors.Is(err, models.ErrConversationClosed):
+			return utils.ErrorResponse(c, fiber.StatusConflict, h.langContext.T(c, "conversation_is_closed"), nil)
+		}
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_message"), err)
+	}
+
+	if _, err := h.repo.PopulateSender(message); err != nil {
+		h.logger.Error("Failed to populate sender of message %s: %v", message.ID, err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, successKey), message.ToPayload())
+}
+
+func (h *ConversationHandler) HandleEditMessage(c *fiber.Ctx) error {
+	var input struct {
+		Content string `json:"content" validate:"required"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	message, err := h.getMessageForChange(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "message_not_found"), err)
+	}
+
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+	_, err = h.commandFactory.NewEditMessageCommand(message, input.Content, models.SenderTypeAgent, authUser.User.ID).Handle()
+
+	return h.messageChangeResponse(c, message, err, "message_updated")
+}
+
+func (h *ConversationHandler) HandleDeleteMessage(c *fiber.Ctx) error {
+	message, err := h.getMessageForChange(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "message_not_found"), err)
+	}
+
+	authUser := h.securityContext.GetAuthenticatedUser(c)
+	_, err = h.commandFactory.NewDeleteMessageCommand(message, models.SenderTypeAgent, authUser.User.ID).Handle()
+
+	return h.messageChangeResponse(c, message, err, "message_deleted")
+}
+
+// HandleGetMessageEdits lists the edit history of a message
+func (h *ConversationHandler) HandleGetMessageEdits(c *fiber.Ctx) error {
+	message, err := h.getMessageForChange(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "message_not_found"), err)
+	}
+
+	edits, err := h.repo.GetMessageEdits(message.ID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_fetch_message_edits"), err)
+	}
+
+	responses := make([]types.MessageEditPayload, len(edits))
+	for i, edit := range edits {
+		responses[i] = edit.ToResponse()
 	}
 
-	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "conversation_closed"), conversation.ToPayload())
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "message_edits_fetched"), responses)
 }
 
 func (h *ConversationHandler) HandleSendMessageAttachment(c *fiber.Ctx) error {
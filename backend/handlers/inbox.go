@@ -1,41 +1,41 @@
 package handler
 
 import (
+	"errors"
 	"live-chat-server/interfaces"
 	"live-chat-server/listeners"
 	"live-chat-server/models"
 	"live-chat-server/repositories"
-	"live-chat-server/types"
 	"live-chat-server/utils"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 type InboxInput struct {
-	Name           string   `json:"name" validate:"required,min=3,max=1000"`
-	WelcomeMessage string   `json:"welcome_message" validate:"required,min=3,max=255"`
-	Description    string   `json:"description" validate:"omitempty,min=3,max=1000"`
-	UserIDs        []string `json:"user_ids"`
+	Name        string   `json:"name" validate:"required,min=3,max=1000"`
+	Description string   `json:"description" validate:"omitempty,min=3,max=1000"`
+	UserIDs     []string `json:"user_ids"`
 }
 
 type CreateInboxInput struct {
 	InboxInput
-	Type string `json:"type" validate:"required,oneof=web_chat email"`
+	Type string `json:"type" validate:"required"`
 }
 
 type UpdateInboxInput struct {
 	InboxInput
-	ID                    string                        `json:"id" validate:"required,uuid"`
-	Enabled               bool                          `json:"enabled" validate:"required"`
-	AutoAssignmentEnabled bool                          `json:"auto_assignment_enabled" validate:"omitempty"`
-	MaxAutoAssignments    int                           `json:"max_auto_assignments" validate:"omitempty,min=1,max=100"`
-	AutoResponderEnabled  bool                          `json:"auto_responder_enabled" validate:"omitempty"`
-	AutoResponderMessage  string                        `json:"auto_responder_message" validate:"omitempty"`
-	WorkingHours          map[string]types.WorkingHours `json:"working_hours" validate:"omitempty,working_hours"`
-	OutsideHoursMessage   string                        `json:"outside_hours_message" validate:"omitempty"`
-	WidgetCustomization   types.WidgetCustomization     `json:"widget_customization" validate:"required"`
-	PreChatForm           *types.PreChatForm            `json:"pre_chat_form" validate:"omitempty"`
+	ID                    string `json:"id" validate:"required,uuid"`
+	Enabled               bool   `json:"enabled" validate:"required"`
+	AutoAssignmentEnabled bool   `json:"auto_assignment_enabled" validate:"omitempty"`
+	MaxAutoAssignments    int    `json:"max_auto_assignments" validate:"omitempty,min=1,max=100"`
+	AutoResponderEnabled  bool   `json:"auto_responder_enabled" validate:"omitempty"`
+	AutoResponderMessage  string `json:"auto_responder_message" validate:"omitempty"`
+	// SendTranscriptOnClose emails the contact a transcript of their conversation once it is closed
+	SendTranscriptOnClose bool `json:"send_transcript_on_close" validate:"omitempty"`
+	// SLAPolicyID attaches an SLA policy, an empty string detaches it and nil leaves it unchanged
+	SLAPolicyID *string `json:"sla_policy_id" validate:"omitempty,uuid"`
 }
 
 type UserResponse struct {
@@ -52,9 +52,11 @@ type InboxHandler struct {
 	logger           interfaces.Logger
 	langContext      interfaces.LanguageContext
 	conversationRepo repositories.ConversationRepository
+	channels         interfaces.ChannelRegistry
+	slaPolicyRepo    repositories.SLAPolicyRepository
 }
 
-func NewInboxHandler(repo repositories.InboxRepository, userRepo repositories.UserRepository, securityContext interfaces.SecurityContext, dispatcher interfaces.Dispatcher, logger interfaces.Logger, langContext interfaces.LanguageContext, conversationRepo repositories.ConversationRepository) *InboxHandler {
+func NewInboxHandler(repo repositories.InboxRepository, userRepo repositories.UserRepository, securityContext interfaces.SecurityContext, dispatcher interfaces.Dispatcher, logger interfaces.Logger, langContext interfaces.LanguageContext, conversationRepo repositories.ConversationRepository, channels interfaces.ChannelRegistry, slaPolicyRepo repositories.SLAPolicyRepository) *InboxHandler {
 	handlerLogger := logger.Named("inbox_handler")
 	return &InboxHandler{
 		repo:             repo,
@@ -64,6 +66,8 @@ func NewInboxHandler(repo repositories.InboxRepository, userRepo repositories.Us
 		logger:           handlerLogger,
 		langContext:      langContext,
 		conversationRepo: conversationRepo,
+		channels:         channels,
+		slaPolicyRepo:    slaPolicyRepo,
 	}
 }
 
@@ -76,7 +80,13 @@ func (h *InboxHandler) HandleGetInbox(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "inbox_not_found"), err)
 	}
 
-	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "inbox_found"), inbox.ToResponse())
+	if err := h.channels.LoadConfig(inbox); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "inbox_not_found"), err)
+	}
+
+	isAdmin := user.User.Role == string(models.RoleAdmin) || user.User.Role == string(models.RoleSuperAdmin)
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "inbox_found"), h.channels.InboxPayload(inbox, isAdmin))
 }
 
 func (h *InboxHandler) HandleListInboxes(c *fiber.Ctx) error {
@@ -106,6 +116,16 @@ func (h *InboxHandler) HandleCreateInbox(c *fiber.Ctx) error {
 		return utils.ValidationErrorResponse(c, err)
 	}
 
+	channel, ok := h.channels.Get(models.InboxType(input.Type))
+	if !ok {
+		return utils.ValidationErrorResponse(c, h.inboxTypeValidationErrors())
+	}
+
+	config, err := channel.CreateConfig(c)
+	if err != nil {
+		return h.channelConfigErrorResponse(c, err, "failed_to_create_inbox")
+	}
+
 	user := h.securityContext.GetAuthenticatedUser(c)
 
 	users := []models.User{*user.User}
@@ -115,7 +135,7 @@ func (h *InboxHandler) HandleCreateInbox(c *fiber.Ctx) error {
 		Name:                  input.Name,
 		CompanyID:             *user.User.CompanyID,
 		Description:           input.Description,
-		Type:                  models.InboxTypeWebChat,
+		Type:                  channel.Type(),
 		Enabled:               true,
 		AutoAssignmentEnabled: false,
 		MaxAutoAssignments:    1,
@@ -123,46 +143,13 @@ func (h *InboxHandler) HandleCreateInbox(c *fiber.Ctx) error {
 		Users:                 users,
 	}
 
-	// Create the webchat configuration
-	webchat := models.InboxWebChat{
-		WelcomeMessage: input.WelcomeMessage,
-		WorkingHours: types.WorkingHoursMap{
-			"monday":    types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-			"tuesday":   types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-			"wednesday": types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-			"thursday":  types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-			"friday":    types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-			"saturday":  types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: false},
-			"sunday":    types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: false},
-		},
-		WidgetCustomization: types.WidgetCustomization{
-			PrimaryColor: "#0A2540",
-			Position:     "bottom-right",
-		},
-		PreChatForm: types.PreChatForm{
-			Enabled:     false,
-			Title:       h.langContext.T(c, "pre_form_title"),
-			Description: h.langContext.T(c, "pre_form_description"),
-			Fields: []types.PreChatFormField{
-				{
-					ID:           "field-" + utils.GenerateRandomID(),
-					Type:         "text",
-					Label:        h.langContext.T(c, "pre_form_name_label"),
-					Placeholder:  h.langContext.T(c, "pre_form_name_placeholder"),
-					Required:     true,
-					ContactField: "name",
-				},
-			},
-		},
-	}
-
-	// Create inbox with webchat configuration
-	if err := h.repo.CreateInboxWithWebChat(&inbox, &webchat); err != nil {
+	// Create inbox with the channel configuration
+	if err := h.repo.CreateInboxWithConfig(&inbox, config); err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_create_inbox"), err.Error())
 	}
 
 	// Reload the inbox with its relationships to get the full data
-	reloadedInbox, err := h.repo.GetInboxByID(inbox.ID)
+	reloadedInbox, err := h.reloadInbox(inbox.ID)
 	if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_reload_inbox"), err.Error())
 	}
@@ -172,7 +159,52 @@ func (h *InboxHandler) HandleCreateInbox(c *fiber.Ctx) error {
 		User:  user.User,
 	})
 
-	return utils.SuccessResponse(c, fiber.StatusCreated, h.langContext.T(c, "inbox_created"), reloadedInbox.ToResponse())
+	return utils.SuccessResponse(c, fiber.StatusCreated, h.langContext.T(c, "inbox_created"), h.channels.InboxPayload(reloadedInbox, true))
+}
+
+// reloadInbox fetches the inbox with its users and channel configuration
+func (h *InboxHandler) reloadInbox(inboxID string) (*models.Inbox, error) {
+	inbox, err := h.repo.GetInboxByID(inboxID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.channels.LoadConfig(inbox); err != nil {
+		return nil, err
+	}
+
+	return inbox, nil
+}
+
+// inboxTypeValidationErrors reports an inbox type no channel is registered for
+func (h *InboxHandler) inboxTypeValidationErrors() []utils.ValidationError {
+	inboxTypes := make([]string, 0, len(h.channels.Channels()))
+	for _, channel := range h.channels.Channels() {
+		inboxTypes = append(inboxTypes, string(channel.Type()))
+	}
+
+	return []utils.ValidationError{
+		{
+			Field:   "type",
+			Tag:     "oneof",
+			Param:   strings.Join(inboxTypes, " "),
+			Message: "validation.oneof",
+		},
+	}
+}
+
+// channelConfigErrorResponse responds to an error returned by a channel for the submitted configuration
+func (h *InboxHandler) channelConfigErrorResponse(c *fiber.Ctx, err error, fallbackKey string) error {
+	var configErr *interfaces.ChannelConfigError
+	if !errors.As(err, &configErr) {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, fallbackKey), err.Error())
+	}
+
+	if len(configErr.Errors) > 0 {
+		return utils.ValidationErrorResponse(c, configErr.Errors)
+	}
+
+	return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, configErr.Key), nil)
 }
 
 func (h *InboxHandler) UpdateInboxUsers(tx *gorm.DB, inbox *models.Inbox, newUserIDs []string, companyID string) ([]string, error) {
@@ -230,6 +262,35 @@ func (h *InboxHandler) HandleUpdateInbox(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "inbox_not_found"), err)
 	}
 
+	channel, ok := h.channels.Get(inbox.Type)
+	if !ok {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_inbox"), "unsupported inbox type")
+	}
+
+	if err := channel.LoadConfig(inbox); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_inbox"), err)
+	}
+
+	if input.SLAPolicyID != nil {
+		if *input.SLAPolicyID == "" {
+			inbox.SLAPolicyID = nil
+			inbox.SLAPolicy = nil
+		} else {
+			policy, err := h.slaPolicyRepo.GetSLAPolicyByIDAndCompanyID(*input.SLAPolicyID, *user.User.CompanyID)
+			if err != nil {
+				return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "sla_policy_not_found"), err)
+			}
+			inbox.SLAPolicyID = &policy.ID
+			inbox.SLAPolicy = policy
+		}
+	}
+
+	// Validate and apply the type-specific configuration before saving anything
+	config, err := channel.UpdateConfig(c, inbox)
+	if err != nil {
+		return h.channelConfigErrorResponse(c, err, "failed_to_update_inbox")
+	}
+
 	// Update common inbox fields
 	inbox.Name = input.Name
 	inbox.Description = input.Description
@@ -238,6 +299,7 @@ func (h *InboxHandler) HandleUpdateInbox(c *fiber.Ctx) error {
 	inbox.MaxAutoAssignments = input.MaxAutoAssignments
 	inbox.AutoResponderEnabled = input.AutoResponderEnabled
 	inbox.AutoResponderMessage = input.AutoResponderMessage
+	inbox.SendTranscriptOnClose = input.SendTranscriptOnClose
 
 	if err := models.DB.Transaction(func(tx *gorm.DB) error {
 		// Save main inbox
@@ -246,42 +308,17 @@ func (h *InboxHandler) HandleUpdateInbox(c *fiber.Ctx) error {
 		}
 
 		// Update type-specific configuration
-		switch inbox.Type {
-		case models.InboxTypeWebChat:
-			if inbox.WebChat == nil {
-				// Create WebChat config if it doesn't exist
-				inbox.WebChat = &models.InboxWebChat{
-					InboxID: inbox.ID,
-				}
-			}
-
-			// Update WebChat fields
-			inbox.WebChat.WelcomeMessage = input.WelcomeMessage
-			inbox.WebChat.WorkingHours = input.WorkingHours
-			inbox.WebChat.OutsideHoursMessage = input.OutsideHoursMessage
-			inbox.WebChat.WidgetCustomization = input.WidgetCustomization
-
-			// Update PreChatForm if provided
-			if input.PreChatForm != nil {
-				inbox.WebChat.PreChatForm = *input.PreChatForm
-			}
-
-			return h.repo.UpdateWebChatConfig(inbox.WebChat, tx)
-
-		case models.InboxTypeEmail:
-			// Update Email fields if this is an email inbox
-			if inbox.Email != nil {
-				return h.repo.UpdateEmailConfig(inbox.Email, tx)
-			}
+		if config == nil {
+			return nil
 		}
 
-		return nil
+		return h.repo.UpdateInboxConfig(config, tx)
 	}); err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_inbox"), err)
 	}
 
 	// Reload the inbox with its relationships to get the updated data
-	updatedInbox, err := h.repo.GetInboxByID(inbox.ID)
+	updatedInbox, err := h.reloadInbox(inbox.ID)
 	if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_reload_inbox"), err)
 	}
@@ -291,7 +328,7 @@ func (h *InboxHandler) HandleUpdateInbox(c *fiber.Ctx) error {
 		User:  user.User,
 	})
 
-	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "inbox_updated"), updatedInbox.ToResponse())
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "inbox_updated"), h.channels.InboxPayload(updatedInbox, true))
 }
 
 func (h *InboxHandler) HandleDeleteInbox(c *fiber.Ctx) error {
@@ -358,7 +395,7 @@ func (h *InboxHandler) HandleUpdateInboxUsers(c *fiber.Ctx) error {
 	}
 
 	// Reload inbox with users and configurations
-	updatedInbox, err := h.repo.GetInboxByID(inboxID)
+	updatedInbox, err := h.reloadInbox(inboxID)
 	if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_reload_inbox"), err)
 	}
@@ -369,5 +406,5 @@ func (h *InboxHandler) HandleUpdateInboxUsers(c *fiber.Ctx) error {
 		User:           user.User,
 	})
 
-	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "inbox_users_updated"), updatedInbox.ToResponse())
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "inbox_users_updated"), h.channels.InboxPayload(updatedInbox, false))
 }
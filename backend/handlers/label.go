@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/listeners"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LabelInput struct {
+	Name        string `json:"name" validate:"required,max=50"`
+	Color       string `json:"color" validate:"required,hexcolor"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+type ConversationLabelsInput struct {
+	LabelIDs []string `json:"label_ids" validate:"required,min=1,dive,uuid"`
+}
+
+type LabelHandler struct {
+	repo             repositories.LabelRepository
+	conversationRepo repositories.ConversationRepository
+	securityContext  interfaces.SecurityContext
+	dispatcher       interfaces.Dispatcher
+	langContext      interfaces.LanguageContext
+}
+
+func NewLabelHandler(repo repositories.LabelRepository, conversationRepo repositories.ConversationRepository, securityContext interfaces.SecurityContext, dispatcher interfaces.Dispatcher, langContext interfaces.LanguageContext) *LabelHandler {
+	return &LabelHandler{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		securityContext:  securityContext,
+		dispatcher:       dispatcher,
+		langContext:      langContext,
+	}
+}
+
+func (h *LabelHandler) HandleListLabels(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	labels, err := h.repo.GetLabelsByCompanyID(*user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_get_labels"), err)
+	}
+
+	labelsResponse := make([]interface{}, len(labels))
+	for i, label := range labels {
+		labelsResponse[i] = label.ToResponse()
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "labels_found"), labelsResponse)
+}
+
+func (h *LabelHandler) HandleCreateLabel(c *fiber.Ctx) error {
+	var input LabelInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	user := h.securityContext.GetAuthenticatedUser(c)
+	name := strings.TrimSpace(input.Name)
+
+	if _, err := h.repo.GetLabelByNameAndCompanyID(name, *user.User.CompanyID); err == nil {
+		return utils.ErrorResponse(c, fiber.StatusConflict, h.langContext.T(c, "label_already_exists"), nil)
+	}
+
+	label := &models.Label{
+		CompanyID:   *user.User.CompanyID,
+		Name:        name,
+		Color:       strings.ToUpper(input.Color),
+		Description: input.Description,
+	}
+
+	if err := h.repo.CreateLabel(label); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_create_label"), err)
+	}
+
+	h.dispatcher.Dispatch(interfaces.EventTypeLabelCreated, &listeners.LabelPayload{
+		Label: label,
+		User:  user.User,
+	})
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, h.langContext.T(c, "label_created"), label.ToResponse())
+}
+
+func (h *LabelHandler) HandleUpdateLabel(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	var input LabelInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	label, err := h.repo.GetLabelByIDAndCompanyID(c.Params("id"), *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "label_not_found"), err)
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if existing, err := h.repo.GetLabelByNameAndCompanyID(name, *user.User.CompanyID); err == nil && existing.ID != label.ID {
+		return utils.ErrorResponse(c, fiber.StatusConflict, h.langContext.T(c, "label_already_exists"), nil)
+	}
+
+	label.Name = name
+	label.Color = strings.ToUpper(input.Color)
+	label.Description = input.Description
+
+	if err := h.repo.UpdateLabel(label); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_label"), err)
+	}
+
+	h.dispatcher.Dispatch(interfaces.EventTypeLabelUpdated, &listeners.LabelPayload{
+		Label: label,
+		User:  user.User,
+	})
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "label_updated"), label.ToResponse())
+}
+
+func (h *LabelHandler) HandleDeleteLabel(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	label, err := h.repo.GetLabelByIDAndCompanyID(c.Params("id"), *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "label_not_found"), err)
+	}
+
+	conversationIDs, err := h.repo.GetConversationIDsByLabelID(label.ID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_delete_label"), err)
+	}
+
+	if err := h.repo.DeleteLabel(label); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_delete_label"), err)
+	}
+
+	h.dispatcher.Dispatch(interfaces.EventTypeLabelDeleted, &listeners.LabelDeletedPayload{
+		Label:           label,
+		User:            user.User,
+		ConversationIDs: conversationIDs,
+	})
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "label_deleted"), nil)
+}
+
+func (h *LabelHandler) HandleAddConversationLabels(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	var input ConversationLabelsInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	conversation, err := h.conversationRepo.GetConversationByIdAndCompanyID(c.Params("id"), *user.User.CompanyID, "Labels")
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	labels, err := h.repo.GetLabelsByIDsAndCompanyID(input.LabelIDs, *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_conversation_labels"), err)
+	}
+
+	if len(labels) != len(utils.Unique(input.LabelIDs)) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "label_not_found"), nil)
+	}
+
+	if err := h.repo.AddLabelsToConversation(conversation, labels); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_conversation_labels"), err)
+	}
+
+	return h.conversationLabelsUpdated(c, conversation, user.User)
+}
+
+func (h *LabelHandler) HandleRemoveConversationLabel(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	conversation, err := h.conversationRepo.GetConversationByIdAndCompanyID(c.Params("id"), *user.User.CompanyID, "Labels")
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), err)
+	}
+
+	label, err := h.repo.GetLabelByIDAndCompanyID(c.Params("labelId"), *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "label_not_found"), err)
+	}
+
+	if err := h.repo.RemoveLabelFromConversation(conversation, label); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_conversation_labels"), err)
+	}
+
+	return h.conversationLabelsUpdated(c, conversation, user.User)
+}
+
+// conversationLabelsUpdated broadcasts the conversation's labels and responds with them
+func (h *LabelHandler) conversationLabelsUpdated(c *fiber.Ctx, conversation *models.Conversation, user *models.User) error {
+	h.dispatcher.Dispatch(interfaces.EventTypeConversationLabels, &listeners.ConversationLabelsPayload{
+		Conversation: conversation,
+		User:         user,
+	})
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "conversation_labels_updated"), models.LabelsToPayload(conversation.Labels))
+}
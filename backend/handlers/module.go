@@ -62,6 +62,14 @@ func RegisterHandlers(container *dig.Container) {
 		log.Fatalf("Failed to provide canned response handler: %v", err)
 	}
 
+	if err := container.Provide(NewLabelHandler); err != nil {
+		log.Fatalf("Failed to provide label handler: %v", err)
+	}
+
+	if err := container.Provide(NewSLAPolicyHandler); err != nil {
+		log.Fatalf("Failed to provide SLA policy handler: %v", err)
+	}
+
 	if err := container.Provide(NewNotificationHandler); err != nil {
 		log.Fatalf("Failed to provide notification handler: %v", err)
 	}
@@ -77,4 +85,12 @@ func RegisterHandlers(container *dig.Container) {
 	if err := container.Provide(NewAnalyticsHandler); err != nil {
 		log.Fatalf("Failed to provide analytics handler: %v", err)
 	}
+
+	if err := container.Provide(NewSearchHandler); err != nil {
+		log.Fatalf("Failed to provide search handler: %v", err)
+	}
+
+	if err := container.Provide(NewSavedViewHandler); err != nil {
+		log.Fatalf("Failed to provide saved view handler: %v", err)
+	}
 }
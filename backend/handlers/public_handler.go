@@ -17,9 +17,10 @@ type PublicHandler struct {
 	conversationRepo repositories.ConversationRepository
 	config           config.ConfigManager
 	userRepo         repositories.UserRepository
+	channels         interfaces.ChannelRegistry
 }
 
-func NewPublicHandler(inboxRepo repositories.InboxRepository, conversationRepo repositories.ConversationRepository, logger interfaces.Logger, langContext interfaces.LanguageContext, config config.ConfigManager, userRepo repositories.UserRepository) *PublicHandler {
+func NewPublicHandler(inboxRepo repositories.InboxRepository, conversationRepo repositories.ConversationRepository, logger interfaces.Logger, langContext interfaces.LanguageContext, config config.ConfigManager, userRepo repositories.UserRepository, channels interfaces.ChannelRegistry) *PublicHandler {
 	return &PublicHandler{
 		inboxRepo:        inboxRepo,
 		logger:           logger,
@@ -27,6 +28,7 @@ func NewPublicHandler(inboxRepo repositories.InboxRepository, conversationRepo r
 		conversationRepo: conversationRepo,
 		config:           config,
 		userRepo:         userRepo,
+		channels:         channels,
 	}
 }
 
@@ -43,7 +45,11 @@ func (h *PublicHandler) HandleGetInboxDetails(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "inbox_not_found"), errors.New("inbox is not enabled"))
 	}
 
-	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "inbox_details_retrieved"), inbox.ToResponse())
+	if err := h.channels.LoadConfig(inbox); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "inbox_not_found"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "inbox_details_retrieved"), h.channels.PublicInboxPayload(inbox))
 }
 
 func (h *PublicHandler) HandleGetConversationDetails(c *fiber.Ctx) error {
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type SavedViewInput struct {
+	Name       string                              `json:"name" validate:"required,max=100"`
+	Visibility string                              `json:"visibility" validate:"required,oneof=private team company"`
+	InboxID    string                              `json:"inbox_id" validate:"required_if=Visibility team,omitempty,uuid"`
+	Filter     models.ConversationFilterDefinition `json:"filter"`
+}
+
+type SavedViewRunResponse struct {
+	*ConversationListResponse
+
+	View  interface{} `json:"view"`
+	Count int64       `json:"count"`
+}
+
+type SavedViewHandler struct {
+	repo             repositories.SavedViewRepository
+	conversationRepo repositories.ConversationRepository
+	readRepo         repositories.ConversationReadRepository
+	inboxRepo        repositories.InboxRepository
+	securityContext  interfaces.SecurityContext
+	langContext      interfaces.LanguageContext
+}
+
+func NewSavedViewHandler(repo repositories.SavedViewRepository, conversationRepo repositories.ConversationRepository, readRepo repositories.ConversationReadRepository, inboxRepo repositories.InboxRepository, securityContext interfaces.SecurityContext, langContext interfaces.LanguageContext) *SavedViewHandler {
+	return &SavedViewHandler{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		readRepo:         readRepo,
+		inboxRepo:        inboxRepo,
+		securityContext:  securityContext,
+		langContext:      langContext,
+	}
+}
+
+func (h *SavedViewHandler) HandleListSavedViews(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	views, err := h.repo.GetSavedViewsForUser(user.User.ID, *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_get_saved_views"), err)
+	}
+
+	viewsResponse := make([]interface{}, len(views))
+	for i, view := range views {
+		viewsResponse[i] = view.ToResponse()
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "saved_views_found"), viewsResponse)
+}
+
+func (h *SavedViewHandler) HandleCreateSavedView(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	view := &models.SavedView{
+		CompanyID: *user.User.CompanyID,
+		UserID:    user.User.ID,
+	}
+	if ok, err := h.bindSavedView(c, view, user.User); !ok {
+		return err
+	}
+
+	if err := h.repo.CreateSavedView(view); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_create_saved_view"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, h.langContext.T(c, "saved_view_created"), view.ToResponse())
+}
+
+func (h *SavedViewHandler) HandleUpdateSavedView(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	view, err := h.repo.GetSavedViewForUser(c.Params("id"), user.User.ID, *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "saved_view_not_found"), err)
+	}
+
+	if !view.CanManage(user.User) {
+		return utils.ErrorResponse(c, fiber.StatusForbidden, h.langContext.T(c, "saved_view_not_manageable"), nil)
+	}
+
+	if ok, err := h.bindSavedView(c, view, user.User); !ok {
+		return err
+	}
+
+	if err := h.repo.UpdateSavedView(view); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_saved_view"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "saved_view_updated"), view.ToResponse())
+}
+
+func (h *SavedViewHandler) HandleDeleteSavedView(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	view, err := h.repo.GetSavedViewForUser(c.Params("id"), user.User.ID, *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "saved_view_not_found"), err)
+	}
+
+	if !view.CanManage(user.User) {
+		return utils.ErrorResponse(c, fiber.StatusForbidden, h.langContext.T(c, "saved_view_not_manageable"), nil)
+	}
+
+	if err := h.repo.DeleteSavedView(view); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_delete_saved_view"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "saved_view_deleted"), nil)
+}
+
+// HandleRunSavedView lists a page of the conversations matching the view, with how many match it now
+func (h *SavedViewHandler) HandleRunSavedView(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	view, err := h.repo.GetSavedViewForUser(c.Params("id"), user.User.ID, *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "saved_view_not_found"), err)
+	}
+
+	filter := repositories.NewConversationFilter(view.Filter, user.User.ID)
+	if err := parseConversationPage(c, &filter); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	conversations, err := listConversations(h.conversationRepo, h.readRepo, user.User.ID, filter)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "conversation_not_found"), nil)
+	} else if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_run_saved_view"), err)
+	}
+
+	count, err := h.conversationRepo.CountConversationsForUser(user.User.ID, filter)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_run_saved_view"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "saved_view_run"), SavedViewRunResponse{
+		ConversationListResponse: conversations,
+		View:                     view.ToResponse(),
+		Count:                    count,
+	})
+}
+
+// bindSavedView validates the submitted view into view. When it is not valid it responds to the request and returns false.
+// Only admins share views with a team or the company.
+func (h *SavedViewHandler) bindSavedView(c *fiber.Ctx, view *models.SavedView, user *models.User) (bool, error) {
+	var input SavedViewInput
+	if err := c.BodyParser(&input); err != nil {
+		return false, utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return false, utils.ValidationErrorResponse(c, err)
+	}
+
+	view.Name = strings.TrimSpace(input.Name)
+	view.Visibility = models.SavedViewVisibility(input.Visibility)
+	view.Filter = input.Filter
+	view.InboxID = nil
+
+	if view.IsShared() && !view.CanManage(user) {
+		return false, utils.ErrorResponse(c, fiber.StatusForbidden, h.langContext.T(c, "saved_view_share_admin_only"), nil)
+	}
+
+	if view.Visibility == models.SavedViewVisibilityTeam {
+		inbox, err := h.inboxRepo.GetInboxByIDAndCompanyID(input.InboxID, *user.CompanyID)
+		if err != nil {
+			return false, utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "inbox_not_found"), err)
+		}
+		view.InboxID = &inbox.ID
+	}
+
+	return true, nil
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	SearchTypeMessages = "messages"
+	SearchTypeContacts = "contacts"
+	SearchTypeNotes    = "notes"
+)
+
+type SearchInput struct {
+	Query    string   `validate:"required,max=255"`
+	Types    []string `validate:"dive,oneof=messages contacts notes"`
+	InboxID  string   `validate:"omitempty,uuid"`
+	Status   string   `validate:"omitempty,oneof=active pending closed resolved snoozed"`
+	Assignee string   `validate:"omitempty,oneof=me unassigned|uuid"`
+	From     string   `validate:"omitempty,datetime=2006-01-02"`
+	To       string   `validate:"omitempty,datetime=2006-01-02"`
+	Limit    int      `validate:"gte=0,lte=50"`
+}
+
+// SearchResponse groups the results by type, types that were not searched are left out
+type SearchResponse struct {
+	Messages []repositories.MessageSearchResult     `json:"messages,omitempty"`
+	Contacts []repositories.ContactSearchResult     `json:"contacts,omitempty"`
+	Notes    []repositories.ContactNoteSearchResult `json:"notes,omitempty"`
+}
+
+type SearchHandler struct {
+	searchRepo      repositories.SearchRepository
+	securityContext interfaces.SecurityContext
+	logger          interfaces.Logger
+	langContext     interfaces.LanguageContext
+}
+
+func NewSearchHandler(
+	searchRepo repositories.SearchRepository,
+	securityContext interfaces.SecurityContext,
+	logger interfaces.Logger,
+	langContext interfaces.LanguageContext,
+) *SearchHandler {
+	handlerLogger := logger.Named("search_handler")
+
+	return &SearchHandler{
+		searchRepo:      searchRepo,
+		securityContext: securityContext,
+		logger:          handlerLogger,
+		langContext:     langContext,
+	}
+}
+
+// HandleSearch searches the company's messages, contacts and contact notes.
+// Messages are limited to the inboxes the agent is a member of.
+func (h *SearchHandler) HandleSearch(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	input := SearchInput{
+		Query:    strings.TrimSpace(c.Query("q")),
+		InboxID:  c.Query("inbox_id"),
+		Status:   c.Query("status"),
+		Assignee: c.Query("assignee"),
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		Limit:    c.QueryInt("limit", repositories.DefaultSearchLimit),
+	}
+	if types := c.Query("types"); types != "" {
+		input.Types = strings.Split(types, ",")
+	} else {
+		input.Types = []string{SearchTypeMessages, SearchTypeContacts, SearchTypeNotes}
+	}
+
+	if input.Query == "" {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "search_query_required"), nil)
+	}
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	query := repositories.SearchQuery{
+		CompanyID:      *user.User.CompanyID,
+		UserID:         user.User.ID,
+		Query:          input.Query,
+		IncludePrivate: true,
+		InboxID:        input.InboxID,
+		Status:         models.ConversationStatus(input.Status),
+		Limit:          input.Limit,
+	}
+
+	if input.Assignee == "me" {
+		query.AssignedToID = user.User.ID
+	} else {
+		query.AssignedToID = input.Assignee
+	}
+
+	if input.From != "" {
+		from, _ := time.Parse("2006-01-02", input.From)
+		query.From = &from
+	}
+	if input.To != "" {
+		// The end date is inclusive
+		to, _ := time.Parse("2006-01-02", input.To)
+		to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		query.To = &to
+	}
+
+	var response SearchResponse
+	var err error
+	for _, searchType := range utils.Unique(input.Types) {
+		switch searchType {
+		case SearchTypeMessages:
+			response.Messages, err = h.searchRepo.SearchMessages(query)
+		case SearchTypeContacts:
+			response.Contacts, err = h.searchRepo.SearchContacts(query)
+		case SearchTypeNotes:
+			response.Notes, err = h.searchRepo.SearchContactNotes(query)
+		}
+		if err != nil {
+			h.logger.Error("Failed to search %s for company %s: %v", searchType, query.CompanyID, err)
+			return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_search"), err)
+		}
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "search_results_fetched"), response)
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SLAPolicyInput struct {
+	Name                 string                        `json:"name" validate:"required,max=100"`
+	Description          string                        `json:"description" validate:"max=255"`
+	FirstResponseMinutes int                           `json:"first_response_minutes" validate:"min=0"`
+	NextResponseMinutes  int                           `json:"next_response_minutes" validate:"min=0"`
+	ResolutionMinutes    int                           `json:"resolution_minutes" validate:"min=0"`
+	WarningPercent       int                           `json:"warning_percent" validate:"min=0,max=99"`
+	BusinessHoursOnly    bool                          `json:"business_hours_only"`
+	BusinessHours        map[string]types.WorkingHours `json:"business_hours" validate:"required_if=BusinessHoursOnly true,omitempty,working_hours"`
+	Timezone             string                        `json:"timezone" validate:"required"`
+}
+
+type SLAPolicyHandler struct {
+	repo            repositories.SLAPolicyRepository
+	securityContext interfaces.SecurityContext
+	langContext     interfaces.LanguageContext
+}
+
+func NewSLAPolicyHandler(repo repositories.SLAPolicyRepository, securityContext interfaces.SecurityContext, langContext interfaces.LanguageContext) *SLAPolicyHandler {
+	return &SLAPolicyHandler{
+		repo:            repo,
+		securityContext: securityContext,
+		langContext:     langContext,
+	}
+}
+
+func (h *SLAPolicyHandler) HandleListSLAPolicies(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	policies, err := h.repo.GetSLAPoliciesByCompanyID(*user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_get_sla_policies"), err)
+	}
+
+	policiesResponse := make([]interface{}, len(policies))
+	for i, policy := range policies {
+		policiesResponse[i] = policy.ToResponse()
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "sla_policies_found"), policiesResponse)
+}
+
+func (h *SLAPolicyHandler) HandleGetSLAPolicy(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	policy, err := h.repo.GetSLAPolicyByIDAndCompanyID(c.Params("id"), *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "sla_policy_not_found"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "sla_policy_found"), policy.ToResponse())
+}
+
+func (h *SLAPolicyHandler) HandleCreateSLAPolicy(c *fiber.Ctx) error {
+	var input SLAPolicyInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	if _, err := time.LoadLocation(input.Timezone); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "invalid_timezone"), err)
+	}
+
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	policy := &models.SLAPolicy{CompanyID: *user.User.CompanyID}
+	input.apply(policy)
+
+	if err := h.repo.CreateSLAPolicy(policy); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_create_sla_policy"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusCreated, h.langContext.T(c, "sla_policy_created"), policy.ToResponse())
+}
+
+func (h *SLAPolicyHandler) HandleUpdateSLAPolicy(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	policy, err := h.repo.GetSLAPolicyByIDAndCompanyID(c.Params("id"), *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "sla_policy_not_found"), err)
+	}
+
+	var input SLAPolicyInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "bad_request"), err)
+	}
+
+	if err := utils.ValidateStruct(input); err != nil {
+		return utils.ValidationErrorResponse(c, err)
+	}
+
+	if _, err := time.LoadLocation(input.Timezone); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, h.langContext.T(c, "invalid_timezone"), err)
+	}
+
+	// Conversations keep the due dates they were given, the changes apply from their next clock
+	input.apply(policy)
+
+	if err := h.repo.UpdateSLAPolicy(policy); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_update_sla_policy"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "sla_policy_updated"), policy.ToResponse())
+}
+
+func (h *SLAPolicyHandler) HandleDeleteSLAPolicy(c *fiber.Ctx) error {
+	user := h.securityContext.GetAuthenticatedUser(c)
+
+	policy, err := h.repo.GetSLAPolicyByIDAndCompanyID(c.Params("id"), *user.User.CompanyID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, h.langContext.T(c, "sla_policy_not_found"), err)
+	}
+
+	if err := h.repo.DeleteSLAPolicy(policy); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, h.langContext.T(c, "failed_to_delete_sla_policy"), err)
+	}
+
+	return utils.SuccessResponse(c, fiber.StatusOK, h.langContext.T(c, "sla_policy_deleted"), nil)
+}
+
+func (input *SLAPolicyInput) apply(policy *models.SLAPolicy) {
+	policy.Name = strings.TrimSpace(input.Name)
+	policy.Description = input.Description
+	policy.FirstResponseMinutes = input.FirstResponseMinutes
+	policy.NextResponseMinutes = input.NextResponseMinutes
+	policy.ResolutionMinutes = input.ResolutionMinutes
+	policy.WarningPercent = input.WarningPercent
+	policy.BusinessHoursOnly = input.BusinessHoursOnly
+	policy.BusinessHours = input.BusinessHours
+	policy.Timezone = input.Timezone
+}
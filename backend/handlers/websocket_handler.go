@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"live-chat-server/commands"
 	"live-chat-server/interfaces"
 	"live-chat-server/listeners"
@@ -8,6 +9,7 @@ import (
 	"live-chat-server/repositories"
 	"live-chat-server/types"
 	"live-chat-server/utils"
+	"time"
 
 	"github.com/gofiber/websocket/v2"
 	"github.com/mitchellh/mapstructure"
@@ -22,6 +24,7 @@ type WebSocketHandler struct {
 	logger              interfaces.Logger
 	securityContext     interfaces.SecurityContext
 	conversationRepo    repositories.ConversationRepository
+	readRepo            repositories.ConversationReadRepository
 	inboxRepo           repositories.InboxRepository
 	contactRepo         repositories.ContactRepository
 	userRepo            repositories.UserRepository
@@ -38,6 +41,7 @@ type WebSocketHandlerParams struct {
 	Logger              interfaces.Logger
 	SecurityContext     interfaces.SecurityContext
 	ConversationRepo    repositories.ConversationRepository
+	ReadRepo            repositories.ConversationReadRepository
 	InboxRepo           repositories.InboxRepository
 	ContactRepo         repositories.ContactRepository
 	UserRepo            repositories.UserRepository
@@ -54,6 +58,7 @@ func NewWebSocketHandler(params WebSocketHandlerParams) *WebSocketHandler {
 		logger:              params.Logger,
 		securityContext:     params.SecurityContext,
 		conversationRepo:    params.ConversationRepo,
+		readRepo:            params.ReadRepo,
 		inboxRepo:           params.InboxRepo,
 		contactRepo:         params.ContactRepo,
 		userRepo:            params.UserRepo,
@@ -124,7 +129,8 @@ func (h *WebSocketHandler) HandleContactWebSocket(c *websocket.Conn) {
 			return
 		}
 	} else {
-		contact, err = h.contactRepo.GetContactByID(contactID)
+		// A contact merged into another keeps working as the contact it was merged into
+		contact, err = h.contactRepo.ResolveContactByID(contactID)
 		if err != nil {
 			h.logger.Error("Failed to get contact", "error", err, "contact_id", contactID)
 			c.Close()
@@ -166,6 +172,26 @@ func (h *WebSocketHandler) handleMessages(client *types.WebSocketClient) {
 			h.HandleConversationTypingStop(client, &msg)
 		case types.EventTypeConversationClose:
 			h.HandleConversationClose(client, &msg)
+		case types.EventTypeConversationResolve:
+			h.HandleConversationResolve(client, &msg)
+		case types.EventTypeConversationReopen:
+			h.HandleConversationReopen(client, &msg)
+		case types.EventTypeConversationPending:
+			h.HandleConversationPending(client, &msg)
+		case types.EventTypeConversationSnooze:
+			h.HandleConversationSnooze(client, &msg)
+		case types.EventTypeConversationWake:
+			h.HandleConversationWake(client, &msg)
+		case types.EventTypeConversationPriority:
+			h.HandleConversationPriority(client, &msg)
+		case types.EventTypeConversationRead:
+			h.HandleConversationRead(client, &msg)
+		case types.EventTypeConversationSendTranscript:
+			h.HandleConversationSendTranscript(client, &msg)
+		case types.EventTypeMessageEdit:
+			h.HandleMessageEdit(client, &msg)
+		case types.EventTypeMessageDelete:
+			h.HandleMessageDelete(client, &msg)
 		case types.EventTypeSubscribe:
 			h.HandleSubscribe(client, &msg)
 		case types.EventTypeUnsubscribe:
@@ -229,15 +255,49 @@ func (h *WebSocketHandler) HandleConversationGetByID(client *types.WebSocketClie
 		return
 	}
 
-	conversation, err := h.conversationRepo.GetConversationByID(payload.ConversationID, "Messages", "Inbox", "Contact", "AssignedTo")
+	conversation, err := h.conversationRepo.GetConversationByID(payload.ConversationID, "Inbox", "Contact", "AssignedTo", "Labels", "Participants.User")
 	if err != nil {
 		client.SendError("Failed to get conversation", "SERVER_ERROR")
 		return
 	}
 
+	if (client.IsAgent() && conversation.CompanyID != client.GetCompanyID()) || (client.IsContact() && conversation.ContactID != client.GetID()) {
+		client.SendError("Unauthorized", "UNAUTHORIZED")
+		return
+	}
+
+	err = h.conversationRepo.LoadMessagesPage(conversation, repositories.MessagePage{
+		Before:         payload.Before,
+		After:          payload.After,
+		Limit:          payload.Limit,
+		IncludePrivate: client.IsAgent(),
+	})
+	if err != nil {
+		client.SendError("Failed to get conversation messages", "SERVER_ERROR")
+		return
+	}
+
 	// Subscribe to the conversation
 	h.pubSub.Subscribe(client, "conversation:"+payload.ConversationID)
 
+	// Everything the client is about to be sent has been delivered to them
+	readerType := models.SenderType(getSenderType(client.GetType()))
+	if _, err := h.commandFactory.NewRecordReceiptCommand(conversation, models.ReceiptTypeDelivered, readerType, client.GetID(), "").Handle(); err != nil {
+		h.logger.Error("Failed to record delivery receipt of conversation %s: %v", conversation.ID, err)
+	}
+
+	if conversation.Reads, err = h.readRepo.GetConversationReads(conversation.ID); err != nil {
+		h.logger.Error("Failed to get read state of conversation %s: %v", conversation.ID, err)
+	}
+
+	unreadCounts, err := h.readRepo.GetUnreadCounts([]string{conversation.ID}, readerType, client.GetID(), client.IsAgent())
+	if err != nil {
+		h.logger.Error("Failed to count unread messages of conversation %s: %v", conversation.ID, err)
+	} else {
+		unreadCount := unreadCounts[conversation.ID]
+		conversation.UnreadCount = &unreadCount
+	}
+
 	h.dispatcher.Dispatch(interfaces.EventTypeConversationGetByID, map[string]interface{}{
 		"conversation": conversation,
 		"client":       client,
@@ -252,7 +312,7 @@ func (h *WebSocketHandler) HandleConversationSendMessage(client *types.WebSocket
 		return
 	}
 
-	conversation, err := h.conversationRepo.GetConversationByID(payload.ConversationID, "Messages", "Inbox", "Contact", "AssignedTo")
+	conversation, err := h.conversationRepo.GetConversationByID(payload.ConversationID, "Inbox", "Contact", "AssignedTo", "Labels")
 	if err != nil {
 		client.SendError("Failed to get conversation", "SERVER_ERROR")
 		return
@@ -267,6 +327,18 @@ func (h *WebSocketHandler) HandleConversationSendMessage(client *types.WebSocket
 		private = true
 	}
 
+	// Only private notes can mention agents
+	if !private {
+		delete(payload.Metadata, "mentions")
+	} else if err := h.validateMentions(conversation, payload.Metadata); err != nil {
+		if errors.Is(err, models.ErrInvalidMentions) || errors.Is(err, models.ErrMentionNotInboxMember) {
+			client.SendError(err.Error(), "INVALID_PAYLOAD")
+		} else {
+			client.SendError("Failed to send message", "SERVER_ERROR")
+		}
+		return
+	}
+
 	// Create internal message payload
 	internalMessage := &listeners.InternalMessagePayload{
 		ConversationID: payload.ConversationID,
@@ -278,6 +350,21 @@ func (h *WebSocketHandler) HandleConversationSendMessage(client *types.WebSocket
 	internalMessage.Sender.ID = client.GetID()
 	internalMessage.Sender.Type = getSenderType(client.GetType())
 
+	if payload.ReplyToMessageID != "" {
+		original, err := h.conversationRepo.GetMessageByID(payload.ReplyToMessageID)
+		if err != nil {
+			client.SendError("The quoted message is not part of the conversation", "INVALID_PAYLOAD")
+			return
+		}
+
+		reply := &models.Message{ConversationID: conversation.ID, Private: private}
+		if err := reply.CanReplyTo(original); err != nil {
+			client.SendError(err.Error(), "INVALID_PAYLOAD")
+			return
+		}
+		internalMessage.ReplyToMessageID = &original.ID
+	}
+
 	// Create a structured payload for the dispatcher
 	messagePayload := map[string]interface{}{
 		"message":      internalMessage,
@@ -287,6 +374,21 @@ func (h *WebSocketHandler) HandleConversationSendMessage(client *types.WebSocket
 	h.dispatcher.Dispatch(interfaces.EventTypeConversationSendMessage, messagePayload)
 }
 
+// validateMentions checks that everyone mentioned in a private note is a member of the conversation's inbox
+func (h *WebSocketHandler) validateMentions(conversation *models.Conversation, metadata map[string]interface{}) error {
+	if metadata["mentions"] == nil {
+		return nil
+	}
+
+	members, err := h.inboxRepo.GetUsersForInbox(conversation.InboxID)
+	if err != nil {
+		return err
+	}
+
+	note := &models.Message{Private: true, Metadata: metadata}
+	return note.ValidateMentions(members)
+}
+
 // HandleConversationTyping handles the typing indicator
 func (h *WebSocketHandler) HandleConversationTyping(client *types.WebSocketClient, msg *types.WebSocketMessage) {
 	var payload types.IncomingConversationTypingPayload
@@ -340,28 +442,262 @@ func (h *WebSocketHandler) HandleConversationTypingStop(client *types.WebSocketC
 
 // HandleConversationClose handles closing a conversation
 func (h *WebSocketHandler) HandleConversationClose(client *types.WebSocketClient, msg *types.WebSocketMessage) {
-	var payload types.IncomingCloseConversationPayload
+	h.handleConversationTransition(client, msg, models.ConversationTransitionClose)
+}
+
+// HandleConversationResolve handles an agent resolving a conversation
+func (h *WebSocketHandler) HandleConversationResolve(client *types.WebSocketClient, msg *types.WebSocketMessage) {
+	h.handleConversationTransition(client, msg, models.ConversationTransitionResolve)
+}
+
+// HandleConversationReopen handles an agent reopening a resolved conversation
+func (h *WebSocketHandler) HandleConversationReopen(client *types.WebSocketClient, msg *types.WebSocketMessage) {
+	h.handleConversationTransition(client, msg, models.ConversationTransitionReopen)
+}
+
+// HandleConversationPending handles an agent marking a conversation as pending
+func (h *WebSocketHandler) HandleConversationPending(client *types.WebSocketClient, msg *types.WebSocketMessage) {
+	h.handleConversationTransition(client, msg, models.ConversationTransitionPending)
+}
+
+// HandleConversationWake handles an agent waking a snoozed conversation
+func (h *WebSocketHandler) HandleConversationWake(client *types.WebSocketClient, msg *types.WebSocketMessage) {
+	h.handleConversationTransition(client, msg, models.ConversationTransitionWake)
+}
+
+// HandleConversationSnooze handles an agent snoozing a conversation
+func (h *WebSocketHandler) HandleConversationSnooze(client *types.WebSocketClient, msg *types.WebSocketMessage) {
+	if !client.IsAgent() {
+		client.SendError("Unauthorized", "UNAUTHORIZED")
+		return
+	}
+
+	var payload types.IncomingSnoozeConversationPayload
+	if err := mapstructure.Decode(msg.Payload, &payload); err != nil {
+		client.SendError("Invalid payload", "INVALID_PAYLOAD")
+		return
+	}
+
+	until, err := time.Parse(time.RFC3339, payload.SnoozedUntil)
+	if err != nil {
+		client.SendError("Invalid snooze time", "INVALID_PAYLOAD")
+		return
+	}
+
+	conversation, actorID, ok := h.getConversationForStatusChange(client, payload.ConversationID)
+	if !ok {
+		return
+	}
+
+	_, err = h.commandFactory.NewSnoozeConversationCommand(conversation, until, actorID).Handle()
+	h.sendStatusChangeError(client, err)
+}
+
+// HandleConversationPriority handles an agent changing the priority of a conversation
+func (h *WebSocketHandler) HandleConversationPriority(client *types.WebSocketClient, msg *types.WebSocketMessage) {
+	if !client.IsAgent() {
+		client.SendError("Unauthorized", "UNAUTHORIZED")
+		return
+	}
+
+	var payload types.IncomingConversationPriorityPayload
 	if err := mapstructure.Decode(msg.Payload, &payload); err != nil {
 		client.SendError("Invalid payload", "INVALID_PAYLOAD")
 		return
 	}
 
-	conversation, err := h.conversationRepo.GetConversationByID(payload.ConversationID, "Contact", "Inbox", "AssignedTo")
+	conversation, actorID, ok := h.getConversationForStatusChange(client, payload.ConversationID)
+	if !ok {
+		return
+	}
+
+	_, err := h.commandFactory.NewSetConversationPriorityCommand(conversation, models.ConversationPriority(payload.Priority), actorID).Handle()
+	h.sendStatusChangeError(client, err)
+}
+
+// HandleConversationRead moves the client's read pointer up to a message of the conversation,
+// or to the latest message they can see when none is given
+func (h *WebSocketHandler) HandleConversationRead(client *types.WebSocketClient, msg *types.WebSocketMessage) {
+	var payload types.IncomingConversationReadPayload
+	if err := mapstructure.Decode(msg.Payload, &payload); err != nil {
+		client.SendError("Invalid payload", "INVALID_PAYLOAD")
+		return
+	}
+
+	conversation, err := h.conversationRepo.GetConversationByID(payload.ConversationID)
 	if err != nil {
 		client.SendError("Failed to get conversation", "SERVER_ERROR")
 		return
 	}
 
-	conversation.Status = models.ConversationStatusClosed
+	if (client.IsAgent() && conversation.CompanyID != client.GetCompanyID()) || (client.IsContact() && conversation.ContactID != client.GetID()) {
+		client.SendError("Unauthorized", "UNAUTHORIZED")
+		return
+	}
+
+	readerType := models.SenderType(getSenderType(client.GetType()))
+	_, err = h.commandFactory.NewRecordReceiptCommand(conversation, models.ReceiptTypeRead, readerType, client.GetID(), payload.MessageID).Handle()
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrReceiptMessageNotFound):
+		client.SendError("Message not found", "INVALID_PAYLOAD")
+	default:
+		client.SendError("Failed to mark conversation as read", "SERVER_ERROR")
+	}
+}
+
+// HandleConversationSendTranscript handles a contact asking for a transcript of their conversation by email
+func (h *WebSocketHandler) HandleConversationSendTranscript(client *types.WebSocketClient, msg *types.WebSocketMessage) {
+	if !client.IsContact() {
+		client.SendError("Unauthorized", "UNAUTHORIZED")
+		return
+	}
+
+	var payload types.IncomingConversationTranscriptPayload
+	if err := mapstructure.Decode(msg.Payload, &payload); err != nil {
+		client.SendError("Invalid payload", "INVALID_PAYLOAD")
+		return
+	}
+
+	conversation, err := h.conversationRepo.GetConversationByID(payload.ConversationID)
+	if err != nil {
+		client.SendError("Failed to get conversation", "SERVER_ERROR")
+		return
+	}
 
-	if err := h.conversationRepo.UpdateConversation(conversation); err != nil {
-		client.SendError("Failed to close conversation", "SERVER_ERROR")
+	if conversation.ContactID != client.GetID() {
+		client.SendError("Unauthorized", "UNAUTHORIZED")
 		return
 	}
 
-	h.SendSystemMessage(conversation, "This conversation has been closed.")
+	_, err = h.commandFactory.NewSendConversationTranscriptCommand(conversation.ID, models.TranscriptTriggerRequest, payload.Timezone).Handle()
+	switch {
+	case err == nil:
+		client.SendMessage(types.EventTypeConversationTranscriptSent, map[string]interface{}{
+			"conversation_id": conversation.ID,
+		})
+	case errors.Is(err, models.ErrContactHasNoEmail):
+		client.SendError("An email address is needed to send the transcript", "NO_EMAIL")
+	case errors.Is(err, models.ErrTranscriptRecentlyRequested):
+		client.SendError("A transcript was sent recently, please try again later", "TRANSCRIPT_RECENTLY_SENT")
+	default:
+		client.SendError("Failed to send transcript", "SERVER_ERROR")
+	}
+}
+
+// HandleMessageEdit handles an agent or contact editing one of their messages
+func (h *WebSocketHandler) HandleMessageEdit(client *types.WebSocketClient, msg *types.WebSocketMessage) {
+	var payload types.IncomingEditMessagePayload
+	if err := mapstructure.Decode(msg.Payload, &payload); err != nil {
+		client.SendError("Invalid payload", "INVALID_PAYLOAD")
+		return
+	}
 
-	h.dispatcher.Dispatch(interfaces.EventTypeConversationClose, conversation)
+	message, err := h.conversationRepo.GetMessageByID(payload.MessageID)
+	if err != nil {
+		client.SendError("Failed to get message", "SERVER_ERROR")
+		return
+	}
+
+	_, err = h.commandFactory.NewEditMessageCommand(message, payload.Content, models.SenderType(getSenderType(client.GetType())), client.GetID()).Handle()
+	h.sendMessageChangeError(client, err)
+}
+
+// HandleMessageDelete handles an agent or contact deleting one of their messages
+func (h *WebSocketHandler) HandleMessageDelete(client *types.WebSocketClient, msg *types.WebSocketMessage) {
+	var payload types.IncomingDeleteMessagePayload
+	if err := mapstructure.Decode(msg.Payload, &payload); err != nil {
+		client.SendError("Invalid payload", "INVALID_PAYLOAD")
+		return
+	}
+
+	message, err := h.conversationRepo.GetMessageByID(payload.MessageID)
+	if err != nil {
+		client.SendError("Failed to get message", "SERVER_ERROR")
+		return
+	}
+
+	_, err = h.commandFactory.NewDeleteMessageCommand(message, models.SenderType(getSenderType(client.GetType())), client.GetID()).Handle()
+	h.sendMessageChangeError(client, err)
+}
+
+// handleConversationTransition applies a status transition requested over the socket.
+// Contacts may only close their conversation, every other transition is made by agents.
+func (h *WebSocketHandler) handleConversationTransition(client *types.WebSocketClient, msg *types.WebSocketMessage, transition models.ConversationTransition) {
+	if !client.IsAgent() && transition != models.ConversationTransitionClose {
+		client.SendError("Unauthorized", "UNAUTHORIZED")
+		return
+	}
+
+	var payload types.IncomingConversationTransitionPayload
+	if err := mapstructure.Decode(msg.Payload, &payload); err != nil {
+		client.SendError("Invalid payload", "INVALID_PAYLOAD")
+		return
+	}
+
+	conversation, actorID, ok := h.getConversationForStatusChange(client, payload.ConversationID)
+	if !ok {
+		return
+	}
+
+	_, err := h.commandFactory.NewTransitionConversationCommand(conversation, transition, actorID).Handle()
+	h.sendStatusChangeError(client, err)
+}
+
+// getConversationForStatusChange loads the conversation a client is changing the status of,
+// along with the agent making the change. It reports false once an error has been sent.
+func (h *WebSocketHandler) getConversationForStatusChange(client *types.WebSocketClient, conversationID string) (*models.Conversation, *string, bool) {
+	conversation, err := h.conversationRepo.GetConversationByID(conversationID, "Contact", "Inbox", "AssignedTo", "Labels")
+	if err != nil {
+		client.SendError("Failed to get conversation", "SERVER_ERROR")
+		return nil, nil, false
+	}
+
+	if conversation.CompanyID != client.GetCompanyID() || (!client.IsAgent() && conversation.ContactID != client.GetID()) {
+		client.SendError("Unauthorized", "UNAUTHORIZED")
+		return nil, nil, false
+	}
+
+	if !client.IsAgent() {
+		return conversation, nil, true
+	}
+
+	agentID := client.GetID()
+	return conversation, &agentID, true
+}
+
+// sendStatusChangeError tells the client why a status command failed, if it did
+func (h *WebSocketHandler) sendStatusChangeError(client *types.WebSocketClient, err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrInvalidConversationTransition):
+		client.SendError("Invalid status transition", "INVALID_STATUS_TRANSITION")
+	case errors.Is(err, models.ErrSnoozeUntilInPast):
+		client.SendError("The snooze time must be in the future", "INVALID_PAYLOAD")
+	case errors.Is(err, models.ErrInvalidConversationPriority):
+		client.SendError("Invalid priority", "INVALID_PAYLOAD")
+	default:
+		client.SendError("Failed to update conversation", "SERVER_ERROR")
+	}
+}
+
+// sendMessageChangeError tells the client why an edit or delete command failed, if it did
+func (h *WebSocketHandler) sendMessageChangeError(client *types.WebSocketClient, err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrMessageNotOwnMessage):
+		client.SendError("You can only change your own messages", "UNAUTHORIZED")
+	case errors.Is(err, models.ErrMessageEditWindowExpired):
+		client.SendError("The message can no longer be changed", "MESSAGE_EDIT_WINDOW_EXPIRED")
+	case errors.Is(err, models.ErrMessageDeleted):
+		client.SendError("The message has already been deleted", "MESSAGE_DELETED")
+	case errors.Is(err, models.ErrMessageNotEditable), errors.Is(err, models.ErrMessageContentRequired):
+		client.SendError(err.Error(), "INVALID_PAYLOAD")
+	case errors.Is(err, models.ErrConversationClosed):
+		client.SendError("The conversation is closed", "CONVERSATION_CLOSED")
+	default:
+		client.SendError("Failed to update message", "SERVER_ERROR")
+	}
 }
 
 // SendSystemMessage sends a system message to a conversation
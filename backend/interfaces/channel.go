@@ -0,0 +1,70 @@
+package interfaces
+
+import (
+	"live-chat-server/models"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Channel implements an inbox type: its configuration, how agent replies reach
+// the contact and the routes external services call to deliver contact messages
+type Channel interface {
+	// Type returns the inbox type the channel implements
+	Type() models.InboxType
+	// LoadConfig loads the configuration of the inbox and sets it on the inbox.
+	// Inboxes without a configuration are left without one.
+	LoadConfig(inbox *models.Inbox) error
+	// ApplyToPayload adds the configuration of the inbox to the payload returned to agents
+	ApplyToPayload(inbox *models.Inbox, payload *types.InboxPayload)
+	// ApplyToPublicPayload adds the configuration fields which are safe to expose to contacts
+	ApplyToPublicPayload(inbox *models.Inbox, payload *types.InboxPayload)
+	// CreateConfig validates the channel settings of an inbox create request
+	// and returns the configuration of the new inbox
+	CreateConfig(c *fiber.Ctx) (models.InboxConfig, error)
+	// UpdateConfig validates the channel settings of an inbox update request and applies
+	// them to the inbox's configuration. It returns nil if the request changes nothing.
+	UpdateConfig(c *fiber.Ctx, inbox *models.Inbox) (models.InboxConfig, error)
+	// SampleConfig returns the configuration of an inbox created by the seeders
+	SampleConfig(inbox *models.Inbox) models.InboxConfig
+	// Deliver sends a message from an agent to the conversation's contact
+	Deliver(conversation *models.Conversation, message *models.Message) error
+	// RegisterRoutes registers the channel's inbound routes on the API router
+	RegisterRoutes(router fiber.Router)
+}
+
+// ChannelSecrets is implemented by channels whose configuration holds secrets which admins
+// need to read back, e.g. to set up an integration. They are left out of every other payload.
+type ChannelSecrets interface {
+	// ApplySecretsToPayload adds the secrets of the inbox to the payload returned to admins
+	ApplySecretsToPayload(inbox *models.Inbox, payload *types.InboxPayload)
+}
+
+// ChannelRegistry holds the channels registered in the container
+type ChannelRegistry interface {
+	// Get returns the channel implementing the inbox type
+	Get(inboxType models.InboxType) (Channel, bool)
+	// Channels returns all registered channels
+	Channels() []Channel
+	// RegisterRoutes registers the inbound routes of all channels
+	RegisterRoutes(router fiber.Router)
+	// LoadConfig loads the configuration of the inbox through its channel
+	LoadConfig(inbox *models.Inbox) error
+	// InboxPayload returns the payload of the inbox for agents, with the channel's secrets for admins
+	InboxPayload(inbox *models.Inbox, admin bool) types.InboxPayload
+	// PublicInboxPayload returns the payload of the inbox which is safe to expose to contacts
+	PublicInboxPayload(inbox *models.Inbox) types.InboxPayload
+}
+
+// ChannelConfigError is returned by a channel when the submitted configuration is invalid
+type ChannelConfigError struct {
+	// Key is the translation key of the error message
+	Key string
+	// Errors lists the invalid fields, if the request failed validation
+	Errors []utils.ValidationError
+}
+
+func (e *ChannelConfigError) Error() string {
+	return e.Key
+}
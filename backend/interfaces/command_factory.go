@@ -3,6 +3,7 @@ package interfaces
 import (
 	"live-chat-server/models"
 	"live-chat-server/types"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -23,4 +24,37 @@ type CommandFactory interface {
 
 	// NewHandleMessageNotificationCommand creates a new HandleMessageNotificationCommand
 	NewHandleMessageNotificationCommand(conversation *models.Conversation, message *models.Message) Command
+
+	// NewHandleInboundEmailCommand creates a new HandleInboundEmailCommand
+	NewHandleInboundEmailCommand(inbox *models.Inbox, email *types.InboundEmail) Command
+
+	// NewHandleInboundSMSCommand creates a new HandleInboundSMSCommand
+	NewHandleInboundSMSCommand(inbox *models.Inbox, message *types.InboundSMS) Command
+
+	// NewHandleInboundWhatsAppCommand creates a new HandleInboundWhatsAppCommand
+	NewHandleInboundWhatsAppCommand(inbox *models.Inbox, message *types.InboundWhatsAppMessage) Command
+
+	// NewTransitionConversationCommand creates a new TransitionConversationCommand
+	NewTransitionConversationCommand(conversation *models.Conversation, transition models.ConversationTransition, actorID *string) Command
+
+	// NewSnoozeConversationCommand creates a new SnoozeConversationCommand
+	NewSnoozeConversationCommand(conversation *models.Conversation, until time.Time, actorID *string) Command
+
+	// NewSetConversationPriorityCommand creates a new SetConversationPriorityCommand
+	NewSetConversationPriorityCommand(conversation *models.Conversation, priority models.ConversationPriority, actorID *string) Command
+
+	// NewTransferConversationCommand creates a new TransferConversationCommand
+	NewTransferConversationCommand(conversation *models.Conversation, inbox *models.Inbox, assigneeID *string, autoAssign bool, actorID *string) Command
+
+	// NewEditMessageCommand creates a new EditMessageCommand
+	NewEditMessageCommand(message *models.Message, content string, editorType models.SenderType, editorID string) Command
+
+	// NewDeleteMessageCommand creates a new DeleteMessageCommand
+	NewDeleteMessageCommand(message *models.Message, editorType models.SenderType, editorID string) Command
+
+	// NewRecordReceiptCommand creates a new RecordReceiptCommand
+	NewRecordReceiptCommand(conversation *models.Conversation, receipt models.ReceiptType, readerType models.SenderType, readerID string, messageID string) Command
+
+	// NewSendConversationTranscriptCommand creates a new SendConversationTranscriptCommand
+	NewSendConversationTranscriptCommand(conversationID string, trigger models.TranscriptTrigger, timezone string) Command
 }
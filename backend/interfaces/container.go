@@ -5,12 +5,14 @@ import (
 	"live-chat-server/repositories"
 	"live-chat-server/storage"
 
+	"github.com/hibiken/asynq"
 	"go.uber.org/dig"
 )
 
 // JobClient defines the interface for the job client
 type JobClient interface {
 	Enqueue(jobName string, payload interface{}) error
+	EnqueueWithOptions(jobName string, payload interface{}, opts ...asynq.Option) error
 }
 
 // Container defines the methods available in our DI container
@@ -20,8 +22,11 @@ type Container interface {
 	GetUserRepo() repositories.UserRepository
 	GetCompanyRepo() repositories.CompanyRepository
 	GetConversationRepo() repositories.ConversationRepository
+	GetConversationReadRepo() repositories.ConversationReadRepository
+	GetConversationParticipantRepo() repositories.ConversationParticipantRepository
 	GetDispatcher() Dispatcher
 	GetDiskManager() storage.Manager
+	GetPrivateDiskManager() storage.PrivateManager
 	GetJobClient() JobClient
 	GetEmailService() EmailService
 	GetSecurityContext() SecurityContext
@@ -33,7 +38,9 @@ type Container interface {
 	GetDig() *dig.Container
 	GetConversationHandler() ConversationHandler
 	GetCommandFactory() CommandFactory
+	GetChannelRegistry() ChannelRegistry
 	GetNotificationService() NotificationService
 	GetPubSubService() PubSub
 	GetHealthService() HealthService
+	GetAuditService() AuditService
 }
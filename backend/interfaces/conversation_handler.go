@@ -4,4 +4,5 @@ import "live-chat-server/models"
 
 type ConversationHandler interface {
 	AssignConversation(conversation *models.Conversation, agentID string, agentName string) error
+	SendSystemMessage(conversation *models.Conversation, content string) error
 }
@@ -16,17 +16,49 @@ const (
 	EventTypeConversationTypingStop  EventType = "conversation_typing_stop"
 	EventTypeConversationAssign      EventType = "conversation_assign"
 	EventTypeConversationClose       EventType = "conversation_close"
+	EventTypeConversationResolve     EventType = "conversation_resolve"
+	EventTypeConversationReopen      EventType = "conversation_reopen"
+	EventTypeConversationPending     EventType = "conversation_pending"
+	EventTypeConversationSnooze      EventType = "conversation_snooze"
+	EventTypeConversationWake        EventType = "conversation_wake"
+	EventTypeConversationLabels      EventType = "conversation_labels_updated"
+	EventTypeConversationPriority    EventType = "conversation_priority"
+	EventTypeConversationTransfer    EventType = "conversation_transfer"
 	EventTypeConversationDeleted     EventType = "conversation_deleted"
+	EventTypeConversationRead        EventType = "conversation_read"
+	EventTypeConversationReceipt     EventType = "conversation_receipt"
+
+	EventTypeConversationParticipants EventType = "conversation_participants_updated"
+
+	// EventTypeConversationMessagesStored announces messages which were saved before being dispatched.
+	// Inbound channels use it rather than EventTypeConversationSendMessage, whose listener stores the
+	// message in the background, because they may only acknowledge a message once it is saved.
+	EventTypeConversationMessagesStored EventType = "conversation_messages_stored"
+
+	// SLA events
+	EventTypeSLAWarning  EventType = "sla_warning"
+	EventTypeSLABreached EventType = "sla_breached"
+
+	// Message events
+	EventTypeMessageCreated EventType = "message_created"
+	EventTypeMessageUpdated EventType = "message_updated"
+	EventTypeMessageDeleted EventType = "message_deleted"
 
 	// Contact events
 	EventTypeContactUpdated     EventType = "contact_updated"
 	EventTypeContactCreated     EventType = "contact_created"
 	EventTypeContactDeleted     EventType = "contact_deleted"
 	EventTypeContactNoteCreated EventType = "contact_note_created"
+	EventTypeContactMerged      EventType = "contact_merged"
+	EventTypeContactMergeUndone EventType = "contact_merge_undone"
 	// Inbox events
 	EventTypeInboxUpdated EventType = "inbox_updated"
 	EventTypeInboxCreated EventType = "inbox_created"
 	EventTypeInboxDeleted EventType = "inbox_deleted"
+	// Label events
+	EventTypeLabelCreated EventType = "label_created"
+	EventTypeLabelUpdated EventType = "label_updated"
+	EventTypeLabelDeleted EventType = "label_deleted"
 
 	// User events
 	EventTypeUserCreated   EventType = "user_created"
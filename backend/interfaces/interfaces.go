@@ -1,6 +1,7 @@
 package interfaces
 
 import (
+	"io"
 	"live-chat-server/types"
 	"mime/multipart"
 
@@ -18,5 +19,6 @@ type LanguageContext interface {
 
 type UploadService interface {
 	UploadFile(fileHeader *multipart.FileHeader, diskLocation string) (*types.UploadResult, error)
+	StoreFile(filename string, size int64, reader io.Reader, diskLocation string) (*types.UploadResult, error)
 	DeleteFile(path string) error
 }
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"live-chat-server/types"
+	"net/url"
+)
+
+// SMSProvider defines the operations of an SMS gateway used by SMS inboxes
+type SMSProvider interface {
+	// Send sends a text message and returns the provider's ID for it
+	Send(to, body string) (string, error)
+
+	// SignatureHeader returns the request header the provider signs webhooks with
+	SignatureHeader() string
+
+	// VerifySignature checks a webhook request was sent by the provider
+	VerifySignature(webhookURL string, params url.Values, signature string) bool
+
+	// ParseInbound extracts the received message from the webhook parameters
+	ParseInbound(params url.Values) (*types.InboundSMS, error)
+}
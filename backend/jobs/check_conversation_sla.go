@@ -0,0 +1,81 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// SLACheckStageWarning warns agents that a target is close to being breached
+	SLACheckStageWarning = "warning"
+	// SLACheckStageBreach records that a target was breached
+	SLACheckStageBreach = "breach"
+)
+
+// CheckConversationSLAJobPayload defines the payload for the check conversation SLA job
+type CheckConversationSLAJobPayload struct {
+	ConversationID string           `json:"conversation_id"`
+	Target         models.SLATarget `json:"target"`
+	Stage          string           `json:"stage"`
+	DueAt          time.Time        `json:"due_at"`
+}
+
+// CheckConversationSLAJob fires the SLA warning and breach events for a conversation
+type CheckConversationSLAJob struct {
+	*BaseJob
+	conversationRepo repositories.ConversationRepository
+	dispatcher       interfaces.Dispatcher
+	logger           interfaces.Logger
+}
+
+// NewCheckConversationSLAJob creates a new check conversation SLA job
+func NewCheckConversationSLAJob(conversationRepo repositories.ConversationRepository, dispatcher interfaces.Dispatcher, logger interfaces.Logger) *CheckConversationSLAJob {
+	return &CheckConversationSLAJob{
+		BaseJob:          NewBaseJob("check_conversation_sla"),
+		conversationRepo: conversationRepo,
+		dispatcher:       dispatcher,
+		logger:           logger,
+	}
+}
+
+// ProcessTask processes the check conversation SLA task
+func (j *CheckConversationSLAJob) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	var payload CheckConversationSLAJobPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %v", err)
+	}
+
+	conversation, err := j.conversationRepo.GetConversationByID(payload.ConversationID, "Inbox", "AssignedTo")
+	if err != nil {
+		return fmt.Errorf("failed to get conversation: %v", err)
+	}
+
+	// The target was met in time or has been rescheduled, so a newer job covers it
+	if conversation.IsSLATargetMet(payload.Target, payload.DueAt) {
+		return nil
+	}
+
+	eventType := interfaces.EventTypeSLAWarning
+	if payload.Stage == SLACheckStageBreach {
+		conversation.MarkSLABreached(payload.Target)
+		if err := j.conversationRepo.UpdateConversationSLA(conversation); err != nil {
+			return fmt.Errorf("failed to mark SLA breached: %v", err)
+		}
+		eventType = interfaces.EventTypeSLABreached
+	}
+
+	j.dispatcher.Dispatch(eventType, map[string]interface{}{
+		"conversation": conversation,
+		"target":       payload.Target,
+		"due_at":       payload.DueAt,
+	})
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"net/http"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// APICallbackSignatureHeader carries the HMAC-SHA256 of the callback body, keyed with the inbox's callback secret
+const APICallbackSignatureHeader = "X-Signature-256"
+
+// DeliverAPICallbackJobPayload defines the payload for the deliver API callback job
+type DeliverAPICallbackJobPayload struct {
+	InboxID string          `json:"inbox_id"`
+	Event   string          `json:"event"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// DeliverAPICallbackJob posts an event of an API inbox to its callback URL.
+// Failed deliveries are retried by the job server.
+type DeliverAPICallbackJob struct {
+	*BaseJob
+	inboxRepo  repositories.InboxRepository
+	channels   interfaces.ChannelRegistry
+	httpClient *http.Client
+	logger     interfaces.Logger
+}
+
+// NewDeliverAPICallbackJob creates a new deliver API callback job
+func NewDeliverAPICallbackJob(inboxRepo repositories.InboxRepository, channels interfaces.ChannelRegistry, logger interfaces.Logger) *DeliverAPICallbackJob {
+	return &DeliverAPICallbackJob{
+		BaseJob:    NewBaseJob("deliver_api_callback"),
+		inboxRepo:  inboxRepo,
+		channels:   channels,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		logger:     logger,
+	}
+}
+
+// ProcessTask processes the deliver API callback task
+func (j *DeliverAPICallbackJob) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	var payload DeliverAPICallbackJobPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %v", err)
+	}
+
+	inbox, err := j.inboxRepo.GetInboxByID(payload.InboxID)
+	if err != nil {
+		return fmt.Errorf("failed to get inbox: %v", err)
+	}
+
+	if inbox.Type != models.InboxTypeAPI {
+		return nil
+	}
+
+	if err := j.channels.LoadConfig(inbox); err != nil {
+		return fmt.Errorf("failed to load inbox configuration: %v", err)
+	}
+
+	config, ok := inbox.Config().(*models.InboxAPI)
+	if !ok || config.CallbackURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"event":     payload.Event,
+		"inbox_id":  inbox.ID,
+		"timestamp": time.Now().Unix(),
+		"data":      payload.Data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode callback: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create callback request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event", payload.Event)
+	req.Header.Set(APICallbackSignatureHeader, SignAPICallback(config.CallbackSecret, body))
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver %s callback to inbox %s: %v", payload.Event, inbox.ID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback for inbox %s returned status %d", inbox.ID, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// SignAPICallback returns the signature header value of a callback body
+func SignAPICallback(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
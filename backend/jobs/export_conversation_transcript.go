@@ -0,0 +1,111 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/storage"
+	"live-chat-server/transcript"
+	"live-chat-server/utils"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// Transcripts are stored by scope, only admins may download the ones including private notes
+const (
+	TranscriptScopePublic  = "public"
+	TranscriptScopePrivate = "private"
+)
+
+// TranscriptPath returns where an exported transcript is stored in the private disk
+func TranscriptPath(conversationID string, scope string, filename string) string {
+	return fmt.Sprintf("transcripts/%s/%s/%s", conversationID, scope, filename)
+}
+
+// ExportConversationTranscriptJobPayload defines the payload for the export conversation transcript job
+type ExportConversationTranscriptJobPayload struct {
+	ConversationID string            `json:"conversation_id"`
+	UserID         string            `json:"user_id"`
+	Format         transcript.Format `json:"format"`
+	Timezone       string            `json:"timezone"`
+	IncludePrivate bool              `json:"include_private"`
+}
+
+// ExportConversationTranscriptJob renders a conversation's transcript to a file and notifies the agent who asked for it
+type ExportConversationTranscriptJob struct {
+	*BaseJob
+	conversationRepo    repositories.ConversationRepository
+	userRepo            repositories.UserRepository
+	diskManager         storage.PrivateManager
+	notificationService interfaces.NotificationService
+	logger              interfaces.Logger
+}
+
+// NewExportConversationTranscriptJob creates a new export conversation transcript job
+func NewExportConversationTranscriptJob(conversationRepo repositories.ConversationRepository, userRepo repositories.UserRepository, diskManager storage.PrivateManager, notificationService interfaces.NotificationService, logger interfaces.Logger) *ExportConversationTranscriptJob {
+	return &ExportConversationTranscriptJob{
+		BaseJob:             NewBaseJob("export_conversation_transcript"),
+		conversationRepo:    conversationRepo,
+		userRepo:            userRepo,
+		diskManager:         diskManager,
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// ProcessTask processes the export conversation transcript task
+func (j *ExportConversationTranscriptJob) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	var payload ExportConversationTranscriptJobPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %v", err)
+	}
+
+	user, err := j.userRepo.GetUserByID(payload.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %v", err)
+	}
+
+	conversation, err := j.conversationRepo.GetConversationByID(payload.ConversationID, "Contact", "Inbox", "Messages")
+	if err != nil {
+		return fmt.Errorf("failed to get conversation: %v", err)
+	}
+
+	loc, err := time.LoadLocation(payload.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	// Private notes are only for admins, in case the agent's role changed since they asked
+	includePrivate := payload.IncludePrivate && (user.Role == string(models.RoleAdmin) || user.Role == string(models.RoleSuperAdmin))
+
+	content, err := transcript.New(conversation, conversation.Messages, loc, includePrivate).Render(payload.Format)
+	if err != nil {
+		return fmt.Errorf("failed to render transcript: %v", err)
+	}
+
+	scope := TranscriptScopePublic
+	if includePrivate {
+		scope = TranscriptScopePrivate
+	}
+
+	filename := utils.GenerateUniqueFilename("transcript" + payload.Format.Extension())
+	if _, err := j.diskManager.Store(TranscriptPath(conversation.ID, scope, filename), bytes.NewReader(content)); err != nil {
+		return fmt.Errorf("failed to store transcript: %v", err)
+	}
+
+	// Transcripts are downloaded through the API, which checks the agent may read them
+	if err := j.notificationService.CreateNotification(user, models.UserNotificationTypeTranscriptReady, map[string]interface{}{
+		"ActionURL":      utils.APIURL(fmt.Sprintf("/api/conversations/%s/transcripts/%s/%s", conversation.ID, scope, filename)),
+		"ConversationID": conversation.ID,
+		"Format":         string(payload.Format),
+	}); err != nil {
+		j.logger.Error("Failed to notify agent %s of transcript for conversation %s: %v", user.ID, conversation.ID, err)
+	}
+
+	return nil
+}
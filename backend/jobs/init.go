@@ -5,15 +5,20 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+
+	"github.com/hibiken/asynq"
 )
 
-// RegisterJobServer initializes and starts the job server with an email service
-func RegisterJobServer(redisAddr string, emailService interfaces.EmailService, logger interfaces.Logger) *Server {
+// RegisterJobServer initializes and starts the job server with the services from the container
+func RegisterJobServer(redisAddr string, container interfaces.Container) *Server {
+	logger := container.GetLogger()
+
 	// Initialize job server
 	jobServer := NewServer(redisAddr)
 
 	// Register job handlers
-	registerJobHandlers(jobServer, emailService, logger)
+	registerJobHandlers(jobServer, container, logger)
+	registerPeriodicJobHandlers(jobServer, container, logger)
 
 	// Handle graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -38,7 +43,9 @@ func RegisterJobServer(redisAddr string, emailService interfaces.EmailService, l
 }
 
 // registerJobHandlers registers all job handlers
-func registerJobHandlers(jobServer *Server, emailService interfaces.EmailService, logger interfaces.Logger) {
+func registerJobHandlers(jobServer *Server, container interfaces.Container, logger interfaces.Logger) {
+	emailService := container.GetEmailService()
+
 	sendInviteJob := NewSendInviteJob(emailService, logger)
 	jobServer.RegisterHandler("send_invite", sendInviteJob)
 
@@ -47,4 +54,40 @@ func registerJobHandlers(jobServer *Server, emailService interfaces.EmailService
 
 	emailJob := NewSendEmailJob(emailService, logger)
 	jobServer.RegisterHandler("send_email", emailJob)
+
+	sendEmailReplyJob := NewSendEmailReplyJob(container.GetConversationRepo(), container.GetInboxRepo(), container.GetChannelRegistry(), logger)
+	jobServer.RegisterHandler("send_email_reply", sendEmailReplyJob)
+
+	sendSMSReplyJob := NewSendSMSReplyJob(container.GetConversationRepo(), container.GetInboxRepo(), container.GetChannelRegistry(), logger)
+	jobServer.RegisterHandler("send_sms_reply", sendSMSReplyJob)
+
+	sendWhatsAppReplyJob := NewSendWhatsAppReplyJob(container.GetConversationRepo(), container.GetInboxRepo(), container.GetChannelRegistry(), logger)
+	jobServer.RegisterHandler("send_whatsapp_reply", sendWhatsAppReplyJob)
+
+	deliverAPICallbackJob := NewDeliverAPICallbackJob(container.GetInboxRepo(), container.GetChannelRegistry(), logger)
+	jobServer.RegisterHandler("deliver_api_callback", deliverAPICallbackJob)
+
+	wakeSnoozedConversationJob := NewWakeSnoozedConversationJob(container.GetConversationRepo(), container.GetCommandFactory(), container.GetNotificationService(), logger)
+	jobServer.RegisterHandler("wake_snoozed_conversation", wakeSnoozedConversationJob)
+
+	checkConversationSLAJob := NewCheckConversationSLAJob(container.GetConversationRepo(), container.GetDispatcher(), logger)
+	jobServer.RegisterHandler("check_conversation_sla", checkConversationSLAJob)
+
+	exportConversationTranscriptJob := NewExportConversationTranscriptJob(container.GetConversationRepo(), container.GetUserRepo(), container.GetPrivateDiskManager(), container.GetNotificationService(), logger)
+	jobServer.RegisterHandler("export_conversation_transcript", exportConversationTranscriptJob)
+}
+
+// registerPeriodicJobHandlers registers all job handlers that run on a schedule
+func registerPeriodicJobHandlers(jobServer *Server, container interfaces.Container, logger interfaces.Logger) {
+	pollEmailInboxesJob := NewPollEmailInboxesJob(container.GetInboxRepo(), container.GetChannelRegistry(), container.GetCommandFactory(), logger)
+	if err := jobServer.RegisterPeriodicHandler(
+		"@every "+PollEmailInboxesInterval.String(),
+		"poll_email_inboxes",
+		pollEmailInboxesJob,
+		// Skip a poll while the previous one is still queued or running
+		asynq.Unique(PollEmailInboxesInterval),
+		asynq.MaxRetry(0),
+	); err != nil {
+		logger.Error("Failed to schedule email inbox polling: %v", err)
+	}
 }
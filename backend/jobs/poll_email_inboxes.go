@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"live-chat-server/email"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// PollEmailInboxesInterval is how often email inboxes are polled for new emails
+const PollEmailInboxesInterval = time.Minute
+
+// PollEmailInboxesJob fetches new emails from every enabled email inbox over IMAP
+type PollEmailInboxesJob struct {
+	*BaseJob
+	inboxRepo      repositories.InboxRepository
+	channels       interfaces.ChannelRegistry
+	commandFactory interfaces.CommandFactory
+	logger         interfaces.Logger
+}
+
+// NewPollEmailInboxesJob creates a new poll email inboxes job
+func NewPollEmailInboxesJob(inboxRepo repositories.InboxRepository, channels interfaces.ChannelRegistry, commandFactory interfaces.CommandFactory, logger interfaces.Logger) *PollEmailInboxesJob {
+	return &PollEmailInboxesJob{
+		BaseJob:        NewBaseJob("poll_email_inboxes"),
+		inboxRepo:      inboxRepo,
+		channels:       channels,
+		commandFactory: commandFactory,
+		logger:         logger.Named("email_poller"),
+	}
+}
+
+// ProcessTask processes the poll email inboxes task
+func (j *PollEmailInboxesJob) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	inboxes, err := j.inboxRepo.GetEnabledInboxesByType(models.InboxTypeEmail)
+	if err != nil {
+		return err
+	}
+
+	for i := range inboxes {
+		inbox := &inboxes[i]
+		if err := j.channels.LoadConfig(inbox); err != nil {
+			j.logger.Error("Failed to load configuration of email inbox %s: %v", inbox.ID, err)
+			continue
+		}
+
+		config, ok := inbox.Config().(*models.InboxEmail)
+		if !ok || config.ImapServer == "" {
+			continue
+		}
+
+		receiver := email.NewIMAPReceiver(email.IMAPConfig{
+			Host:     config.ImapServer,
+			Port:     config.ImapPort,
+			Username: config.Username,
+			Password: config.Password,
+		}, j.logger)
+
+		// A failing mailbox shouldn't stop the remaining inboxes from being polled
+		err := receiver.FetchUnseen(func(inbound *types.InboundEmail) error {
+			_, err := j.commandFactory.NewHandleInboundEmailCommand(inbox, inbound).Handle()
+			return err
+		})
+		if err != nil {
+			j.logger.Error("Failed to poll email inbox %s: %v", inbox.ID, err)
+		}
+	}
+
+	return nil
+}
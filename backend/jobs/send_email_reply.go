@@ -0,0 +1,179 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"live-chat-server/email"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// SendEmailReplyJobPayload defines the payload for the send email reply job
+type SendEmailReplyJobPayload struct {
+	MessageID string `json:"message_id"`
+}
+
+// SendEmailReplyJob emails a reply in an email inbox conversation to the contact
+// through the inbox's own SMTP server
+type SendEmailReplyJob struct {
+	*BaseJob
+	conversationRepo repositories.ConversationRepository
+	inboxRepo        repositories.InboxRepository
+	channels         interfaces.ChannelRegistry
+	logger           interfaces.Logger
+}
+
+// NewSendEmailReplyJob creates a new send email reply job
+func NewSendEmailReplyJob(conversationRepo repositories.ConversationRepository, inboxRepo repositories.InboxRepository, channels interfaces.ChannelRegistry, logger interfaces.Logger) *SendEmailReplyJob {
+	return &SendEmailReplyJob{
+		BaseJob:          NewBaseJob("send_email_reply"),
+		conversationRepo: conversationRepo,
+		inboxRepo:        inboxRepo,
+		channels:         channels,
+		logger:           logger,
+	}
+}
+
+// ProcessTask processes the send email reply task
+func (j *SendEmailReplyJob) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	var payload SendEmailReplyJobPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %v", err)
+	}
+
+	message, err := j.conversationRepo.GetMessageByID(payload.MessageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message: %v", err)
+	}
+
+	// The reply has already been delivered
+	emailMetadata := message.GetEmailMetadata()
+	if emailMetadata != nil && !emailMetadata.Pending {
+		return nil
+	}
+
+	conversation, err := j.conversationRepo.GetConversationByID(message.ConversationID, "Inbox", "Contact")
+	if err != nil {
+		return fmt.Errorf("failed to get conversation: %v", err)
+	}
+
+	if conversation.Contact.Email == nil || *conversation.Contact.Email == "" {
+		j.logger.Warn("Skipping email reply for message %s, contact has no email address", message.ID)
+		return nil
+	}
+
+	inbox, err := j.inboxRepo.GetInboxByID(conversation.InboxID)
+	if err != nil {
+		return fmt.Errorf("failed to get inbox: %v", err)
+	}
+
+	if err := j.channels.LoadConfig(inbox); err != nil {
+		return fmt.Errorf("failed to load inbox configuration: %v", err)
+	}
+
+	config, ok := inbox.Config().(*models.InboxEmail)
+	if !ok || config.SmtpServer == "" {
+		return fmt.Errorf("inbox %s has no SMTP configuration", inbox.ID)
+	}
+
+	// The headers are generated and stored once, so retries send the same Message-ID
+	if emailMetadata == nil {
+		emailMetadata, err = j.replyHeaders(message, conversation, config)
+		if err != nil {
+			return err
+		}
+
+		message.SetEmailMetadata(*emailMetadata)
+		if err := j.conversationRepo.UpdateMessage(message); err != nil {
+			return fmt.Errorf("failed to store email headers: %v", err)
+		}
+	}
+
+	body := message.Content
+	if message.Type == models.MessageTypeFile || message.Type == models.MessageTypeImage {
+		body = utils.Asset(message.Content)
+	}
+
+	sender := email.NewInboxSMTPSender(interfaces.EmailConfig{
+		Host:     config.SmtpServer,
+		Port:     config.SmtpPort,
+		Username: config.Username,
+		Password: config.Password,
+		From:     config.Username,
+	}, j.logger)
+
+	if err := sender.Send(&email.OutboundEmail{
+		FromName:   inbox.Name,
+		To:         *conversation.Contact.Email,
+		Subject:    emailMetadata.Subject,
+		TextBody:   body,
+		MessageID:  emailMetadata.MessageID,
+		InReplyTo:  emailMetadata.InReplyTo,
+		References: emailMetadata.References,
+	}); err != nil {
+		return err
+	}
+
+	emailMetadata.Pending = false
+	message.SetEmailMetadata(*emailMetadata)
+	if err := j.conversationRepo.UpdateMessage(message); err != nil {
+		return fmt.Errorf("failed to mark email reply as sent: %v", err)
+	}
+
+	return nil
+}
+
+// replyHeaders generates the headers of a reply, threading it with the email it quotes
+// or the latest email in the conversation
+func (j *SendEmailReplyJob) replyHeaders(message *models.Message, conversation *models.Conversation, config *models.InboxEmail) (*types.EmailMessageMetadata, error) {
+	emailMetadata := &types.EmailMessageMetadata{
+		MessageID: email.GenerateMessageID(config.Username),
+		Subject:   email.ReplySubject(conversationSubject(conversation)),
+		From:      config.Username,
+		Pending:   true,
+	}
+
+	var threadMessage *models.Message
+	if message.ReplyTo != nil && message.ReplyTo.GetEmailMetadata() != nil {
+		threadMessage = message.ReplyTo
+	} else {
+		var err error
+		threadMessage, err = j.conversationRepo.GetLatestEmailMessage(conversation.ID, message.CreatedAt)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get thread message: %v", err)
+		}
+	}
+
+	if threadMessage != nil {
+		if threadMetadata := threadMessage.GetEmailMetadata(); threadMetadata != nil {
+			emailMetadata.InReplyTo = threadMetadata.MessageID
+			emailMetadata.References = append(append([]string{}, threadMetadata.References...), threadMetadata.MessageID)
+		}
+	}
+
+	return emailMetadata, nil
+}
+
+// conversationSubject returns the subject of the email that started the conversation
+func conversationSubject(conversation *models.Conversation) string {
+	if conversation.Metadata != nil {
+		var metadata struct {
+			Email struct {
+				Subject string `json:"subject"`
+			} `json:"email"`
+		}
+		if err := json.Unmarshal(*conversation.Metadata, &metadata); err == nil && metadata.Email.Subject != "" {
+			return metadata.Email.Subject
+		}
+	}
+
+	return conversation.Inbox.Name
+}
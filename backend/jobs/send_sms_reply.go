@@ -0,0 +1,105 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/sms"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+
+	"github.com/hibiken/asynq"
+)
+
+// SendSMSReplyJobPayload defines the payload for the send SMS reply job
+type SendSMSReplyJobPayload struct {
+	MessageID string `json:"message_id"`
+}
+
+// SendSMSReplyJob texts a reply in an SMS inbox conversation to the contact
+// through the inbox's SMS provider
+type SendSMSReplyJob struct {
+	*BaseJob
+	conversationRepo repositories.ConversationRepository
+	inboxRepo        repositories.InboxRepository
+	channels         interfaces.ChannelRegistry
+	logger           interfaces.Logger
+}
+
+// NewSendSMSReplyJob creates a new send SMS reply job
+func NewSendSMSReplyJob(conversationRepo repositories.ConversationRepository, inboxRepo repositories.InboxRepository, channels interfaces.ChannelRegistry, logger interfaces.Logger) *SendSMSReplyJob {
+	return &SendSMSReplyJob{
+		BaseJob:          NewBaseJob("send_sms_reply"),
+		conversationRepo: conversationRepo,
+		inboxRepo:        inboxRepo,
+		channels:         channels,
+		logger:           logger,
+	}
+}
+
+// ProcessTask processes the send SMS reply task
+func (j *SendSMSReplyJob) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	var payload SendSMSReplyJobPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %v", err)
+	}
+
+	message, err := j.conversationRepo.GetMessageByID(payload.MessageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message: %v", err)
+	}
+
+	// The reply has already been delivered
+	if message.GetSMSMetadata() != nil {
+		return nil
+	}
+
+	conversation, err := j.conversationRepo.GetConversationByID(message.ConversationID, "Contact")
+	if err != nil {
+		return fmt.Errorf("failed to get conversation: %v", err)
+	}
+
+	if conversation.Contact.Phone == nil || *conversation.Contact.Phone == "" {
+		j.logger.Warn("Skipping SMS reply for message %s, contact has no phone number", message.ID)
+		return nil
+	}
+
+	inbox, err := j.inboxRepo.GetInboxByID(conversation.InboxID)
+	if err != nil {
+		return fmt.Errorf("failed to get inbox: %v", err)
+	}
+
+	if err := j.channels.LoadConfig(inbox); err != nil {
+		return fmt.Errorf("failed to load inbox configuration: %v", err)
+	}
+
+	config, _ := inbox.Config().(*models.InboxSMS)
+	provider, err := sms.NewProvider(config, j.logger)
+	if err != nil {
+		return fmt.Errorf("inbox %s cannot send sms: %v", inbox.ID, err)
+	}
+
+	body := message.Content
+	if message.Type == models.MessageTypeFile || message.Type == models.MessageTypeImage {
+		body = utils.Asset(message.Content)
+	}
+
+	providerMessageID, err := provider.Send(*conversation.Contact.Phone, body)
+	if err != nil {
+		return err
+	}
+
+	message.SetSMSMetadata(types.SMSMessageMetadata{
+		MessageID: providerMessageID,
+		From:      config.PhoneNumber,
+		To:        *conversation.Contact.Phone,
+	})
+	if err := j.conversationRepo.UpdateMessage(message); err != nil {
+		j.logger.Error("Failed to store SMS details on message %s: %v", message.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+	"live-chat-server/whatsapp"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// SendWhatsAppReplyJobPayload defines the payload for the send WhatsApp reply job
+type SendWhatsAppReplyJobPayload struct {
+	MessageID string `json:"message_id"`
+}
+
+// SendWhatsAppReplyJob sends a reply in a WhatsApp inbox conversation to the contact
+// through the WhatsApp Cloud API
+type SendWhatsAppReplyJob struct {
+	*BaseJob
+	conversationRepo repositories.ConversationRepository
+	inboxRepo        repositories.InboxRepository
+	channels         interfaces.ChannelRegistry
+	logger           interfaces.Logger
+}
+
+// NewSendWhatsAppReplyJob creates a new send WhatsApp reply job
+func NewSendWhatsAppReplyJob(conversationRepo repositories.ConversationRepository, inboxRepo repositories.InboxRepository, channels interfaces.ChannelRegistry, logger interfaces.Logger) *SendWhatsAppReplyJob {
+	return &SendWhatsAppReplyJob{
+		BaseJob:          NewBaseJob("send_whatsapp_reply"),
+		conversationRepo: conversationRepo,
+		inboxRepo:        inboxRepo,
+		channels:         channels,
+		logger:           logger,
+	}
+}
+
+// ProcessTask processes the send WhatsApp reply task
+func (j *SendWhatsAppReplyJob) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	var payload SendWhatsAppReplyJobPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %v", err)
+	}
+
+	message, err := j.conversationRepo.GetMessageByID(payload.MessageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message: %v", err)
+	}
+
+	// The reply has already been delivered, or was rejected
+	if message.GetWhatsAppMetadata() != nil {
+		return nil
+	}
+
+	conversation, err := j.conversationRepo.GetConversationByID(message.ConversationID, "Contact")
+	if err != nil {
+		return fmt.Errorf("failed to get conversation: %v", err)
+	}
+
+	if conversation.Contact.Phone == nil || *conversation.Contact.Phone == "" {
+		j.logger.Warn("Skipping WhatsApp reply for message %s, contact has no phone number", message.ID)
+		return nil
+	}
+	to := *conversation.Contact.Phone
+
+	inbox, err := j.inboxRepo.GetInboxByID(conversation.InboxID)
+	if err != nil {
+		return fmt.Errorf("failed to get inbox: %v", err)
+	}
+
+	if err := j.channels.LoadConfig(inbox); err != nil {
+		return fmt.Errorf("failed to load inbox configuration: %v", err)
+	}
+
+	config, _ := inbox.Config().(*models.InboxWhatsApp)
+	client, err := whatsapp.NewClient(config, j.logger)
+	if err != nil {
+		return fmt.Errorf("inbox %s cannot send whatsapp messages: %v", inbox.ID, err)
+	}
+
+	metadata := types.WhatsAppMessageMetadata{
+		From: config.PhoneNumberID,
+		To:   to,
+	}
+
+	// Template messages are the only ones allowed outside the customer service window
+	if template := message.GetWhatsAppTemplate(); template != nil {
+		metadata.MessageID, err = client.SendTemplate(to, *template)
+		if err != nil {
+			return err
+		}
+
+		j.storeMetadata(message, metadata)
+		return nil
+	}
+
+	open, err := j.sessionWindowOpen(conversation.ID)
+	if err != nil {
+		return err
+	}
+
+	if !open {
+		j.logger.Warn("Not sending WhatsApp reply for message %s, the 24 hour session window has closed", message.ID)
+		metadata.Error = "session_window_closed"
+		j.storeMetadata(message, metadata)
+		return nil
+	}
+
+	switch message.Type {
+	case models.MessageTypeImage:
+		metadata.MessageID, err = client.SendMedia(to, "image", utils.Asset(message.Content), "")
+	case models.MessageTypeFile:
+		metadata.MessageID, err = client.SendMedia(to, "document", utils.Asset(message.Content), uploadFilename(message))
+	default:
+		metadata.MessageID, err = client.SendText(to, message.Content)
+	}
+	if err != nil {
+		return err
+	}
+
+	j.storeMetadata(message, metadata)
+	return nil
+}
+
+// sessionWindowOpen reports whether the contact has messaged the conversation within the session window
+func (j *SendWhatsAppReplyJob) sessionWindowOpen(conversationID string) (bool, error) {
+	lastContactMessage, err := j.conversationRepo.GetLatestMessageBySenderType(conversationID, models.SenderTypeContact)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get last contact message: %v", err)
+	}
+
+	return time.Since(lastContactMessage.CreatedAt) < whatsapp.SessionWindow, nil
+}
+
+// storeMetadata records the outcome of the delivery on the message
+func (j *SendWhatsAppReplyJob) storeMetadata(message *models.Message, metadata types.WhatsAppMessageMetadata) {
+	message.SetWhatsAppMetadata(metadata)
+	if err := j.conversationRepo.UpdateMessage(message); err != nil {
+		j.logger.Error("Failed to store WhatsApp details on message %s: %v", message.ID, err)
+	}
+}
+
+// uploadFilename returns the original filename of a file message
+func uploadFilename(message *models.Message) string {
+	if metadata, ok := message.Metadata.(map[string]interface{}); ok {
+		if filename, ok := metadata["filename"].(string); ok {
+			return filename
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,204 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+func TestSendWhatsAppReplySessionWindow(t *testing.T) {
+	tests := []struct {
+		name             string
+		lastContactAgo   time.Duration
+		neverWrote       bool
+		template         bool
+		wantSentType     string
+		wantMetadataErr  string
+		wantMessageIDSet bool
+	}{
+		{
+			name:             "reply inside the window",
+			lastContactAgo:   time.Hour,
+			wantSentType:     "text",
+			wantMessageIDSet: true,
+		},
+		{
+			name:            "reply after the window",
+			lastContactAgo:  25 * time.Hour,
+			wantMetadataErr: "session_window_closed",
+		},
+		{
+			name:            "contact never wrote",
+			neverWrote:      true,
+			wantMetadataErr: "session_window_closed",
+		},
+		{
+			name:             "template after the window",
+			lastContactAgo:   25 * time.Hour,
+			template:         true,
+			wantSentType:     "template",
+			wantMessageIDSet: true,
+		},
+		{
+			name:             "template for a contact who never wrote",
+			neverWrote:       true,
+			template:         true,
+			wantSentType:     "template",
+			wantMessageIDSet: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The Graph API stand-in records the type of each message sent through it
+			var sentTypes []string
+			graphAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v19.0/PN1/messages" || r.Header.Get("Authorization") != "Bearer access_token" {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"error": {"message": "unexpected request", "code": 100}}`))
+					return
+				}
+
+				var body struct {
+					To   string `json:"to"`
+					Type string `json:"type"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.To != "15551234567" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				sentTypes = append(sentTypes, body.Type)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"messaging_product": "whatsapp", "messages": [{"id": "wamid.OUT"}]}`))
+			}))
+			defer graphAPI.Close()
+
+			var metadata interface{}
+			if tt.template {
+				metadata = map[string]interface{}{
+					"whatsapp_template": map[string]interface{}{"name": "follow_up", "language": "en_US"},
+				}
+			}
+
+			conversationRepo := &whatsAppConversationRepository{
+				message: &models.Message{
+					ID:             "message-1",
+					ConversationID: "conversation-1",
+					SenderType:     models.SenderTypeAgent,
+					Type:           models.MessageTypeText,
+					Content:        "Thanks for waiting",
+					Metadata:       metadata,
+				},
+				neverWrote:    tt.neverWrote,
+				lastContactAt: time.Now().Add(-tt.lastContactAgo),
+			}
+			job := NewSendWhatsAppReplyJob(conversationRepo, whatsAppInboxRepository{}, whatsAppChannelRegistry{apiBaseURL: graphAPI.URL}, nopLogger{})
+
+			payload, _ := json.Marshal(SendWhatsAppReplyJobPayload{MessageID: "message-1"})
+			if err := job.ProcessTask(context.Background(), asynq.NewTask("send_whatsapp_reply", payload)); err != nil {
+				t.Fatalf("ProcessTask() error = %v", err)
+			}
+
+			if tt.wantSentType == "" && len(sentTypes) > 0 {
+				t.Errorf("sent %v, want nothing sent", sentTypes)
+			}
+			if tt.wantSentType != "" && (len(sentTypes) != 1 || sentTypes[0] != tt.wantSentType) {
+				t.Errorf("sent %v, want a single %s message", sentTypes, tt.wantSentType)
+			}
+
+			if conversationRepo.updated == nil {
+				t.Fatal("delivery outcome was not stored on the message")
+			}
+			delivery := conversationRepo.updated.GetWhatsAppMetadata()
+			if delivery == nil {
+				t.Fatal("stored message has no WhatsApp metadata")
+			}
+			if delivery.Error != tt.wantMetadataErr {
+				t.Errorf("delivery error = %q, want %q", delivery.Error, tt.wantMetadataErr)
+			}
+			if (delivery.MessageID == "wamid.OUT") != tt.wantMessageIDSet {
+				t.Errorf("delivery message id = %q", delivery.MessageID)
+			}
+		})
+	}
+}
+
+// whatsAppConversationRepository serves a single agent reply and the contact's last message
+type whatsAppConversationRepository struct {
+	repositories.ConversationRepository
+	message       *models.Message
+	neverWrote    bool
+	lastContactAt time.Time
+	updated       *models.Message
+}
+
+func (r *whatsAppConversationRepository) GetMessageByID(id string) (*models.Message, error) {
+	return r.message, nil
+}
+
+func (r *whatsAppConversationRepository) GetConversationByID(id string, preloads ...string) (*models.Conversation, error) {
+	phone := "+15551234567"
+	return &models.Conversation{
+		ID:        id,
+		InboxID:   "inbox-1",
+		ContactID: "contact-1",
+		Contact:   models.Contact{ID: "contact-1", Phone: &phone},
+	}, nil
+}
+
+func (r *whatsAppConversationRepository) GetLatestMessageBySenderType(conversationID string, senderType models.SenderType) (*models.Message, error) {
+	if r.neverWrote || senderType != models.SenderTypeContact {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.Message{ConversationID: conversationID, SenderType: senderType, CreatedAt: r.lastContactAt}, nil
+}
+
+func (r *whatsAppConversationRepository) UpdateMessage(message *models.Message) error {
+	r.updated = message
+	return nil
+}
+
+type whatsAppInboxRepository struct {
+	repositories.InboxRepository
+}
+
+func (whatsAppInboxRepository) GetInboxByID(id string) (*models.Inbox, error) {
+	return &models.Inbox{ID: id, Type: models.InboxTypeWhatsApp, Enabled: true}, nil
+}
+
+// whatsAppChannelRegistry configures inboxes to send through the Graph API stand-in
+type whatsAppChannelRegistry struct {
+	interfaces.ChannelRegistry
+	apiBaseURL string
+}
+
+func (r whatsAppChannelRegistry) LoadConfig(inbox *models.Inbox) error {
+	inbox.SetConfig(&models.InboxWhatsApp{
+		InboxID:       inbox.ID,
+		PhoneNumberID: "PN1",
+		AccessToken:   "access_token",
+		APIBaseURL:    r.apiBaseURL,
+	})
+	return nil
+}
+
+// nopLogger discards every log entry
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{})                  {}
+func (nopLogger) Info(msg string, args ...interface{})                   {}
+func (nopLogger) Warn(msg string, args ...interface{})                   {}
+func (nopLogger) Error(msg string, args ...interface{})                  {}
+func (nopLogger) Fatal(msg string, args ...interface{})                  {}
+func (l nopLogger) With(fields map[string]interface{}) interfaces.Logger { return l }
+func (l nopLogger) Named(name string) interfaces.Logger                  { return l }
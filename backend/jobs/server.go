@@ -10,9 +10,10 @@ import (
 
 // Server manages the job processing
 type Server struct {
-	server *asynq.Server
-	mux    *asynq.ServeMux
-	Client *Client
+	server    *asynq.Server
+	mux       *asynq.ServeMux
+	scheduler *asynq.Scheduler
+	Client    *Client
 }
 
 // NewServer creates a new job server
@@ -34,11 +35,13 @@ func NewServer(redisAddr string) *Server {
 	)
 
 	mux := asynq.NewServeMux()
+	scheduler := asynq.NewScheduler(redisOpt, nil)
 
 	return &Server{
-		server: server,
-		mux:    mux,
-		Client: NewClient(redisAddr),
+		server:    server,
+		mux:       mux,
+		scheduler: scheduler,
+		Client:    NewClient(redisAddr),
 	}
 }
 
@@ -47,8 +50,21 @@ func (s *Server) RegisterHandler(pattern string, handler JobHandler) {
 	s.mux.HandleFunc(pattern, handler.ProcessTask)
 }
 
+// RegisterPeriodicHandler registers a job handler and schedules it to run on the given cron spec
+func (s *Server) RegisterPeriodicHandler(cronspec string, pattern string, handler JobHandler, opts ...asynq.Option) error {
+	s.RegisterHandler(pattern, handler)
+
+	_, err := s.scheduler.Register(cronspec, asynq.NewTask(pattern, nil), opts...)
+	return err
+}
+
 // Start starts the job server
 func (s *Server) Start() error {
+	if err := s.scheduler.Start(); err != nil {
+		log.Printf("could not start scheduler: %v", err)
+		return err
+	}
+
 	if err := s.server.Run(s.mux); err != nil {
 		log.Printf("could not run server: %v", err)
 		return err
@@ -58,6 +74,7 @@ func (s *Server) Start() error {
 
 // Stop stops the job server
 func (s *Server) Stop() {
+	s.scheduler.Shutdown()
 	s.server.Stop()
 	s.server.Shutdown()
 }
@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/utils"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// WakeSnoozedConversationJobPayload defines the payload for the wake snoozed conversation job
+type WakeSnoozedConversationJobPayload struct {
+	ConversationID string `json:"conversation_id"`
+}
+
+// WakeSnoozedConversationJob returns a snoozed conversation to its assignee once the snooze expires
+type WakeSnoozedConversationJob struct {
+	*BaseJob
+	conversationRepo    repositories.ConversationRepository
+	commandFactory      interfaces.CommandFactory
+	notificationService interfaces.NotificationService
+	logger              interfaces.Logger
+}
+
+// NewWakeSnoozedConversationJob creates a new wake snoozed conversation job
+func NewWakeSnoozedConversationJob(conversationRepo repositories.ConversationRepository, commandFactory interfaces.CommandFactory, notificationService interfaces.NotificationService, logger interfaces.Logger) *WakeSnoozedConversationJob {
+	return &WakeSnoozedConversationJob{
+		BaseJob:             NewBaseJob("wake_snoozed_conversation"),
+		conversationRepo:    conversationRepo,
+		commandFactory:      commandFactory,
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// ProcessTask processes the wake snoozed conversation task
+func (j *WakeSnoozedConversationJob) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	var payload WakeSnoozedConversationJobPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %v", err)
+	}
+
+	conversation, err := j.conversationRepo.GetConversationByID(payload.ConversationID, "Contact", "Inbox", "AssignedTo")
+	if err != nil {
+		return fmt.Errorf("failed to get conversation: %v", err)
+	}
+
+	// The conversation was woken early or snoozed again for longer
+	if !conversation.IsSnoozed() || conversation.SnoozedUntil == nil || conversation.SnoozedUntil.After(time.Now()) {
+		return nil
+	}
+
+	if _, err := j.commandFactory.NewTransitionConversationCommand(conversation, models.ConversationTransitionWake, nil).Handle(); err != nil {
+		return fmt.Errorf("failed to wake conversation: %v", err)
+	}
+
+	if conversation.AssignedTo != nil {
+		if err := j.notificationService.CreateNotification(conversation.AssignedTo, models.UserNotificationTypeSnoozeExpired, map[string]interface{}{
+			"ActionURL":      utils.FrontendURL("/conversations/" + conversation.ID),
+			"ConversationID": conversation.ID,
+		}); err != nil {
+			j.logger.Error("Failed to notify agent %s of woken conversation %s: %v", conversation.AssignedTo.ID, conversation.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package listeners
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+
+	"go.uber.org/dig"
+)
+
+// ChannelListener hands replies visible to the contact to the channel of the conversation's inbox for delivery
+type ChannelListener struct {
+	dispatcher interfaces.Dispatcher
+	channels   interfaces.ChannelRegistry
+	inboxRepo  repositories.InboxRepository
+	logger     interfaces.Logger
+}
+
+// ChannelListenerParams contains dependencies for ChannelListener
+type ChannelListenerParams struct {
+	dig.In
+	Dispatcher interfaces.Dispatcher
+	Channels   interfaces.ChannelRegistry
+	InboxRepo  repositories.InboxRepository
+	Logger     interfaces.Logger
+}
+
+func NewChannelListener(params ChannelListenerParams) *ChannelListener {
+	listener := &ChannelListener{
+		dispatcher: params.Dispatcher,
+		channels:   params.Channels,
+		inboxRepo:  params.InboxRepo,
+		logger:     params.Logger,
+	}
+	listener.subscribe()
+	return listener
+}
+
+func (l *ChannelListener) subscribe() {
+	l.dispatcher.Subscribe(interfaces.EventTypeMessageCreated, l.HandleMessageCreated)
+}
+
+func (l *ChannelListener) HandleMessageCreated(event interfaces.Event) {
+	payload, ok := event.Payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	message, ok := payload["message"].(*models.Message)
+	if !ok {
+		return
+	}
+
+	conversation, ok := payload["conversation"].(*models.Conversation)
+	if !ok {
+		return
+	}
+
+	// Only replies visible to the contact are delivered
+	if message.Private || (message.SenderType != models.SenderTypeAgent && message.SenderType != models.SenderTypeBot) {
+		return
+	}
+
+	inboxType := conversation.Inbox.Type
+	if conversation.Inbox.ID == "" {
+		inbox, err := l.inboxRepo.GetInboxByID(conversation.InboxID)
+		if err != nil {
+			l.logger.Error("Failed to get inbox %s: %v", conversation.InboxID, err)
+			return
+		}
+		inboxType = inbox.Type
+	}
+
+	channel, ok := l.channels.Get(inboxType)
+	if !ok {
+		l.logger.Warn("No channel registered for inbox type %s", inboxType)
+		return
+	}
+
+	if err := channel.Deliver(conversation, message); err != nil {
+		l.logger.Error("Failed to deliver message %s through the %s channel: %v", message.ID, inboxType, err)
+	}
+}
@@ -3,23 +3,28 @@ package listeners
 import (
 	"live-chat-server/interfaces"
 	"live-chat-server/models"
+	"live-chat-server/repositories"
 	"live-chat-server/types"
 
 	"go.uber.org/dig"
 )
 
 type ContactListener struct {
-	dispatcher   interfaces.Dispatcher
-	pubSub       interfaces.PubSub
-	auditService interfaces.AuditService
+	dispatcher       interfaces.Dispatcher
+	pubSub           interfaces.PubSub
+	auditService     interfaces.AuditService
+	conversationRepo repositories.ConversationRepository
+	logger           interfaces.Logger
 }
 
 // ContactListenerParams contains dependencies for ContactListener
 type ContactListenerParams struct {
 	dig.In
-	Dispatcher   interfaces.Dispatcher
-	PubSub       interfaces.PubSub
-	AuditService interfaces.AuditService
+	Dispatcher       interfaces.Dispatcher
+	PubSub           interfaces.PubSub
+	AuditService     interfaces.AuditService
+	ConversationRepo repositories.ConversationRepository
+	Logger           interfaces.Logger
 }
 
 type ContactCreatedPayload struct {
@@ -36,11 +41,20 @@ type ContactNoteCreatedPayload struct {
 	Note    *models.ContactNote
 }
 
+type ContactMergedPayload struct {
+	Merge *models.ContactMerge
+	User  *models.User
+}
+
+type ContactMergeUndonePayload = ContactMergedPayload
+
 func NewContactListener(params ContactListenerParams) *ContactListener {
 	listener := &ContactListener{
-		dispatcher:   params.Dispatcher,
-		pubSub:       params.PubSub,
-		auditService: params.AuditService,
+		dispatcher:       params.Dispatcher,
+		pubSub:           params.PubSub,
+		auditService:     params.AuditService,
+		conversationRepo: params.ConversationRepo,
+		logger:           params.Logger.Named("contact_listener"),
 	}
 	listener.subscribe()
 	return listener
@@ -51,6 +65,8 @@ func (l *ContactListener) subscribe() {
 	l.dispatcher.Subscribe(interfaces.EventTypeContactUpdated, l.handleContactUpdated)
 	l.dispatcher.Subscribe(interfaces.EventTypeContactDeleted, l.handleContactDeleted)
 	l.dispatcher.Subscribe(interfaces.EventTypeContactNoteCreated, l.handleContactNoteCreated)
+	l.dispatcher.Subscribe(interfaces.EventTypeContactMerged, l.handleContactMerged)
+	l.dispatcher.Subscribe(interfaces.EventTypeContactMergeUndone, l.handleContactMergeUndone)
 }
 
 func (l *ContactListener) handleContactCreated(event interfaces.Event) {
@@ -58,7 +74,14 @@ func (l *ContactListener) handleContactCreated(event interfaces.Event) {
 		contact := payload.Contact
 		user := payload.User
 
-		l.auditService.LogUserAction(user.ID, string(models.AuditActionContactCreate), "contact", contact.ID, "Contact created", nil)
+		// Contacts created from inbound channels have no acting user
+		if user != nil {
+			l.auditService.LogUserAction(user.ID, string(models.AuditActionContactCreate), "contact", contact.ID, "Contact created", nil)
+		} else {
+			l.auditService.LogSystemEvent(string(models.AuditActionContactCreate), "contact", "Contact created", map[string]interface{}{
+				"contact_id": contact.ID,
+			})
+		}
 
 		// Broadcast to company channel
 		l.pubSub.Publish("company:"+contact.CompanyID, types.EventTypeContactCreated, contact.ToPayload())
@@ -98,3 +121,60 @@ func (l *ContactListener) handleContactNoteCreated(event interfaces.Event) {
 		l.pubSub.Publish("contact:"+contact.ID, types.EventTypeContactNoteCreated, note.ToPayload())
 	}
 }
+
+func (l *ContactListener) handleContactMerged(event interfaces.Event) {
+	if payload, ok := event.Payload.(*ContactMergedPayload); ok {
+		merge := payload.Merge
+		primary := merge.PrimaryContact
+		secondary := merge.SecondaryContact
+
+		l.auditService.LogUserAction(payload.User.ID, string(models.AuditActionContactMerge), "contact", primary.ID, "Contact merged", map[string]interface{}{
+			"merge_id":             merge.ID,
+			"secondary_contact_id": secondary.ID,
+			"conversation_ids":     merge.ConversationIDs,
+			"note_ids":             merge.NoteIDs,
+			"filled_fields":        merge.FilledFields,
+		})
+
+		l.pubSub.Publish("company:"+merge.CompanyID, types.EventTypeContactMerged, merge.ToResponse())
+		l.pubSub.Publish("company:"+merge.CompanyID, types.EventTypeContactDeleted, secondary.ToPayload())
+		l.pubSub.Publish("company:"+merge.CompanyID, types.EventTypeContactUpdated, primary.ToPayload())
+
+		// Let a widget still connected as the secondary contact know which contact it now is
+		l.pubSub.Publish("contact:"+secondary.ID, types.EventTypeContactMerged, merge.ToResponse())
+
+		l.publishConversationUpdates(merge.ConversationIDs)
+	}
+}
+
+func (l *ContactListener) handleContactMergeUndone(event interfaces.Event) {
+	if payload, ok := event.Payload.(*ContactMergeUndonePayload); ok {
+		merge := payload.Merge
+		primary := merge.PrimaryContact
+		secondary := merge.SecondaryContact
+
+		l.auditService.LogUserAction(payload.User.ID, string(models.AuditActionContactMergeUndo), "contact", primary.ID, "Contact merge undone", map[string]interface{}{
+			"merge_id":             merge.ID,
+			"secondary_contact_id": secondary.ID,
+		})
+
+		l.pubSub.Publish("company:"+merge.CompanyID, types.EventTypeContactMergeUndone, merge.ToResponse())
+		l.pubSub.Publish("company:"+merge.CompanyID, types.EventTypeContactCreated, secondary.ToPayload())
+		l.pubSub.Publish("company:"+merge.CompanyID, types.EventTypeContactUpdated, primary.ToPayload())
+
+		l.publishConversationUpdates(merge.ConversationIDs)
+	}
+}
+
+// publishConversationUpdates broadcasts the conversations whose contact changed
+func (l *ContactListener) publishConversationUpdates(conversationIDs []string) {
+	for _, conversationID := range conversationIDs {
+		conversation, err := l.conversationRepo.GetConversationByID(conversationID, "Contact", "Inbox", "AssignedTo", "Labels")
+		if err != nil {
+			l.logger.Error("Failed to get conversation %s: %v", conversationID, err)
+			continue
+		}
+
+		l.pubSub.Publish("conversation:"+conversation.ID, types.EventTypeConversationUpdate, conversation.ToPayloadWithoutMessages())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"live-chat-server/models"
 	"live-chat-server/repositories"
 	"live-chat-server/types"
+	"live-chat-server/utils"
 	"time"
 
 	"go.uber.org/dig"
@@ -22,6 +23,39 @@ type InternalMessagePayload struct {
 		ID   string
 		Type types.SenderType
 	}
+
+	// ReplyToMessageID is the earlier message of the conversation being quoted, if any
+	ReplyToMessageID *string
+}
+
+// ConversationTransferPayload is dispatched once a conversation has moved to another inbox
+type ConversationTransferPayload struct {
+	Conversation *models.Conversation
+	FromInbox    *models.Inbox
+	// AgentIDs are the members of the inbox the conversation moved to
+	AgentIDs []string
+}
+
+// MessageChangedPayload is dispatched when the sender of a message edits or deletes it
+type MessageChangedPayload struct {
+	Message      *models.Message
+	Conversation *models.Conversation
+}
+
+// MessagesStoredPayload is dispatched once messages have been saved outside of the listener,
+// such as the messages received by an email, SMS, WhatsApp or API inbox, so they are announced
+// in the order they were stored
+type MessagesStoredPayload struct {
+	Conversation *models.Conversation
+	Messages     []*models.Message
+}
+
+// ConversationReceiptPayload is dispatched when a reader's delivered or read pointer has moved
+type ConversationReceiptPayload struct {
+	Conversation *models.Conversation
+	Read         *models.ConversationRead
+	// Message is the message the pointer moved to
+	Message *models.Message
 }
 
 type ConversationListener struct {
@@ -66,17 +100,28 @@ func NewConversationListener(params ConversationListenerParams) *ConversationLis
 func (l *ConversationListener) subscribe() {
 	l.dispatcher.Subscribe(interfaces.EventTypeConversationStart, l.HandleConversationStart)
 	l.dispatcher.Subscribe(interfaces.EventTypeConversationSendMessage, l.HandleConversationSendMessage)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationMessagesStored, l.HandleConversationMessagesStored)
 	l.dispatcher.Subscribe(interfaces.EventTypeConversationGetByID, l.HandleConversationGetByID)
 	l.dispatcher.Subscribe(interfaces.EventTypeConversationTyping, l.HandleConversationTyping)
 	l.dispatcher.Subscribe(interfaces.EventTypeConversationTypingStop, l.HandleConversationTypingStop)
 	l.dispatcher.Subscribe(interfaces.EventTypeConversationAssign, l.HandleConversationAssign)
 	l.dispatcher.Subscribe(interfaces.EventTypeConversationClose, l.HandleConversationClose)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationResolve, l.HandleConversationStatusChange)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationReopen, l.HandleConversationStatusChange)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationPending, l.HandleConversationStatusChange)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationSnooze, l.HandleConversationStatusChange)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationWake, l.HandleConversationStatusChange)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationPriority, l.HandleConversationPriority)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationTransfer, l.HandleConversationTransfer)
+	l.dispatcher.Subscribe(interfaces.EventTypeMessageUpdated, l.HandleMessageChanged)
+	l.dispatcher.Subscribe(interfaces.EventTypeMessageDeleted, l.HandleMessageChanged)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationReceipt, l.HandleConversationReceipt)
 }
 
 func (l *ConversationListener) HandleConversationStart(event interfaces.Event) {
 	if conversation, ok := event.Payload.(*models.Conversation); ok {
 		l.pubSub.Publish("company:"+conversation.CompanyID, types.EventTypeConversationStart, conversation.ToPayloadWithoutMessages())
-		l.pubSub.Publish("conversation:"+conversation.ID, types.EventTypeConversationStart, conversation.ToPayloadWithoutMessages())
+		l.publishToConversation(conversation, types.EventTypeConversationStart)
 	}
 }
 
@@ -114,6 +159,8 @@ func (l *ConversationListener) HandleConversationSendMessage(event interfaces.Ev
 			SenderType:     models.SenderType(internalMessage.Sender.Type),
 			Metadata:       metaData,
 			Private:        internalMessage.Private,
+
+			ReplyToMessageID: internalMessage.ReplyToMessageID,
 		}
 
 		// Add sender ID for non-system messages
@@ -137,37 +184,74 @@ func (l *ConversationListener) HandleConversationSendMessage(event interfaces.Ev
 			l.logger.Error("Error updating conversation:", err)
 		}
 
-		// Populate sender information if available
-		populatedMessage, err := l.conversationRepo.PopulateSender(createdMessage)
-		if err != nil {
-			l.logger.Error("Error populating sender:", err)
-			// Continue with unpopulated message if there's an error
-			populatedMessage = createdMessage
-		}
+		l.announceMessage(conversation, createdMessage)
+	}
+}
+
+// HandleConversationMessagesStored announces messages which were saved before being dispatched
+func (l *ConversationListener) HandleConversationMessagesStored(event interfaces.Event) {
+	payload, ok := event.Payload.(*MessagesStoredPayload)
+	if !ok {
+		return
+	}
 
-		l.commandFactory.NewHandleMessageNotificationCommand(conversation, populatedMessage).Handle()
+	for _, message := range payload.Messages {
+		l.announceMessage(payload.Conversation, message)
+	}
+}
 
-		if populatedMessage.SenderType == models.SenderTypeAgent {
-			l.auditService.LogUserAction(
-				*populatedMessage.SenderID,
-				string(models.AuditActionMessageSend),
-				"message",
-				populatedMessage.ID,
-				fmt.Sprintf("Sent message: %s", populatedMessage.Content),
-				nil,
-			)
+// announceMessage handles a stored message: it brings the conversation back to an agent when the
+// contact replies, notifies the agents, delivers the message to its channel and broadcasts it
+func (l *ConversationListener) announceMessage(conversation *models.Conversation, createdMessage *models.Message) {
+	// A contact replying to a resolved or snoozed conversation needs an agent again
+	if createdMessage.SenderType == models.SenderTypeContact && (conversation.IsResolved() || conversation.IsSnoozed()) {
+		transition := models.ConversationTransitionReopen
+		if conversation.IsSnoozed() {
+			transition = models.ConversationTransitionWake
 		}
 
-		// If the message is private, then only broadcast the message to agent conversation
-		if internalMessage.Private {
-			l.pubSub.Publish("conversation-agent:"+internalMessage.ConversationID, types.EventTypeConversationSendMessage, populatedMessage.ToPayload())
-		} else {
-			// Broadcast the message to the conversation channel
-			l.pubSub.Publish("conversation:"+internalMessage.ConversationID, types.EventTypeConversationSendMessage, populatedMessage.ToPayload())
+		if _, err := l.commandFactory.NewTransitionConversationCommand(conversation, transition, nil).Handle(); err != nil {
+			l.logger.Error("Failed to %s conversation %s: %v", transition, conversation.ID, err)
 		}
+	}
 
-		l.pubSub.Publish("company:"+conversation.CompanyID, types.EventTypeConversationUpdate, conversation.ToPayloadWithoutMessages())
+	// Populate sender information if available
+	populatedMessage, err := l.conversationRepo.PopulateSender(createdMessage)
+	if err != nil {
+		l.logger.Error("Error populating sender:", err)
+		// Continue with unpopulated message if there's an error
+		populatedMessage = createdMessage
+	}
+
+	l.commandFactory.NewHandleMessageNotificationCommand(conversation, populatedMessage).Handle()
+
+	// Let channel listeners deliver the message outside of the platform
+	l.dispatcher.Dispatch(interfaces.EventTypeMessageCreated, map[string]interface{}{
+		"message":      populatedMessage,
+		"conversation": conversation,
+	})
+
+	if populatedMessage.SenderType == models.SenderTypeAgent {
+		l.auditService.LogUserAction(
+			*populatedMessage.SenderID,
+			string(models.AuditActionMessageSend),
+			"message",
+			populatedMessage.ID,
+			fmt.Sprintf("Sent message: %s", populatedMessage.Content),
+			nil,
+		)
 	}
+
+	// If the message is private, then only broadcast the message to agent conversation
+	topic := "conversation:" + conversation.ID
+	if createdMessage.Private {
+		topic = "conversation-agent:" + conversation.ID
+	}
+	l.pubSub.Publish(topic, types.EventTypeConversationSendMessage, populatedMessage.ToPayload())
+
+	l.pubSub.Publish("company:"+conversation.CompanyID, types.EventTypeConversationUpdate, conversation.ToPayloadWithoutMessages())
+
+	l.recordMessageReceipts(conversation, populatedMessage, topic)
 }
 
 func (l *ConversationListener) HandleConversationGetByID(event interfaces.Event) {
@@ -203,8 +287,8 @@ func (l *ConversationListener) HandleConversationAssign(event interfaces.Event)
 		// Broadcast assignment to company channel
 		l.pubSub.Publish("company:"+conversation.CompanyID, types.EventTypeConversationUpdate, conversation.ToPayloadWithoutMessages())
 
-		// Also broadcast to the conversation channel
-		l.pubSub.Publish("conversation:"+conversation.ID, types.EventTypeConversationUpdate, conversation.ToPayloadWithoutMessages())
+		// Also broadcast to the conversation channels
+		l.publishToConversation(conversation, types.EventTypeConversationUpdate)
 	}
 
 }
@@ -212,6 +296,174 @@ func (l *ConversationListener) HandleConversationAssign(event interfaces.Event)
 func (l *ConversationListener) HandleConversationClose(event interfaces.Event) {
 	if conversation, ok := event.Payload.(*models.Conversation); ok {
 		l.pubSub.Publish("company:"+conversation.CompanyID, types.EventTypeConversationClose, conversation.ToPayloadWithoutMessages())
-		l.pubSub.Publish("conversation:"+conversation.ID, types.EventTypeConversationClose, conversation.ToPayloadWithoutMessages())
+		l.publishToConversation(conversation, types.EventTypeConversationClose)
+
+		// Give the contact a copy of the chat when the inbox is set up to send one
+		if conversation.Inbox.SendTranscriptOnClose && utils.GetStringValue(conversation.Contact.Email) != "" {
+			if _, err := l.commandFactory.NewSendConversationTranscriptCommand(conversation.ID, models.TranscriptTriggerClose, "").Handle(); err != nil {
+				l.logger.Error("Failed to send transcript of closed conversation %s: %v", conversation.ID, err)
+			}
+		}
+	}
+}
+
+func (l *ConversationListener) HandleConversationStatusChange(event interfaces.Event) {
+	if conversation, ok := event.Payload.(*models.Conversation); ok {
+		l.pubSub.Publish("company:"+conversation.CompanyID, types.EventTypeConversationUpdate, conversation.ToPayloadWithoutMessages())
+		l.publishToConversation(conversation, types.EventTypeConversationUpdate)
+	}
+}
+
+func (l *ConversationListener) HandleConversationPriority(event interfaces.Event) {
+	if conversation, ok := event.Payload.(*models.Conversation); ok {
+		l.pubSub.Publish("company:"+conversation.CompanyID, types.EventTypeConversationPriority, conversation.ToPayloadWithoutMessages())
+		l.publishToConversation(conversation, types.EventTypeConversationUpdate)
+	}
+}
+
+func (l *ConversationListener) HandleConversationTransfer(event interfaces.Event) {
+	payload, ok := event.Payload.(*ConversationTransferPayload)
+	if !ok {
+		return
+	}
+
+	conversation := payload.Conversation
+	transferPayload := &types.OutgoingConversationTransferPayload{
+		ConversationID: conversation.ID,
+		FromInboxID:    payload.FromInbox.ID,
+		ToInboxID:      conversation.InboxID,
+		Conversation:   conversation.ToPayloadWithoutMessages(),
+	}
+
+	l.pubSub.Publish("company:"+conversation.CompanyID, types.EventTypeConversationTransfer, transferPayload)
+
+	// Agents outside the new inbox lose access, so they stop receiving the conversation's messages
+	for _, topic := range []string{"conversation:" + conversation.ID, "conversation-agent:" + conversation.ID} {
+		for _, client := range l.pubSub.GetSubscribers(topic) {
+			if !client.IsAgent() || utils.Contains(payload.AgentIDs, client.GetID()) {
+				continue
+			}
+
+			l.pubSub.Unsubscribe(client, topic)
+		}
+	}
+
+	l.publishToConversation(conversation, types.EventTypeConversationUpdate)
+}
+
+// HandleMessageChanged broadcasts an edited or deleted message to the clients showing the conversation
+func (l *ConversationListener) HandleMessageChanged(event interfaces.Event) {
+	payload, ok := event.Payload.(*MessageChangedPayload)
+	if !ok {
+		return
+	}
+
+	message, err := l.conversationRepo.PopulateSender(payload.Message)
+	if err != nil {
+		l.logger.Error("Failed to populate sender of message %s: %v", payload.Message.ID, err)
+		message = payload.Message
+	}
+
+	eventType := types.EventTypeMessageUpdated
+	if event.Type == interfaces.EventTypeMessageDeleted {
+		eventType = types.EventTypeMessageDeleted
 	}
+
+	// Private notes are only ever shown to agents
+	if message.Private {
+		l.pubSub.Publish("conversation-agent:"+message.ConversationID, eventType, message.ToPayload())
+	} else {
+		l.pubSub.Publish("conversation:"+message.ConversationID, eventType, message.ToPayload())
+	}
+
+	conversation := payload.Conversation
+	l.pubSub.Publish("company:"+conversation.CompanyID, types.EventTypeConversationUpdate, conversation.ToPayloadWithoutMessages())
+}
+
+// recordMessageReceipts marks a new message as read by its sender and delivered to every client it was published to
+func (l *ConversationListener) recordMessageReceipts(conversation *models.Conversation, message *models.Message, topic string) {
+	if message.SenderID != nil && (message.SenderType == models.SenderTypeAgent || message.SenderType == models.SenderTypeContact) {
+		if _, err := l.commandFactory.NewRecordReceiptCommand(conversation, models.ReceiptTypeRead, message.SenderType, *message.SenderID, message.ID).Handle(); err != nil {
+			l.logger.Error("Failed to record read receipt of message %s: %v", message.ID, err)
+		}
+	}
+
+	delivered := make(map[string]bool)
+	for _, client := range l.pubSub.GetSubscribers(topic) {
+		readerType := models.SenderType(client.GetType())
+		if readerType != models.SenderTypeAgent && readerType != models.SenderTypeContact {
+			continue
+		}
+
+		// A reader can be connected more than once
+		key := string(readerType) + ":" + client.GetID()
+		if delivered[key] || (message.SenderID != nil && readerType == message.SenderType && client.GetID() == *message.SenderID) {
+			continue
+		}
+		delivered[key] = true
+
+		if _, err := l.commandFactory.NewRecordReceiptCommand(conversation, models.ReceiptTypeDelivered, readerType, client.GetID(), message.ID).Handle(); err != nil {
+			l.logger.Error("Failed to record delivery receipt of message %s: %v", message.ID, err)
+		}
+	}
+}
+
+// HandleConversationReceipt tells the clients showing the conversation how far a reader has got.
+// Receipts pointing at a private note are only shown to agents.
+func (l *ConversationListener) HandleConversationReceipt(event interfaces.Event) {
+	payload, ok := event.Payload.(*ConversationReceiptPayload)
+	if !ok {
+		return
+	}
+
+	receipt := payload.Read.ToPayload()
+	if payload.Message.Private {
+		l.pubSub.Publish("conversation-agent:"+payload.Conversation.ID, types.EventTypeConversationReceipt, receipt)
+		return
+	}
+
+	// The other pointer may still be on a private note the contact must not learn about
+	if receipt.LastDeliveredMessageID != "" && receipt.LastDeliveredMessageID != payload.Message.ID && l.isPrivateMessage(receipt.LastDeliveredMessageID) {
+		receipt.LastDeliveredMessageID = ""
+		receipt.LastDeliveredAt = ""
+	}
+	if receipt.LastReadMessageID != "" && receipt.LastReadMessageID != payload.Message.ID && l.isPrivateMessage(receipt.LastReadMessageID) {
+		receipt.LastReadMessageID = ""
+		receipt.LastReadAt = ""
+	}
+
+	l.pubSub.Publish("conversation:"+payload.Conversation.ID, types.EventTypeConversationReceipt, receipt)
+}
+
+// publishToConversation sends the conversation to the clients showing it.
+// Agents get the full payload, the contact only what they may see.
+func (l *ConversationListener) publishToConversation(conversation *models.Conversation, event types.EventType) {
+	l.pubSub.Publish("conversation-agent:"+conversation.ID, event, conversation.ToPayloadWithoutMessages())
+	l.pubSub.Publish("conversation:"+conversation.ID, event, l.contactPayload(conversation))
+}
+
+// contactPayload returns the conversation without who follows it or receipts pointing at private notes
+func (l *ConversationListener) contactPayload(conversation *models.Conversation) *types.ConversationPayload {
+	payload := conversation.ToPayloadWithoutMessages()
+
+	// Who follows the conversation is only for agents
+	payload.Participants = nil
+
+	for i, receipt := range payload.Receipts {
+		if receipt.LastDeliveredMessageID != "" && l.isPrivateMessage(receipt.LastDeliveredMessageID) {
+			payload.Receipts[i].LastDeliveredMessageID = ""
+			payload.Receipts[i].LastDeliveredAt = ""
+		}
+		if receipt.LastReadMessageID != "" && l.isPrivateMessage(receipt.LastReadMessageID) {
+			payload.Receipts[i].LastReadMessageID = ""
+			payload.Receipts[i].LastReadAt = ""
+		}
+	}
+
+	return payload
+}
+
+func (l *ConversationListener) isPrivateMessage(messageID string) bool {
+	message, err := l.conversationRepo.GetMessageByID(messageID)
+	return err != nil || message.Private
 }
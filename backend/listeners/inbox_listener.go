@@ -13,6 +13,7 @@ type InboxListener struct {
 	dispatcher   interfaces.Dispatcher
 	pubSub       interfaces.PubSub
 	auditService services.AuditService
+	channels     interfaces.ChannelRegistry
 }
 
 type InboxPayload struct {
@@ -32,6 +33,7 @@ type InboxListenerParams struct {
 	Dispatcher   interfaces.Dispatcher
 	PubSub       interfaces.PubSub
 	AuditService services.AuditService
+	Channels     interfaces.ChannelRegistry
 }
 
 func NewInboxListener(params InboxListenerParams) *InboxListener {
@@ -39,6 +41,7 @@ func NewInboxListener(params InboxListenerParams) *InboxListener {
 		dispatcher:   params.Dispatcher,
 		pubSub:       params.PubSub,
 		auditService: params.AuditService,
+		channels:     params.Channels,
 	}
 	listener.subscribe()
 	return listener
@@ -53,7 +56,7 @@ func (l *InboxListener) subscribe() {
 func (l *InboxListener) HandleInboxCreated(event interfaces.Event) {
 	if inbox, ok := event.Payload.(InboxPayload); ok {
 		// Broadcast to company channel
-		l.pubSub.Publish("company:"+inbox.Inbox.CompanyID, types.EventTypeInboxCreated, inbox.Inbox.ToPayload())
+		l.pubSub.Publish("company:"+inbox.Inbox.CompanyID, types.EventTypeInboxCreated, l.channels.InboxPayload(inbox.Inbox, false))
 
 		l.auditService.LogUserAction(
 			inbox.User.ID,
@@ -73,7 +76,7 @@ func (l *InboxListener) HandleInboxUpdated(event interfaces.Event) {
 		user := payload.User
 
 		// Broadcast to company channel
-		l.pubSub.Publish("company:"+inbox.CompanyID, types.EventTypeInboxUpdated, inbox.ToPayload())
+		l.pubSub.Publish("company:"+inbox.CompanyID, types.EventTypeInboxUpdated, l.channels.InboxPayload(inbox, false))
 
 		l.auditService.LogUserAction(
 			user.ID,
@@ -0,0 +1,127 @@
+package listeners
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/types"
+
+	"go.uber.org/dig"
+)
+
+type LabelListener struct {
+	dispatcher   interfaces.Dispatcher
+	pubSub       interfaces.PubSub
+	auditService interfaces.AuditService
+}
+
+type LabelPayload struct {
+	Label *models.Label
+	User  *models.User
+}
+
+type LabelDeletedPayload struct {
+	Label           *models.Label
+	User            *models.User
+	ConversationIDs []string
+}
+
+type ConversationLabelsPayload struct {
+	Conversation *models.Conversation
+	User         *models.User
+}
+
+// LabelListenerParams contains dependencies for LabelListener
+type LabelListenerParams struct {
+	dig.In
+	Dispatcher   interfaces.Dispatcher
+	PubSub       interfaces.PubSub
+	AuditService interfaces.AuditService
+}
+
+func NewLabelListener(params LabelListenerParams) *LabelListener {
+	listener := &LabelListener{
+		dispatcher:   params.Dispatcher,
+		pubSub:       params.PubSub,
+		auditService: params.AuditService,
+	}
+	listener.subscribe()
+	return listener
+}
+
+func (l *LabelListener) subscribe() {
+	l.dispatcher.Subscribe(interfaces.EventTypeLabelCreated, l.HandleLabelCreated)
+	l.dispatcher.Subscribe(interfaces.EventTypeLabelUpdated, l.HandleLabelUpdated)
+	l.dispatcher.Subscribe(interfaces.EventTypeLabelDeleted, l.HandleLabelDeleted)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationLabels, l.HandleConversationLabels)
+}
+
+func (l *LabelListener) HandleLabelCreated(event interfaces.Event) {
+	if payload, ok := event.Payload.(*LabelPayload); ok {
+		l.pubSub.Publish("company:"+payload.Label.CompanyID, types.EventTypeLabelCreated, payload.Label.ToPayload())
+
+		l.auditService.LogUserAction(
+			payload.User.ID,
+			string(models.AuditActionLabelCreate),
+			"label",
+			payload.Label.ID,
+			"Label created",
+			nil,
+		)
+	}
+}
+
+func (l *LabelListener) HandleLabelUpdated(event interfaces.Event) {
+	if payload, ok := event.Payload.(*LabelPayload); ok {
+		l.pubSub.Publish("company:"+payload.Label.CompanyID, types.EventTypeLabelUpdated, payload.Label.ToPayload())
+
+		l.auditService.LogUserAction(
+			payload.User.ID,
+			string(models.AuditActionLabelUpdate),
+			"label",
+			payload.Label.ID,
+			"Label updated",
+			nil,
+		)
+	}
+}
+
+func (l *LabelListener) HandleLabelDeleted(event interfaces.Event) {
+	if payload, ok := event.Payload.(*LabelDeletedPayload); ok {
+		data := map[string]interface{}{
+			"label_id": payload.Label.ID,
+		}
+
+		l.pubSub.Publish("company:"+payload.Label.CompanyID, types.EventTypeLabelDeleted, data)
+
+		// Conversations which had the label drop it as well
+		for _, conversationID := range payload.ConversationIDs {
+			l.pubSub.Publish("conversation:"+conversationID, types.EventTypeLabelDeleted, data)
+		}
+
+		l.auditService.LogUserAction(
+			payload.User.ID,
+			string(models.AuditActionLabelDelete),
+			"label",
+			payload.Label.ID,
+			"Label deleted",
+			nil,
+		)
+	}
+}
+
+func (l *LabelListener) HandleConversationLabels(event interfaces.Event) {
+	if payload, ok := event.Payload.(*ConversationLabelsPayload); ok {
+		conversation := payload.Conversation
+		data := map[string]interface{}{
+			"conversation_id": conversation.ID,
+			"labels":          models.LabelsToPayload(conversation.Labels),
+		}
+
+		l.pubSub.Publish("company:"+conversation.CompanyID, types.EventTypeConversationLabels, data)
+		l.pubSub.Publish("conversation:"+conversation.ID, types.EventTypeConversationLabels, data)
+
+		l.auditService.LogConversationAction(payload.User.ID, conversation.ID, string(models.AuditActionConversationUpdate), map[string]interface{}{
+			"labels": data["labels"],
+		})
+	}
+}
@@ -30,7 +30,32 @@ func RegisterListeners(container *dig.Container) {
 
 	// Register the auth listener
 	if err := container.Provide(NewAuthListener); err != nil {
-		log.Fatal("Failed to provide auth listener: %v", err)
+		log.Fatalf("Failed to provide auth listener: %v", err)
+	}
+
+	// Register the channel listener
+	if err := container.Provide(NewChannelListener); err != nil {
+		log.Fatalf("Failed to provide channel listener: %v", err)
+	}
+
+	// Register the label listener
+	if err := container.Provide(NewLabelListener); err != nil {
+		log.Fatalf("Failed to provide label listener: %v", err)
+	}
+
+	// Register the SLA listener
+	if err := container.Provide(NewSLAListener); err != nil {
+		log.Fatalf("Failed to provide SLA listener: %v", err)
+	}
+
+	// Register the saved view listener
+	if err := container.Provide(NewSavedViewListener); err != nil {
+		log.Fatalf("Failed to provide saved view listener: %v", err)
+	}
+
+	// Register the participant listener
+	if err := container.Provide(NewParticipantListener); err != nil {
+		log.Fatalf("Failed to provide participant listener: %v", err)
 	}
 
 	// Instantiate the listeners to ensure they're created and subscribed
@@ -40,6 +65,11 @@ func RegisterListeners(container *dig.Container) {
 		inboxListener *InboxListener,
 		userListener *UserListener,
 		authListener *AuthListener,
+		channelListener *ChannelListener,
+		labelListener *LabelListener,
+		slaListener *SLAListener,
+		savedViewListener *SavedViewListener,
+		participantListener *ParticipantListener,
 	) {
 	}); err != nil {
 		log.Fatalf("Failed to instantiate listeners: %v", err)
@@ -0,0 +1,120 @@
+package listeners
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+
+	"go.uber.org/dig"
+)
+
+// ParticipantListener keeps the agents following or collaborating on a conversation up to date
+type ParticipantListener struct {
+	dispatcher          interfaces.Dispatcher
+	pubSub              interfaces.PubSub
+	participantRepo     repositories.ConversationParticipantRepository
+	notificationService interfaces.NotificationService
+	logger              interfaces.Logger
+}
+
+type ConversationParticipantsPayload struct {
+	Conversation *models.Conversation
+	Participant  *models.ConversationParticipant
+	// User is the agent who added or removed the participant
+	User    *models.User
+	Removed bool
+}
+
+// ParticipantListenerParams contains dependencies for ParticipantListener
+type ParticipantListenerParams struct {
+	dig.In
+	Dispatcher          interfaces.Dispatcher
+	PubSub              interfaces.PubSub
+	ParticipantRepo     repositories.ConversationParticipantRepository
+	NotificationService interfaces.NotificationService
+	Logger              interfaces.Logger
+}
+
+func NewParticipantListener(params ParticipantListenerParams) *ParticipantListener {
+	listener := &ParticipantListener{
+		dispatcher:          params.Dispatcher,
+		pubSub:              params.PubSub,
+		participantRepo:     params.ParticipantRepo,
+		notificationService: params.NotificationService,
+		logger:              params.Logger,
+	}
+	listener.subscribe()
+	return listener
+}
+
+func (l *ParticipantListener) subscribe() {
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationParticipants, l.HandleConversationParticipants)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationClose, l.HandleConversationStatusChange)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationResolve, l.HandleConversationStatusChange)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationReopen, l.HandleConversationStatusChange)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationPending, l.HandleConversationStatusChange)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationSnooze, l.HandleConversationStatusChange)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationWake, l.HandleConversationStatusChange)
+}
+
+// HandleConversationParticipants broadcasts the participants to the agents and lets an agent know they were added
+func (l *ParticipantListener) HandleConversationParticipants(event interfaces.Event) {
+	payload, ok := event.Payload.(*ConversationParticipantsPayload)
+	if !ok {
+		return
+	}
+
+	conversation := payload.Conversation
+	data := &types.OutgoingConversationParticipantsPayload{
+		ConversationID: conversation.ID,
+		Participants:   models.ParticipantsToPayload(conversation.Participants),
+	}
+	l.pubSub.Publish("company:"+conversation.CompanyID, types.EventTypeConversationParticipants, data)
+	l.pubSub.Publish("conversation-agent:"+conversation.ID, types.EventTypeConversationParticipants, data)
+
+	if payload.Removed || payload.Participant.UserID == payload.User.ID {
+		return
+	}
+
+	// Reloaded for the agent's notification settings
+	participant, err := l.participantRepo.GetParticipant(conversation.ID, payload.Participant.UserID)
+	if err != nil {
+		l.logger.Error("Failed to get participant %s of conversation %s: %v", payload.Participant.UserID, conversation.ID, err)
+		return
+	}
+
+	if err := l.notificationService.CreateNotification(participant.User, models.UserNotificationTypeParticipantAdded, map[string]interface{}{
+		"conversation_id": conversation.ID,
+		"role":            string(participant.Role),
+	}); err != nil {
+		l.logger.Error("Failed to notify participant %s of conversation %s: %v", participant.UserID, conversation.ID, err)
+	}
+}
+
+// HandleConversationStatusChange lets the participants know the conversation changed status, the assignee already follows it
+func (l *ParticipantListener) HandleConversationStatusChange(event interfaces.Event) {
+	conversation, ok := event.Payload.(*models.Conversation)
+	if !ok {
+		return
+	}
+
+	participants, err := l.participantRepo.GetParticipants(conversation.ID)
+	if err != nil {
+		l.logger.Error("Failed to get participants of conversation %s: %v", conversation.ID, err)
+		return
+	}
+
+	for _, participant := range participants {
+		if participant.User == nil || (conversation.AssignedToID != nil && *conversation.AssignedToID == participant.UserID) {
+			continue
+		}
+
+		if err := l.notificationService.CreateNotification(participant.User, models.UserNotificationTypeFollowedStatus, map[string]interface{}{
+			"conversation_id": conversation.ID,
+			"status":          string(conversation.Status),
+		}); err != nil {
+			l.logger.Error("Failed to notify participant %s of conversation %s: %v", participant.UserID, conversation.ID, err)
+		}
+	}
+}
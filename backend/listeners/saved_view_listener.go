@@ -0,0 +1,143 @@
+package listeners
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"sync"
+	"time"
+
+	"go.uber.org/dig"
+)
+
+// savedViewRefreshDelay is how long changes to a company's conversations are collected
+// before the counts are refreshed, so bursts such as bulk actions recount the views once
+const savedViewRefreshDelay = 2 * time.Second
+
+// SavedViewListener refreshes the saved view counts of the connected agents when conversations change
+type SavedViewListener struct {
+	dispatcher       interfaces.Dispatcher
+	pubSub           interfaces.PubSub
+	savedViewRepo    repositories.SavedViewRepository
+	conversationRepo repositories.ConversationRepository
+	logger           interfaces.Logger
+
+	mu      sync.Mutex
+	pending map[string]bool // companies with a scheduled refresh
+}
+
+// SavedViewListenerParams contains dependencies for SavedViewListener
+type SavedViewListenerParams struct {
+	dig.In
+	Dispatcher       interfaces.Dispatcher
+	PubSub           interfaces.PubSub
+	SavedViewRepo    repositories.SavedViewRepository
+	ConversationRepo repositories.ConversationRepository
+	Logger           interfaces.Logger
+}
+
+func NewSavedViewListener(params SavedViewListenerParams) *SavedViewListener {
+	listener := &SavedViewListener{
+		dispatcher:       params.Dispatcher,
+		pubSub:           params.PubSub,
+		savedViewRepo:    params.SavedViewRepo,
+		conversationRepo: params.ConversationRepo,
+		logger:           params.Logger,
+		pending:          make(map[string]bool),
+	}
+	listener.subscribe()
+	return listener
+}
+
+func (l *SavedViewListener) subscribe() {
+	for _, eventType := range []interfaces.EventType{
+		interfaces.EventTypeConversationStart,
+		interfaces.EventTypeConversationAssign,
+		interfaces.EventTypeConversationClose,
+		interfaces.EventTypeConversationResolve,
+		interfaces.EventTypeConversationReopen,
+		interfaces.EventTypeConversationPending,
+		interfaces.EventTypeConversationSnooze,
+		interfaces.EventTypeConversationWake,
+		interfaces.EventTypeConversationPriority,
+		interfaces.EventTypeConversationTransfer,
+		interfaces.EventTypeConversationLabels,
+	} {
+		l.dispatcher.Subscribe(eventType, l.HandleConversationChanged)
+	}
+}
+
+// HandleConversationChanged schedules sending the agents of the conversation's company the new counts of their saved views
+func (l *SavedViewListener) HandleConversationChanged(event interfaces.Event) {
+	var conversation *models.Conversation
+	switch payload := event.Payload.(type) {
+	case *models.Conversation:
+		conversation = payload
+	case *ConversationTransferPayload:
+		conversation = payload.Conversation
+	case *ConversationLabelsPayload:
+		conversation = payload.Conversation
+	default:
+		return
+	}
+
+	l.scheduleRefresh(conversation.CompanyID)
+}
+
+// scheduleRefresh refreshes the company's counts after savedViewRefreshDelay, unless a refresh
+// is already scheduled which will include this change
+func (l *SavedViewListener) scheduleRefresh(companyID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.pending[companyID] {
+		return
+	}
+	l.pending[companyID] = true
+
+	time.AfterFunc(savedViewRefreshDelay, func() {
+		// Changes made while counting schedule another refresh
+		l.mu.Lock()
+		delete(l.pending, companyID)
+		l.mu.Unlock()
+
+		l.refreshCompany(companyID)
+	})
+}
+
+// refreshCompany sends every connected agent of the company the counts of their saved views
+func (l *SavedViewListener) refreshCompany(companyID string) {
+	refreshed := make(map[string]bool)
+	for _, client := range l.pubSub.GetSubscribers("company:" + companyID) {
+		if !client.IsAgent() || refreshed[client.GetID()] {
+			continue
+		}
+		refreshed[client.GetID()] = true
+
+		l.refreshCounts(client.GetID(), companyID)
+	}
+}
+
+func (l *SavedViewListener) refreshCounts(userID string, companyID string) {
+	views, err := l.savedViewRepo.GetSavedViewsForUser(userID, companyID)
+	if err != nil {
+		l.logger.Error("Failed to get saved views of user %s: %v", userID, err)
+		return
+	}
+	if len(views) == 0 {
+		return
+	}
+
+	counts := make(map[string]int64, len(views))
+	for _, view := range views {
+		count, err := l.conversationRepo.CountConversationsForUser(userID, repositories.NewConversationFilter(view.Filter, userID))
+		if err != nil {
+			l.logger.Error("Failed to count conversations of saved view %s: %v", view.ID, err)
+			continue
+		}
+		counts[view.ID] = count
+	}
+
+	l.pubSub.Publish("user:"+userID, types.EventTypeSavedViewCounts, types.OutgoingSavedViewCountsPayload{Counts: counts})
+}
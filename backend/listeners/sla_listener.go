@@ -0,0 +1,297 @@
+package listeners
+
+import (
+	"live-chat-server/interfaces"
+	"live-chat-server/jobs"
+	"live-chat-server/models"
+	"live-chat-server/repositories"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+	"time"
+
+	"go.uber.org/dig"
+)
+
+// SLAListener starts and stops the SLA clocks of conversations and alerts agents about them
+type SLAListener struct {
+	dispatcher          interfaces.Dispatcher
+	pubSub              interfaces.PubSub
+	conversationRepo    repositories.ConversationRepository
+	slaPolicyRepo       repositories.SLAPolicyRepository
+	userRepo            repositories.UserRepository
+	jobClient           interfaces.JobClient
+	notificationService interfaces.NotificationService
+	logger              interfaces.Logger
+}
+
+// SLAListenerParams contains dependencies for SLAListener
+type SLAListenerParams struct {
+	dig.In
+	Dispatcher          interfaces.Dispatcher
+	PubSub              interfaces.PubSub
+	ConversationRepo    repositories.ConversationRepository
+	SLAPolicyRepo       repositories.SLAPolicyRepository
+	UserRepo            repositories.UserRepository
+	JobClient           interfaces.JobClient
+	NotificationService interfaces.NotificationService
+	Logger              interfaces.Logger
+}
+
+func NewSLAListener(params SLAListenerParams) *SLAListener {
+	listener := &SLAListener{
+		dispatcher:          params.Dispatcher,
+		pubSub:              params.PubSub,
+		conversationRepo:    params.ConversationRepo,
+		slaPolicyRepo:       params.SLAPolicyRepo,
+		userRepo:            params.UserRepo,
+		jobClient:           params.JobClient,
+		notificationService: params.NotificationService,
+		logger:              params.Logger,
+	}
+	listener.subscribe()
+	return listener
+}
+
+func (l *SLAListener) subscribe() {
+	l.dispatcher.Subscribe(interfaces.EventTypeMessageCreated, l.HandleMessageCreated)
+	l.dispatcher.Subscribe(interfaces.EventTypeConversationTransfer, l.HandleConversationTransfer)
+	l.dispatcher.Subscribe(interfaces.EventTypeSLAWarning, l.HandleSLAWarning)
+	l.dispatcher.Subscribe(interfaces.EventTypeSLABreached, l.HandleSLABreached)
+}
+
+func (l *SLAListener) HandleMessageCreated(event interfaces.Event) {
+	payload, ok := event.Payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	message, ok := payload["message"].(*models.Message)
+	if !ok || message.Private {
+		return
+	}
+
+	if message.SenderType != models.SenderTypeContact && message.SenderType != models.SenderTypeAgent {
+		return
+	}
+
+	// Reload the conversation, as the one in the payload may predate its SLA being applied
+	conversation, err := l.conversationRepo.GetConversationByID(message.ConversationID, "Inbox", "Inbox.SLAPolicy")
+	if err != nil {
+		l.logger.Error("Failed to get conversation %s for SLA tracking: %v", message.ConversationID, err)
+		return
+	}
+
+	var changed bool
+	if message.SenderType == models.SenderTypeContact {
+		changed = l.startClocks(conversation, message.CreatedAt)
+	} else {
+		changed = l.stopResponseClocks(conversation, message.CreatedAt)
+	}
+
+	if !changed {
+		return
+	}
+
+	if err := l.conversationRepo.UpdateConversationSLA(conversation); err != nil {
+		l.logger.Error("Failed to update SLA of conversation %s: %v", conversation.ID, err)
+	}
+}
+
+// startClocks applies the inbox's SLA policy to a new conversation, or starts the next response
+// clock once the contact writes again after an agent has replied
+func (l *SLAListener) startClocks(conversation *models.Conversation, at time.Time) bool {
+	if conversation.SLAPolicyID == nil {
+		policy := conversation.Inbox.SLAPolicy
+		if policy == nil {
+			return false
+		}
+
+		l.applyPolicy(conversation, policy, at)
+		return true
+	}
+
+	if conversation.FirstResponseAt == nil || conversation.NextResponseDueAt != nil {
+		return false
+	}
+
+	policy, err := l.slaPolicyRepo.GetSLAPolicyByID(*conversation.SLAPolicyID)
+	if err != nil {
+		// The policy has been deleted, so only the due dates already set are tracked
+		return false
+	}
+
+	conversation.NextResponseDueAt = policy.DueAt(models.SLATargetNextResponse, at)
+	if conversation.NextResponseDueAt == nil {
+		return false
+	}
+
+	l.scheduleChecks(conversation, policy, models.SLATargetNextResponse, at)
+	return true
+}
+
+// HandleConversationTransfer replaces the SLA of a transferred conversation with its new inbox's policy,
+// or stops tracking it if the inbox has none
+func (l *SLAListener) HandleConversationTransfer(event interfaces.Event) {
+	payload, ok := event.Payload.(*ConversationTransferPayload)
+	if !ok {
+		return
+	}
+
+	conversation, err := l.conversationRepo.GetConversationByID(payload.Conversation.ID, "Inbox", "Inbox.SLAPolicy")
+	if err != nil {
+		l.logger.Error("Failed to get conversation %s for SLA tracking: %v", payload.Conversation.ID, err)
+		return
+	}
+
+	policy := conversation.Inbox.SLAPolicy
+	if conversation.SLAPolicyID == nil && policy == nil {
+		return
+	}
+
+	// The checks scheduled for the previous due dates find them changed and do nothing
+	conversation.SLAPolicyID = nil
+	conversation.FirstResponseDueAt = nil
+	conversation.NextResponseDueAt = nil
+	conversation.ResolutionDueAt = nil
+	conversation.FirstResponseBreached = false
+	conversation.NextResponseBreached = false
+	conversation.ResolutionBreached = false
+
+	if policy != nil {
+		l.applyPolicy(conversation, policy, time.Now())
+	}
+
+	if err := l.conversationRepo.UpdateConversationSLA(conversation); err != nil {
+		l.logger.Error("Failed to update SLA of conversation %s: %v", conversation.ID, err)
+	}
+}
+
+// applyPolicy starts the policy's first response clock, unless an agent has already replied,
+// and its resolution clock from when the conversation started
+func (l *SLAListener) applyPolicy(conversation *models.Conversation, policy *models.SLAPolicy, at time.Time) {
+	conversation.SLAPolicyID = &policy.ID
+
+	if conversation.FirstResponseAt == nil {
+		conversation.FirstResponseDueAt = policy.DueAt(models.SLATargetFirstResponse, at)
+		l.scheduleChecks(conversation, policy, models.SLATargetFirstResponse, at)
+	}
+
+	conversation.ResolutionDueAt = policy.DueAt(models.SLATargetResolution, conversation.CreatedAt)
+	l.scheduleChecks(conversation, policy, models.SLATargetResolution, conversation.CreatedAt)
+}
+
+// stopResponseClocks records an agent's reply against the response targets
+func (l *SLAListener) stopResponseClocks(conversation *models.Conversation, at time.Time) bool {
+	if conversation.SLAPolicyID == nil {
+		return false
+	}
+
+	changed := false
+	if conversation.FirstResponseAt == nil {
+		conversation.FirstResponseAt = &at
+		changed = true
+	}
+
+	if conversation.NextResponseDueAt != nil {
+		conversation.NextResponseDueAt = nil
+		changed = true
+	}
+
+	return changed
+}
+
+// scheduleChecks schedules the warning and breach checks for a target. The checks compare the
+// due date they were scheduled for, so those left over from a met target do nothing.
+func (l *SLAListener) scheduleChecks(conversation *models.Conversation, policy *models.SLAPolicy, target models.SLATarget, from time.Time) {
+	dueAt := conversation.SLADueAt(target)
+	if dueAt == nil {
+		return
+	}
+
+	if warnAt := policy.WarnAt(target, from); warnAt != nil {
+		l.enqueueCheck(conversation, target, jobs.SLACheckStageWarning, *dueAt, *warnAt)
+	}
+
+	l.enqueueCheck(conversation, target, jobs.SLACheckStageBreach, *dueAt, *dueAt)
+}
+
+func (l *SLAListener) enqueueCheck(conversation *models.Conversation, target models.SLATarget, stage string, dueAt time.Time, at time.Time) {
+	if err := l.jobClient.EnqueueWithOptions("check_conversation_sla", jobs.CheckConversationSLAJobPayload{
+		ConversationID: conversation.ID,
+		Target:         target,
+		Stage:          stage,
+		DueAt:          dueAt,
+	}, jobs.ProcessAt(at)...); err != nil {
+		l.logger.Error("Failed to schedule SLA %s check of conversation %s: %v", stage, conversation.ID, err)
+	}
+}
+
+func (l *SLAListener) HandleSLAWarning(event interfaces.Event) {
+	l.handleSLAAlert(event, types.EventTypeSLAWarning, models.UserNotificationTypeSLAWarning)
+}
+
+func (l *SLAListener) HandleSLABreached(event interfaces.Event) {
+	l.handleSLAAlert(event, types.EventTypeSLABreached, models.UserNotificationTypeSLABreached)
+}
+
+func (l *SLAListener) handleSLAAlert(event interfaces.Event, eventType types.EventType, notificationType models.UserNotificationType) {
+	payload, ok := event.Payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	conversation, ok := payload["conversation"].(*models.Conversation)
+	if !ok {
+		return
+	}
+	target, _ := payload["target"].(models.SLATarget)
+	dueAt, _ := payload["due_at"].(time.Time)
+
+	l.pubSub.Publish("company:"+conversation.CompanyID, eventType, &types.OutgoingSLAAlertPayload{
+		ConversationID: conversation.ID,
+		Target:         string(target),
+		DueAt:          dueAt.Format(time.RFC3339),
+		Conversation:   conversation.ToPayloadWithoutMessages(),
+	})
+
+	for _, user := range l.getAlertRecipients(conversation) {
+		if err := l.notificationService.CreateNotification(user, notificationType, map[string]interface{}{
+			"ActionURL":      utils.FrontendURL("/conversations/" + conversation.ID),
+			"ConversationID": conversation.ID,
+			"Target":         string(target),
+			"DueAt":          dueAt.Format(time.RFC3339),
+		}); err != nil {
+			l.logger.Error("Failed to notify user %s of SLA %s on conversation %s: %v", user.ID, notificationType, conversation.ID, err)
+		}
+	}
+}
+
+// getAlertRecipients returns the conversation's assignee and the company's admins and super admins
+func (l *SLAListener) getAlertRecipients(conversation *models.Conversation) []*models.User {
+	var recipients []*models.User
+	seen := make(map[string]bool)
+
+	if conversation.AssignedToID != nil {
+		if user, err := l.userRepo.GetUserByID(*conversation.AssignedToID); err == nil {
+			recipients = append(recipients, user)
+			seen[user.ID] = true
+		}
+	}
+
+	users, err := l.userRepo.GetUsersByCompanyID(conversation.CompanyID)
+	if err != nil {
+		l.logger.Error("Failed to get users of company %s: %v", conversation.CompanyID, err)
+		return recipients
+	}
+
+	for i := range users {
+		isAdmin := users[i].Role == string(models.RoleAdmin) || users[i].Role == string(models.RoleSuperAdmin)
+		if !isAdmin || seen[users[i].ID] {
+			continue
+		}
+		recipients = append(recipients, &users[i])
+		seen[users[i].ID] = true
+	}
+
+	return recipients
+}
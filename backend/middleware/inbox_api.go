@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"live-chat-server/models"
+	"live-chat-server/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// InboxTokenHeader is the request header external systems send their API inbox token in
+const InboxTokenHeader = "X-Inbox-Token"
+
+// GetAPIInbox returns the API inbox authenticated by InboxAPIAuth
+func GetAPIInbox(c *fiber.Ctx) *models.Inbox {
+	inbox, ok := c.Locals("inbox").(*models.Inbox)
+	if !ok {
+		return nil
+	}
+	return inbox
+}
+
+// InboxAPIAuth middleware authenticates an external system by the token of its API inbox
+func InboxAPIAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := c.Get(InboxTokenHeader)
+		if token == "" {
+			return utils.ErrorResponse(c, fiber.StatusUnauthorized, "unauthorized", nil)
+		}
+
+		var api models.InboxAPI
+		if result := models.DB.First(&api, "token = ?", token); result.Error != nil {
+			return utils.ErrorResponse(c, fiber.StatusUnauthorized, "invalid_token", nil)
+		}
+
+		var inbox models.Inbox
+		if result := models.DB.First(&inbox, "id = ? AND type = ?", api.InboxID, models.InboxTypeAPI); result.Error != nil {
+			return utils.ErrorResponse(c, fiber.StatusUnauthorized, "invalid_token", nil)
+		}
+
+		if !inbox.Enabled {
+			return utils.ErrorResponse(c, fiber.StatusForbidden, "inbox_disabled", nil)
+		}
+
+		inbox.SetConfig(&api)
+		c.Locals("inbox", &inbox)
+
+		return c.Next()
+	}
+}
@@ -28,11 +28,22 @@ const (
 	AuditActionCompanyDelete AuditAction = "company_delete"
 
 	// Conversation actions
-	AuditActionConversationCreate  AuditAction = "conversation_create"
-	AuditActionConversationUpdate  AuditAction = "conversation_update"
-	AuditActionConversationAssign  AuditAction = "conversation_assign"
-	AuditActionConversationResolve AuditAction = "conversation_resolve"
-	AuditActionConversationDelete  AuditAction = "conversation_delete"
+	AuditActionConversationCreate   AuditAction = "conversation_create"
+	AuditActionConversationUpdate   AuditAction = "conversation_update"
+	AuditActionConversationAssign   AuditAction = "conversation_assign"
+	AuditActionConversationResolve  AuditAction = "conversation_resolve"
+	AuditActionConversationReopen   AuditAction = "conversation_reopen"
+	AuditActionConversationPending  AuditAction = "conversation_pending"
+	AuditActionConversationClose    AuditAction = "conversation_close"
+	AuditActionConversationWake     AuditAction = "conversation_wake"
+	AuditActionConversationSnooze   AuditAction = "conversation_snooze"
+	AuditActionConversationPriority AuditAction = "conversation_priority"
+	AuditActionConversationTransfer AuditAction = "conversation_transfer"
+	AuditActionConversationDelete   AuditAction = "conversation_delete"
+
+	// Transcript emails queued for the contact, or that could not be queued
+	AuditActionConversationTranscriptQueued AuditAction = "conversation_transcript_queued"
+	AuditActionConversationTranscriptFailed AuditAction = "conversation_transcript_failed"
 
 	// Message actions
 	AuditActionMessageSend   AuditAction = "message_send"
@@ -49,6 +60,13 @@ const (
 	AuditActionContactUpdate     AuditAction = "contact_update"
 	AuditActionContactDelete     AuditAction = "contact_delete"
 	AuditActionContactNoteCreate AuditAction = "contact_note_create"
+	AuditActionContactMerge      AuditAction = "contact_merge"
+	AuditActionContactMergeUndo  AuditAction = "contact_merge_undo"
+
+	// Label actions
+	AuditActionLabelCreate AuditAction = "label_create"
+	AuditActionLabelUpdate AuditAction = "label_update"
+	AuditActionLabelDelete AuditAction = "label_delete"
 
 	// File actions
 	AuditActionFileUpload AuditAction = "file_upload"
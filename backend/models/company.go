@@ -18,18 +18,27 @@ type Company struct {
 	CreatedAt time.Time      `gorm:""`
 	UpdatedAt time.Time      `gorm:""`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// MessageEditWindowMinutes is how long after sending agents and contacts can edit or delete a message, 0 disables it
+	MessageEditWindowMinutes int `gorm:"not null;default:15"`
 }
 
 func (c *Company) ToResponse() interface{} {
 	return map[string]interface{}{
-		"id":         c.ID,
-		"name":       c.Name,
-		"email":      c.Email,
-		"phone":      c.Phone,
-		"website":    c.Website,
-		"address":    c.Address,
-		"logo":       utils.Asset(c.Logo),
-		"created_at": c.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		"updated_at": c.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		"id":                          c.ID,
+		"name":                        c.Name,
+		"email":                       c.Email,
+		"phone":                       c.Phone,
+		"website":                     c.Website,
+		"address":                     c.Address,
+		"logo":                        utils.Asset(c.Logo),
+		"message_edit_window_minutes": c.MessageEditWindowMinutes,
+		"created_at":                  c.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		"updated_at":                  c.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 }
+
+// MessageEditWindow returns how long after sending a message can be edited or deleted
+func (c *Company) MessageEditWindow() time.Duration {
+	return time.Duration(c.MessageEditWindowMinutes) * time.Minute
+}
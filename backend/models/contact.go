@@ -19,6 +19,10 @@ type Contact struct {
 	CreatedAt  time.Time
 	UpdatedAt  time.Time  
 	DeletedAt  gorm.DeletedAt `gorm:"index"`
+
+	// SearchVector is generated by Postgres from the contact details, it is only ever used in search queries.
+	// Email addresses are also split on @ and dots so a search for the name or domain finds them.
+	SearchVector string `gorm:"type:tsvector GENERATED ALWAYS AS (to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(email, '') || ' ' || translate(coalesce(email, ''), '@.', '  ') || ' ' || coalesce(phone, '') || ' ' || coalesce(company, ''))) STORED;index:idx_contacts_search_vector,type:gin;->:false;<-:false"`
 }
 
 func (c *Contact) ToResponse() types.ContactPayload {
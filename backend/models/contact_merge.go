@@ -0,0 +1,141 @@
+package models
+
+import (
+	"errors"
+	"live-chat-server/types"
+	"live-chat-server/utils"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ContactMergeUndoWindow is how long a merge can be undone after it was made
+const ContactMergeUndoWindow = 7 * 24 * time.Hour
+
+var (
+	ErrContactMergeSameContact   = errors.New("a contact cannot be merged into itself")
+	ErrContactMergeNotUndoable   = errors.New("the contact merge can no longer be undone")
+	ErrContactMergeAlreadyUndone = errors.New("the contact merge has already been undone")
+)
+
+// ContactMerge records a secondary contact merged into a primary contact, with what was moved so it can be undone
+type ContactMerge struct {
+	ID                 string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CompanyID          string `gorm:"type:uuid;not null;index"`
+	PrimaryContactID   string `gorm:"type:uuid;not null;index"`
+	SecondaryContactID string `gorm:"type:uuid;not null;index"`
+	UserID             string `gorm:"type:uuid;not null"`
+	// IDs of the records moved from the secondary to the primary contact
+	ConversationIDs []string `gorm:"type:jsonb;serializer:json"`
+	MessageIDs      []string `gorm:"type:jsonb;serializer:json"`
+	NoteIDs         []string `gorm:"type:jsonb;serializer:json"`
+	// FilledFields are the primary contact's empty fields that were filled from the secondary contact
+	FilledFields  []string `gorm:"type:jsonb;serializer:json"`
+	UndoableUntil time.Time
+	UndoneAt      *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	PrimaryContact   *Contact `gorm:"foreignKey:PrimaryContactID"`
+	SecondaryContact *Contact `gorm:"foreignKey:SecondaryContactID"`
+	User             *User    `gorm:"foreignKey:UserID"`
+}
+
+// CanUndo returns an error if the merge cannot be undone at the given time
+func (m *ContactMerge) CanUndo(now time.Time) error {
+	if m.UndoneAt != nil {
+		return ErrContactMergeAlreadyUndone
+	}
+	if now.After(m.UndoableUntil) {
+		return ErrContactMergeNotUndoable
+	}
+	return nil
+}
+
+// FillEmptyFields copies the secondary contact's details into the primary contact's empty fields and returns the fields filled
+func FillEmptyFields(primary *Contact, secondary *Contact) []string {
+	filled := []string{}
+	for _, field := range contactFields(primary, secondary) {
+		if isBlank(*field.primary) && !isBlank(*field.secondary) {
+			value := **field.secondary
+			*field.primary = &value
+			filled = append(filled, field.name)
+		}
+	}
+	return filled
+}
+
+// ClearFilledFields empties the primary contact's fields that a merge filled, unless they have been changed since
+func ClearFilledFields(primary *Contact, secondary *Contact, filled []string) {
+	for _, field := range contactFields(primary, secondary) {
+		if !utils.Contains(filled, field.name) || isBlank(*field.primary) || isBlank(*field.secondary) {
+			continue
+		}
+		if **field.primary == **field.secondary {
+			*field.primary = nil
+		}
+	}
+}
+
+type contactField struct {
+	name      string
+	primary   **string
+	secondary **string
+}
+
+// contactFields pairs the mergeable fields of the primary and secondary contact
+func contactFields(primary *Contact, secondary *Contact) []contactField {
+	return []contactField{
+		{"name", &primary.Name, &secondary.Name},
+		{"email", &primary.Email, &secondary.Email},
+		{"phone", &primary.Phone, &secondary.Phone},
+		{"company", &primary.Company, &secondary.Company},
+	}
+}
+
+var phoneFormatting = regexp.MustCompile(`[^0-9+]`)
+
+// DuplicateMatches returns the details two contacts share that suggest they are the same person
+func DuplicateMatches(contact *Contact, other *Contact) []string {
+	matches := []string{}
+	if !isBlank(contact.Email) && !isBlank(other.Email) && strings.EqualFold(*contact.Email, *other.Email) {
+		matches = append(matches, "email")
+	}
+	if !isBlank(contact.Phone) && !isBlank(other.Phone) {
+		phone := phoneFormatting.ReplaceAllString(*contact.Phone, "")
+		if phone != "" && phone == phoneFormatting.ReplaceAllString(*other.Phone, "") {
+			matches = append(matches, "phone")
+		}
+	}
+	return matches
+}
+
+func isBlank(value *string) bool {
+	return value == nil || *value == ""
+}
+
+func (m *ContactMerge) ToResponse() types.ContactMergePayload {
+	payload := types.ContactMergePayload{
+		ID:                 m.ID,
+		PrimaryContactID:   m.PrimaryContactID,
+		SecondaryContactID: m.SecondaryContactID,
+		UserID:             m.UserID,
+		ConversationCount:  len(m.ConversationIDs),
+		NoteCount:          len(m.NoteIDs),
+		FilledFields:       m.FilledFields,
+		UndoableUntil:      m.UndoableUntil.Format(time.RFC3339),
+		CreatedAt:          m.CreatedAt.Format(time.RFC3339),
+	}
+
+	if m.UndoneAt != nil {
+		payload.UndoneAt = m.UndoneAt.Format(time.RFC3339)
+	}
+	if m.PrimaryContact != nil {
+		payload.PrimaryContact = m.PrimaryContact.ToPayload()
+	}
+	if m.SecondaryContact != nil {
+		payload.SecondaryContact = m.SecondaryContact.ToPayload()
+	}
+
+	return payload
+}
@@ -18,6 +18,9 @@ type ContactNote struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// SearchVector is generated by Postgres from the content, it is only ever used in search queries
+	SearchVector string `gorm:"type:tsvector GENERATED ALWAYS AS (to_tsvector('simple', coalesce(content, ''))) STORED;index:idx_contact_notes_search_vector,type:gin;->:false;<-:false" json:"-"`
 }
 
 func (cn *ContactNote) ToResponse() types.ContactNotePayload {
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"errors"
 	"live-chat-server/types"
 	"live-chat-server/utils"
 	"time"
@@ -16,22 +17,109 @@ const (
 	ConversationStatusPending  ConversationStatus = "pending"
 	ConversationStatusClosed   ConversationStatus = "closed"
 	ConversationStatusResolved ConversationStatus = "resolved"
+	ConversationStatusSnoozed  ConversationStatus = "snoozed"
 )
 
+// ConversationPriority is how urgently a conversation needs an agent
+type ConversationPriority string
+
+const (
+	ConversationPriorityLow    ConversationPriority = "low"
+	ConversationPriorityMedium ConversationPriority = "medium"
+	ConversationPriorityHigh   ConversationPriority = "high"
+	ConversationPriorityUrgent ConversationPriority = "urgent"
+)
+
+// IsValid reports whether the priority is one of the known levels
+func (p ConversationPriority) IsValid() bool {
+	switch p {
+	case ConversationPriorityLow, ConversationPriorityMedium, ConversationPriorityHigh, ConversationPriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// ConversationTransition is a change of a conversation's status made by an agent or the system
+type ConversationTransition string
+
+const (
+	ConversationTransitionResolve ConversationTransition = "resolve"
+	ConversationTransitionReopen  ConversationTransition = "reopen"
+	ConversationTransitionPending ConversationTransition = "pending"
+	ConversationTransitionClose   ConversationTransition = "close"
+	ConversationTransitionSnooze  ConversationTransition = "snooze"
+	ConversationTransitionWake    ConversationTransition = "wake"
+)
+
+var (
+	// ErrInvalidConversationTransition is returned when a transition is not allowed from the conversation's status
+	ErrInvalidConversationTransition = errors.New("invalid conversation status transition")
+	// ErrSnoozeUntilInPast is returned when a conversation is snoozed until a time which has already passed
+	ErrSnoozeUntilInPast = errors.New("snooze time must be in the future")
+	// ErrInvalidConversationPriority is returned when a priority is not one of the known levels
+	ErrInvalidConversationPriority = errors.New("invalid conversation priority")
+	// ErrConversationAlreadyInInbox is returned when a conversation is transferred to the inbox it is in
+	ErrConversationAlreadyInInbox = errors.New("conversation is already in this inbox")
+	// ErrInboxTypeMismatch is returned when a conversation is transferred to an inbox of another channel
+	ErrInboxTypeMismatch = errors.New("inbox is of another channel type")
+	// ErrConversationClosed is returned when a closed conversation is changed
+	ErrConversationClosed = errors.New("conversation is closed")
+	// ErrAssigneeNotInboxMember is returned when a conversation is assigned to an agent outside its inbox
+	ErrAssigneeNotInboxMember = errors.New("assignee is not a member of the inbox")
+	// ErrContactHasNoEmail is returned when a transcript is sent to a contact without an email address
+	ErrContactHasNoEmail = errors.New("contact has no email address")
+	// ErrTranscriptRecentlyRequested is returned when the contact asks for transcripts too often
+	ErrTranscriptRecentlyRequested = errors.New("a transcript was requested recently")
+)
+
+// TranscriptTrigger is why a conversation's transcript was emailed to the contact
+type TranscriptTrigger string
+
+const (
+	// TranscriptTriggerClose is the inbox sending transcripts of the conversations it closes
+	TranscriptTriggerClose TranscriptTrigger = "close"
+	// TranscriptTriggerRequest is the contact asking for the transcript from the widget
+	TranscriptTriggerRequest TranscriptTrigger = "request"
+)
+
+// conversationTransitions lists the statuses each transition may be applied from.
+// Closed conversations are final; resolved conversations can be reopened and snoozed ones woken.
+var conversationTransitions = map[ConversationTransition][]ConversationStatus{
+	ConversationTransitionResolve: {ConversationStatusPending, ConversationStatusActive, ConversationStatusSnoozed},
+	ConversationTransitionReopen:  {ConversationStatusResolved},
+	ConversationTransitionPending: {ConversationStatusActive},
+	ConversationTransitionClose:   {ConversationStatusPending, ConversationStatusActive, ConversationStatusResolved, ConversationStatusSnoozed},
+	ConversationTransitionSnooze:  {ConversationStatusPending, ConversationStatusActive},
+	ConversationTransitionWake:    {ConversationStatusSnoozed},
+}
+
 // Conversation represents a chat conversation between a contact and agents
 type Conversation struct {
-	ID            string             `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
-	InboxID       string             `gorm:"type:uuid;not null" json:"inbox_id"`
-	ContactID     string             `gorm:"type:uuid;not null" json:"contact_id"`
-	CompanyID     string             `gorm:"type:uuid;not null" json:"company_id"`
-	AssignedToID  *string            `gorm:"type:uuid" json:"assigned_to_id"`
-	Status        ConversationStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
-	LastMessage   string             `json:"last_message"`
-	LastMessageAt *time.Time         `json:"last_message_at"`
-	Metadata      *json.RawMessage   `gorm:"type:jsonb;serializer:json" json:"metadata"`
-	CreatedAt     time.Time          `json:"created_at"`
-	UpdatedAt     time.Time          `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt     `gorm:"index" json:"-"`
+	ID            string               `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	InboxID       string               `gorm:"type:uuid;not null" json:"inbox_id"`
+	ContactID     string               `gorm:"type:uuid;not null" json:"contact_id"`
+	CompanyID     string               `gorm:"type:uuid;not null" json:"company_id"`
+	AssignedToID  *string              `gorm:"type:uuid" json:"assigned_to_id"`
+	Status        ConversationStatus   `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Priority      ConversationPriority `gorm:"type:varchar(10);not null;default:'medium';index" json:"priority"`
+	LastMessage   string               `json:"last_message"`
+	LastMessageAt *time.Time           `json:"last_message_at"`
+	SnoozedUntil  *time.Time           `json:"snoozed_until"`
+	// TranscriptRequestedAt is when the contact last had a transcript emailed from the widget
+	TranscriptRequestedAt *time.Time `json:"-"`
+	// SLA tracking, set once the inbox's SLA policy is applied to the conversation
+	SLAPolicyID           *string          `gorm:"type:uuid" json:"sla_policy_id"`
+	FirstResponseDueAt    *time.Time       `json:"first_response_due_at"`
+	NextResponseDueAt     *time.Time       `json:"next_response_due_at"`
+	ResolutionDueAt       *time.Time       `json:"resolution_due_at"`
+	FirstResponseAt       *time.Time       `json:"first_response_at"`
+	FirstResponseBreached bool             `gorm:"default:false" json:"first_response_breached"`
+	NextResponseBreached  bool             `gorm:"default:false" json:"next_response_breached"`
+	ResolutionBreached    bool             `gorm:"default:false" json:"resolution_breached"`
+	Metadata              *json.RawMessage `gorm:"type:jsonb;serializer:json" json:"metadata"`
+	CreatedAt             time.Time        `json:"created_at"`
+	UpdatedAt             time.Time        `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt   `gorm:"index" json:"-"`
 
 	// Relationships
 	Inbox      Inbox     `gorm:"foreignKey:InboxID" json:"inbox"`
@@ -39,6 +127,18 @@ type Conversation struct {
 	Contact    Contact   `gorm:"foreignKey:ContactID" json:"contact"`
 	AssignedTo *User     `gorm:"foreignKey:AssignedToID" json:"assigned_to"`
 	Messages   []Message `gorm:"foreignKey:ConversationID" json:"messages"`
+	Labels     []Label   `gorm:"many2many:conversation_labels;constraint:OnDelete:CASCADE" json:"labels"`
+
+	// Read state of the conversation, UnreadCount is only set for the client it was loaded for
+	Reads       []ConversationRead `gorm:"foreignKey:ConversationID" json:"-"`
+	UnreadCount *int               `gorm:"-" json:"-"`
+
+	// Cursors of the pages around Messages, set when they are loaded a page at a time
+	MessagesBefore string `gorm:"-" json:"-"`
+	MessagesAfter  string `gorm:"-" json:"-"`
+
+	// Agents following or collaborating on the conversation
+	Participants []ConversationParticipant `gorm:"foreignKey:ConversationID" json:"-"`
 }
 
 func (c *Conversation) ToPayload() *types.ConversationPayload {
@@ -46,6 +146,7 @@ func (c *Conversation) ToPayload() *types.ConversationPayload {
 		ID:             c.ID,
 		ConversationID: c.ID,
 		Status:         string(c.Status),
+		Priority:       string(c.Priority),
 		InboxID:        c.InboxID,
 		Metadata:       c.Metadata,
 		AssignedTo: func() *struct {
@@ -94,6 +195,15 @@ func (c *Conversation) ToPayload() *types.ConversationPayload {
 			}
 			return c.LastMessageAt.Format("2006-01-02 15:04:05")
 		}(),
+		Labels:      LabelsToPayload(c.Labels),
+		SLA:         c.slaPayload(),
+		UnreadCount: c.UnreadCount,
+		Receipts:    ReceiptsToPayload(c.Reads),
+
+		MessagesBefore: c.MessagesBefore,
+		MessagesAfter:  c.MessagesAfter,
+		Participants:   ParticipantsToPayload(c.Participants),
+		SnoozedUntil:   formatOptionalTime(c.SnoozedUntil),
 	}
 }
 
@@ -122,9 +232,34 @@ func (c *Conversation) ToPayloadWithoutPrivateMessages() *types.ConversationPayl
 	}
 	payload.Messages = messages
 
+	// Who follows the conversation is only for agents
+	payload.Participants = nil
+
+	// Receipts pointing at private notes must not reveal them to the contact
+	for i, receipt := range payload.Receipts {
+		if !c.isPublicMessage(receipt.LastReadMessageID) {
+			payload.Receipts[i].LastReadMessageID = ""
+			payload.Receipts[i].LastReadAt = ""
+		}
+		if !c.isPublicMessage(receipt.LastDeliveredMessageID) {
+			payload.Receipts[i].LastDeliveredMessageID = ""
+			payload.Receipts[i].LastDeliveredAt = ""
+		}
+	}
+
 	return payload
 }
 
+// isPublicMessage returns whether the loaded message with the ID is visible to the contact
+func (c *Conversation) isPublicMessage(messageID string) bool {
+	for _, message := range c.Messages {
+		if message.ID == messageID {
+			return !message.Private
+		}
+	}
+	return false
+}
+
 func (c *Conversation) GetMessages() []types.MessagePayload {
 	return MessagesToPayload(c.Messages)
 }
@@ -132,3 +267,109 @@ func (c *Conversation) GetMessages() []types.MessagePayload {
 func (c *Conversation) IsClosed() bool {
 	return c.Status == ConversationStatusClosed
 }
+
+func (c *Conversation) IsResolved() bool {
+	return c.Status == ConversationStatusResolved
+}
+
+func (c *Conversation) IsSnoozed() bool {
+	return c.Status == ConversationStatusSnoozed
+}
+
+// CanTransition reports whether the transition is allowed from the conversation's current status
+func (c *Conversation) CanTransition(transition ConversationTransition) bool {
+	for _, status := range conversationTransitions[transition] {
+		if c.Status == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TransitionStatus returns the status the transition moves the conversation to.
+// A reopened or woken conversation is active again if it has an assignee, otherwise it waits for one.
+func (c *Conversation) TransitionStatus(transition ConversationTransition) ConversationStatus {
+	switch transition {
+	case ConversationTransitionResolve:
+		return ConversationStatusResolved
+	case ConversationTransitionSnooze:
+		return ConversationStatusSnoozed
+	case ConversationTransitionReopen, ConversationTransitionWake:
+		if c.AssignedToID != nil {
+			return ConversationStatusActive
+		}
+		return ConversationStatusPending
+	case ConversationTransitionPending:
+		return ConversationStatusPending
+	default:
+		return ConversationStatusClosed
+	}
+}
+
+// SLADueAt returns when the target is due, or nil if it is not being tracked
+func (c *Conversation) SLADueAt(target SLATarget) *time.Time {
+	switch target {
+	case SLATargetFirstResponse:
+		return c.FirstResponseDueAt
+	case SLATargetNextResponse:
+		return c.NextResponseDueAt
+	case SLATargetResolution:
+		return c.ResolutionDueAt
+	}
+	return nil
+}
+
+// IsSLATargetMet reports whether the target due at the given time no longer needs meeting
+func (c *Conversation) IsSLATargetMet(target SLATarget, dueAt time.Time) bool {
+	current := c.SLADueAt(target)
+	if current == nil || !current.Equal(dueAt) {
+		// The target has been met and cleared, or moved since
+		return true
+	}
+
+	switch target {
+	case SLATargetFirstResponse:
+		return c.FirstResponseAt != nil
+	case SLATargetNextResponse, SLATargetResolution:
+		return c.IsResolved() || c.IsClosed()
+	}
+	return false
+}
+
+// MarkSLABreached records that the target was missed
+func (c *Conversation) MarkSLABreached(target SLATarget) {
+	switch target {
+	case SLATargetFirstResponse:
+		c.FirstResponseBreached = true
+	case SLATargetNextResponse:
+		c.NextResponseBreached = true
+	case SLATargetResolution:
+		c.ResolutionBreached = true
+	}
+}
+
+func (c *Conversation) slaPayload() *types.ConversationSLAPayload {
+	if c.SLAPolicyID == nil {
+		return nil
+	}
+
+	return &types.ConversationSLAPayload{
+		PolicyID:              *c.SLAPolicyID,
+		FirstResponseDueAt:    formatOptionalTime(c.FirstResponseDueAt),
+		NextResponseDueAt:     formatOptionalTime(c.NextResponseDueAt),
+		ResolutionDueAt:       formatOptionalTime(c.ResolutionDueAt),
+		FirstResponseAt:       formatOptionalTime(c.FirstResponseAt),
+		FirstResponseBreached: c.FirstResponseBreached,
+		NextResponseBreached:  c.NextResponseBreached,
+		ResolutionBreached:    c.ResolutionBreached,
+	}
+}
+
+// formatOptionalTime formats the time as RFC 3339, or returns an empty string if it is not set
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
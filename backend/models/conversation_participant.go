@@ -0,0 +1,54 @@
+package models
+
+import (
+	"live-chat-server/types"
+	"time"
+)
+
+// ParticipantRole is how an agent takes part in a conversation they are not assigned to
+type ParticipantRole string
+
+const (
+	// ParticipantRoleFollower agents follow the conversation to be kept up to date
+	ParticipantRoleFollower ParticipantRole = "follower"
+	// ParticipantRoleCollaborator agents were pulled in to help with the conversation
+	ParticipantRoleCollaborator ParticipantRole = "collaborator"
+)
+
+// ConversationParticipant is an agent following or collaborating on a conversation.
+// Participants are notified about the conversation without owning it.
+type ConversationParticipant struct {
+	ID             string          `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ConversationID string          `gorm:"type:uuid;not null;uniqueIndex:idx_conversation_participant"`
+	UserID         string          `gorm:"type:uuid;not null;uniqueIndex:idx_conversation_participant;index"`
+	Role           ParticipantRole `gorm:"type:varchar(20);not null;default:'follower'"`
+	AddedByID      *string         `gorm:"type:uuid"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	Conversation *Conversation `gorm:"foreignKey:ConversationID;constraint:OnDelete:CASCADE"`
+	User         *User         `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (p *ConversationParticipant) ToPayload() types.ConversationParticipantPayload {
+	payload := types.ConversationParticipantPayload{
+		UserID:    p.UserID,
+		Role:      string(p.Role),
+		AddedByID: p.AddedByID,
+		CreatedAt: p.CreatedAt.Format(time.RFC3339),
+	}
+	if p.User != nil {
+		payload.Name = p.User.GetFullName()
+		payload.Avatar = p.User.GetAvatar()
+	}
+	return payload
+}
+
+// ParticipantsToPayload converts participants to their payloads
+func ParticipantsToPayload(participants []ConversationParticipant) []types.ConversationParticipantPayload {
+	payload := make([]types.ConversationParticipantPayload, 0, len(participants))
+	for _, participant := range participants {
+		payload = append(payload, participant.ToPayload())
+	}
+	return payload
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"live-chat-server/types"
+	"time"
+)
+
+// ReceiptType is how far a conversation's messages have reached a reader
+type ReceiptType string
+
+const (
+	ReceiptTypeDelivered ReceiptType = "delivered"
+	ReceiptTypeRead      ReceiptType = "read"
+)
+
+// ErrReceiptMessageNotFound is returned when a receipt points at a message the reader cannot see
+var ErrReceiptMessageNotFound = errors.New("the message is not part of the conversation")
+
+// ConversationRead tracks how far an agent or the contact has received and read a conversation.
+// Every message sent up to a pointer counts as delivered or read.
+type ConversationRead struct {
+	ID                     string     `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ConversationID         string     `gorm:"type:uuid;not null;uniqueIndex:idx_conversation_reader"`
+	ReaderType             SenderType `gorm:"type:varchar(10);not null;uniqueIndex:idx_conversation_reader"`
+	ReaderID               string     `gorm:"type:uuid;not null;uniqueIndex:idx_conversation_reader"`
+	LastDeliveredMessageID *string    `gorm:"type:uuid"`
+	LastDeliveredAt        *time.Time
+	LastReadMessageID      *string `gorm:"type:uuid"`
+	LastReadAt             *time.Time
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+}
+
+// HasRead returns whether the reader has read the message
+func (r *ConversationRead) HasRead(message *Message) bool {
+	return r.LastReadAt != nil && !message.CreatedAt.After(*r.LastReadAt)
+}
+
+// HasReceived returns whether the message has been delivered to the reader
+func (r *ConversationRead) HasReceived(message *Message) bool {
+	return r.LastDeliveredAt != nil && !message.CreatedAt.After(*r.LastDeliveredAt)
+}
+
+func (r *ConversationRead) ToPayload() types.ConversationReceiptPayload {
+	payload := types.ConversationReceiptPayload{
+		ConversationID: r.ConversationID,
+		ReaderType:     string(r.ReaderType),
+		ReaderID:       r.ReaderID,
+	}
+
+	if r.LastDeliveredAt != nil {
+		payload.LastDeliveredMessageID = *r.LastDeliveredMessageID
+		payload.LastDeliveredAt = r.LastDeliveredAt.Format(time.RFC3339Nano)
+	}
+	if r.LastReadAt != nil {
+		payload.LastReadMessageID = *r.LastReadMessageID
+		payload.LastReadAt = r.LastReadAt.Format(time.RFC3339Nano)
+	}
+
+	return payload
+}
+
+// ReceiptsToPayload converts read states to receipts
+func ReceiptsToPayload(reads []ConversationRead) []types.ConversationReceiptPayload {
+	receipts := make([]types.ConversationReceiptPayload, len(reads))
+	for i, read := range reads {
+		receipts[i] = read.ToPayload()
+	}
+	return receipts
+}
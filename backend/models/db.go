@@ -17,16 +17,26 @@ func ConnectDatabase(dsn string) {
 	err = DB.AutoMigrate(
 		&Company{},
 		&User{},
+		&SLAPolicy{},
 		&Inbox{},
 		&InboxEmail{},
+		&InboxSMS{},
+		&InboxWhatsApp{},
+		&InboxAPI{},
 		&InboxWebChat{},
 		&Contact{},
 		&NotificationSettings{},
 		&Conversation{},
 		&Message{},
+		&MessageEdit{},
+		&ConversationRead{},
+		&ConversationParticipant{},
 		&ContactNote{},
+		&ContactMerge{},
 		&CompanyInvite{},
 		&CannedResponse{},
+		&Label{},
+		&SavedView{},
 		&UserNotification{},
 		&AuditLog{},
 	)
@@ -41,12 +51,19 @@ func RunMigrations() {
 		&Company{},
 		&Conversation{},
 		&Contact{},
+		&SLAPolicy{},
 		&Inbox{},
 		&Message{},
+		&MessageEdit{},
+		&ConversationRead{},
+		&ConversationParticipant{},
 		&CannedResponse{},
+		&Label{},
+		&SavedView{},
 		&NotificationSettings{},
 		&UserNotification{},
 		&ContactNote{},
+		&ContactMerge{},
 		&AuditLog{},
 	)
 
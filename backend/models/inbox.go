@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"live-chat-server/types"
+	"live-chat-server/utils"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,6 +18,7 @@ const (
 	InboxTypeEmail    InboxType = "email"
 	InboxTypeSMS      InboxType = "sms"
 	InboxTypeWhatsApp InboxType = "whatsapp"
+	InboxTypeAPI      InboxType = "api"
 )
 
 // Inbox represents a communication channel with common fields
@@ -31,15 +33,19 @@ type Inbox struct {
 	MaxAutoAssignments    int  `gorm:"default:1"`
 	AutoResponderEnabled  bool `gorm:"default:false"`
 	AutoResponderMessage  string
-	Users                 []User `gorm:"many2many:inbox_users;"`
+	SLAPolicyID           *string `gorm:"type:uuid"`
+	Users                 []User  `gorm:"many2many:inbox_users;"`
 	CreatedAt             time.Time
 	UpdatedAt             time.Time
 	DeletedAt             gorm.DeletedAt `gorm:"index" json:"-"`
 	Company               Company        `gorm:"foreignKey:CompanyID"`
+	SLAPolicy             *SLAPolicy     `gorm:"foreignKey:SLAPolicyID;constraint:OnDelete:SET NULL"`
 
-	// Type-specific configurations (relationships)
-	WebChat *InboxWebChat `gorm:"foreignKey:InboxID"`
-	Email   *InboxEmail   `gorm:"foreignKey:InboxID"`
+	// SendTranscriptOnClose emails the contact a transcript of their conversation once it is closed
+	SendTranscriptOnClose bool `gorm:"default:false"`
+
+	// config is the type-specific configuration, loaded by the inbox's channel
+	config InboxConfig
 }
 
 // InboxWebChat contains web chat specific configurations
@@ -84,6 +90,73 @@ type InboxEmail struct {
 	UpdatedAt  time.Time
 }
 
+// InboxSMS contains SMS specific configurations
+type InboxSMS struct {
+	ID          string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	InboxID     string `gorm:"type:uuid;uniqueIndex"`
+	Provider    string `gorm:"type:varchar(50);not null;default:'twilio'"`
+	AccountSID  string
+	AuthToken   string
+	PhoneNumber string `gorm:"type:varchar(50)"`
+	// APIBaseURL overrides the provider's API endpoint, e.g. for regional or self-hosted gateways
+	APIBaseURL string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TableName specifies the table name for InboxSMS
+func (InboxSMS) TableName() string {
+	return "inbox_sms"
+}
+
+// WebhookURL returns the URL the SMS provider should post inbound messages to
+func (s *InboxSMS) WebhookURL() string {
+	return utils.APIURL("/api/public/sms/" + s.InboxID + "/webhook")
+}
+
+// InboxWhatsApp contains WhatsApp Cloud API specific configurations
+type InboxWhatsApp struct {
+	ID                string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	InboxID           string `gorm:"type:uuid;uniqueIndex"`
+	PhoneNumberID     string `gorm:"index"`
+	BusinessAccountID string
+	AccessToken       string
+	AppSecret         string
+	VerifyToken       string
+	// APIBaseURL overrides the Graph API endpoint, e.g. for a local stand-in
+	APIBaseURL string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TableName specifies the table name for InboxWhatsApp
+func (InboxWhatsApp) TableName() string {
+	return "inbox_whatsapp"
+}
+
+// WebhookURL returns the callback URL to configure for the WhatsApp app
+func (w *InboxWhatsApp) WebhookURL() string {
+	return utils.APIURL("/api/public/whatsapp/" + w.InboxID + "/webhook")
+}
+
+// InboxAPI contains the configuration of an inbox fed by an external system over the REST API
+type InboxAPI struct {
+	ID      string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	InboxID string `gorm:"type:uuid;uniqueIndex"`
+	// Token authenticates the external system's requests
+	Token string `gorm:"type:varchar(64);uniqueIndex;not null"`
+	// CallbackURL receives agent replies and status changes, signed with CallbackSecret
+	CallbackURL    string
+	CallbackSecret string `gorm:"type:varchar(64)"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// TableName specifies the table name for InboxAPI
+func (InboxAPI) TableName() string {
+	return "inbox_api"
+}
+
 func (inbox *Inbox) ToResponse() types.InboxPayload {
 	users := make([]types.UserInboxPayload, len(inbox.Users))
 	for i, user := range inbox.Users {
@@ -105,57 +178,14 @@ func (inbox *Inbox) ToResponse() types.InboxPayload {
 		MaxAutoAssignments:    inbox.MaxAutoAssignments,
 		AutoResponderEnabled:  inbox.AutoResponderEnabled,
 		AutoResponderMessage:  inbox.AutoResponderMessage,
+		SendTranscriptOnClose: inbox.SendTranscriptOnClose,
+		SLAPolicyID:           utils.GetStringValue(inbox.SLAPolicyID),
 		UserCount:             len(inbox.Users),
 		CreatedAt:             inbox.CreatedAt.Format("02-01-2006 15:04:05"),
 		UpdatedAt:             inbox.UpdatedAt.Format("02-01-2006 15:04:05"),
 		Users:                 users,
 	}
 
-	// Add type-specific fields based on inbox type
-	switch inbox.Type {
-	case InboxTypeWebChat:
-		if inbox.WebChat != nil {
-			payload.WelcomeMessage = inbox.WebChat.WelcomeMessage
-			payload.OutsideHoursMessage = inbox.WebChat.OutsideHoursMessage
-			payload.WidgetCustomization = inbox.WebChat.WidgetCustomization
-			payload.PreChatForm = &inbox.WebChat.PreChatForm
-
-			// Handle working hours
-			workingHours := inbox.WebChat.WorkingHours
-			if workingHours == nil {
-				workingHours = types.WorkingHoursMap{
-					"monday":    types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-					"tuesday":   types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-					"wednesday": types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-					"thursday":  types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-					"friday":    types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
-					"saturday":  types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: false},
-					"sunday":    types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: false},
-				}
-			}
-
-			payload.WorkingHours = workingHours
-
-			// Determine if working hours are enabled
-			workingHoursEnabled := false
-			for _, wh := range workingHours {
-				if wh.Enabled {
-					workingHoursEnabled = true
-					break
-				}
-			}
-			payload.WorkingHoursEnabled = workingHoursEnabled
-		}
-	case InboxTypeEmail:
-		if inbox.Email != nil {
-			payload.ImapServer = inbox.Email.ImapServer
-			payload.ImapPort = inbox.Email.ImapPort
-			payload.SmtpServer = inbox.Email.SmtpServer
-			payload.SmtpPort = inbox.Email.SmtpPort
-			payload.Username = inbox.Email.Username
-		}
-	}
-
 	return payload
 }
 
@@ -178,42 +208,6 @@ func (inbox *Inbox) ToPublicPayload() types.InboxPayload {
 		AutoResponderMessage:  inbox.AutoResponderMessage,
 	}
 
-	// Add public-facing type-specific fields based on inbox type
-	switch inbox.Type {
-	case InboxTypeWebChat:
-		if inbox.WebChat != nil {
-			payload.WelcomeMessage = inbox.WebChat.WelcomeMessage
-			payload.OutsideHoursMessage = inbox.WebChat.OutsideHoursMessage
-			payload.WidgetCustomization = inbox.WebChat.WidgetCustomization
-			payload.PreChatForm = &inbox.WebChat.PreChatForm
-
-			// Handle working hours
-			workingHours := inbox.WebChat.WorkingHours
-			if workingHours != nil {
-				payload.WorkingHours = workingHours
-
-				// Determine if working hours are enabled
-				workingHoursEnabled := false
-				for _, wh := range workingHours {
-					if wh.Enabled {
-						workingHoursEnabled = true
-						break
-					}
-				}
-				payload.WorkingHoursEnabled = workingHoursEnabled
-			}
-		}
-	case InboxTypeEmail:
-		if inbox.Email != nil {
-			// Use the new statically typed fields
-			payload.ImapServer = inbox.Email.ImapServer
-			payload.ImapPort = inbox.Email.ImapPort
-			payload.SmtpServer = inbox.Email.SmtpServer
-			payload.SmtpPort = inbox.Email.SmtpPort
-			payload.Username = inbox.Email.Username
-		}
-	}
-
 	return payload
 }
 
@@ -230,16 +224,15 @@ func GetInboxesByUserID(db *gorm.DB, userID string) ([]Inbox, error) {
 }
 
 // GetInboxesByUserIDWithPreload retrieves all inboxes associated with a user
-// and preloads the related users for each inbox and type-specific configurations
+// and preloads the related users for each inbox. The type-specific configurations
+// are loaded by the inboxes' channels.
 func GetInboxesByUserIDWithPreload(db *gorm.DB, userID string) ([]Inbox, error) {
 	var inboxes []Inbox
 
-	// Query inboxes and preload the users and type-specific configurations
+	// Query inboxes and preload the users
 	err := db.Joins("JOIN inbox_users ON inbox_users.inbox_id = inboxes.id").
 		Where("inbox_users.user_id = ?", userID).
 		Preload("Users").
-		Preload("WebChat").
-		Preload("Email").
 		Find(&inboxes).Error
 
 	return inboxes, err
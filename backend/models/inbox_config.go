@@ -0,0 +1,55 @@
+package models
+
+import (
+	"live-chat-server/types"
+)
+
+// InboxConfig is implemented by the type-specific configuration model of each channel.
+// The channel of the inbox type loads it and serializes it into the inbox payloads.
+type InboxConfig interface {
+	// SetInboxID links the configuration to its inbox
+	SetInboxID(inboxID string)
+}
+
+// SetConfig sets the type-specific configuration of the inbox
+func (inbox *Inbox) SetConfig(config InboxConfig) {
+	inbox.config = config
+}
+
+// Config returns the type-specific configuration of the inbox, or nil if it has not been loaded
+func (inbox *Inbox) Config() InboxConfig {
+	return inbox.config
+}
+
+// DefaultWorkingHours returns the working hours of a new web chat inbox
+func DefaultWorkingHours() types.WorkingHoursMap {
+	return types.WorkingHoursMap{
+		"monday":    types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
+		"tuesday":   types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
+		"wednesday": types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
+		"thursday":  types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
+		"friday":    types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: true},
+		"saturday":  types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: false},
+		"sunday":    types.WorkingHours{StartTime: "09:00", EndTime: "17:00", Enabled: false},
+	}
+}
+
+func (w *InboxWebChat) SetInboxID(inboxID string) {
+	w.InboxID = inboxID
+}
+
+func (e *InboxEmail) SetInboxID(inboxID string) {
+	e.InboxID = inboxID
+}
+
+func (s *InboxSMS) SetInboxID(inboxID string) {
+	s.InboxID = inboxID
+}
+
+func (w *InboxWhatsApp) SetInboxID(inboxID string) {
+	w.InboxID = inboxID
+}
+
+func (a *InboxAPI) SetInboxID(inboxID string) {
+	a.InboxID = inboxID
+}
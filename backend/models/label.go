@@ -0,0 +1,48 @@
+package models
+
+import (
+	"live-chat-server/types"
+	"time"
+)
+
+// Label categorizes conversations within a company
+type Label struct {
+	ID          string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	CompanyID   string    `gorm:"type:uuid;not null;uniqueIndex:idx_labels_company_name" json:"company_id"`
+	Name        string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_labels_company_name" json:"name"`
+	Color       string    `gorm:"type:varchar(7);not null;default:'#6B7280'" json:"color"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Company *Company `gorm:"foreignKey:CompanyID" json:"-"`
+}
+
+func (l *Label) ToPayload() types.LabelPayload {
+	return types.LabelPayload{
+		ID:          l.ID,
+		Name:        l.Name,
+		Color:       l.Color,
+		Description: l.Description,
+	}
+}
+
+func (l *Label) ToResponse() interface{} {
+	return map[string]interface{}{
+		"id":          l.ID,
+		"name":        l.Name,
+		"color":       l.Color,
+		"description": l.Description,
+		"created_at":  l.CreatedAt,
+		"updated_at":  l.UpdatedAt,
+	}
+}
+
+// LabelsToPayload converts labels to their payloads
+func LabelsToPayload(labels []Label) []types.LabelPayload {
+	payload := make([]types.LabelPayload, 0, len(labels))
+	for _, label := range labels {
+		payload = append(payload, label.ToPayload())
+	}
+	return payload
+}
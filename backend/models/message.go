@@ -1,9 +1,13 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"live-chat-server/types"
 	"live-chat-server/utils"
+	"path"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -12,6 +16,21 @@ import (
 type MessageType string
 type SenderType string
 
+var (
+	ErrMessageNotOwnMessage     = errors.New("only the sender of a message can change it")
+	ErrMessageNotEditable       = errors.New("the message cannot be edited")
+	ErrMessageEditWindowExpired = errors.New("the message can no longer be changed")
+	ErrMessageDeleted           = errors.New("the message has been deleted")
+	ErrMessageContentRequired   = errors.New("the message content is required")
+	ErrReplyToMessageNotFound   = errors.New("the quoted message is not part of the conversation")
+	ErrReplyToPrivateMessage    = errors.New("a private note cannot be quoted in a public message")
+	ErrInvalidMentions          = errors.New("mentions must be a list of user IDs")
+	ErrMentionNotInboxMember    = errors.New("mentioned users must be members of the inbox")
+)
+
+// quotedContentLength is how many characters of a quoted message are shown in a reply
+const quotedContentLength = 140
+
 const (
 	MessageTypeText   MessageType = "text"
 	MessageTypeImage  MessageType = "image"
@@ -36,12 +55,21 @@ type Message struct {
 	Content        string         `gorm:"type:text;not null" json:"content"`
 	Metadata       interface{}    `gorm:"type:jsonb;serializer:json" json:"metadata"` // For storing additional data like file info
 	Private        bool           `gorm:"type:boolean;not null;default:false" json:"private"`
+	EditedAt       *time.Time     `json:"edited_at"`
+	TombstonedAt   *time.Time     `gorm:"index" json:"tombstoned_at"` // When the sender deleted the message, it is kept without its content
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// The earlier message of the conversation this message replies to
+	ReplyToMessageID *string `gorm:"type:uuid;index" json:"reply_to_message_id"`
+
+	// SearchVector is generated by Postgres from the content, it is only ever used in search queries
+	SearchVector string `gorm:"type:tsvector GENERATED ALWAYS AS (to_tsvector('simple', coalesce(content, ''))) STORED;index:idx_messages_search_vector,type:gin;->:false;<-:false" json:"-"`
+
 	// Relationships
 	Conversation  Conversation `gorm:"foreignKey:ConversationID" json:"conversation"`
+	ReplyTo       *Message     `gorm:"foreignKey:ReplyToMessageID" json:"reply_to,omitempty"`
 	AgentSender   *User        `gorm:"-"` // Used when SenderType is "agent"
 	ContactSender *Contact     `gorm:"-"` // Used when SenderType is "contact"
 }
@@ -152,10 +180,209 @@ func (m *Message) GetSenderType() string {
 	return string(m.SenderType)
 }
 
+// GetEmailMetadata returns the email headers stored on the message, or nil if it
+// was not received or sent by email
+func (m *Message) GetEmailMetadata() *types.EmailMessageMetadata {
+	var emailMetadata types.EmailMessageMetadata
+	if !m.decodeMetadata("email", &emailMetadata) || emailMetadata.MessageID == "" {
+		return nil
+	}
+
+	return &emailMetadata
+}
+
+// SetEmailMetadata stores the email headers on the message, keeping any other metadata
+func (m *Message) SetEmailMetadata(emailMetadata types.EmailMessageMetadata) {
+	m.setMetadata("email", emailMetadata)
+}
+
+// GetSMSMetadata returns the SMS details stored on the message, or nil if it
+// was not received or sent by SMS
+func (m *Message) GetSMSMetadata() *types.SMSMessageMetadata {
+	var smsMetadata types.SMSMessageMetadata
+	if !m.decodeMetadata("sms", &smsMetadata) || smsMetadata.MessageID == "" {
+		return nil
+	}
+
+	return &smsMetadata
+}
+
+// SetSMSMetadata stores the SMS details on the message, keeping any other metadata
+func (m *Message) SetSMSMetadata(smsMetadata types.SMSMessageMetadata) {
+	m.setMetadata("sms", smsMetadata)
+}
+
+// GetWhatsAppMetadata returns the WhatsApp details stored on the message, or nil if it
+// was not received or sent through WhatsApp
+func (m *Message) GetWhatsAppMetadata() *types.WhatsAppMessageMetadata {
+	var whatsAppMetadata types.WhatsAppMessageMetadata
+	if !m.decodeMetadata("whatsapp", &whatsAppMetadata) || (whatsAppMetadata.MessageID == "" && whatsAppMetadata.Error == "") {
+		return nil
+	}
+
+	return &whatsAppMetadata
+}
+
+// SetWhatsAppMetadata stores the WhatsApp details on the message, keeping any other metadata
+func (m *Message) SetWhatsAppMetadata(whatsAppMetadata types.WhatsAppMessageMetadata) {
+	m.setMetadata("whatsapp", whatsAppMetadata)
+}
+
+// GetWhatsAppTemplate returns the template an agent attached to the message, if any
+func (m *Message) GetWhatsAppTemplate() *types.WhatsAppTemplate {
+	var template types.WhatsAppTemplate
+	if !m.decodeMetadata("whatsapp_template", &template) || template.Name == "" {
+		return nil
+	}
+
+	return &template
+}
+
+// GetMentionedUserIDs returns the users mentioned in a private note
+func (m *Message) GetMentionedUserIDs() []string {
+	var userIDs []string
+	if !m.Private || !m.decodeMetadata("mentions", &userIDs) {
+		return nil
+	}
+
+	return utils.Unique(userIDs)
+}
+
+// ValidateMentions returns an error unless everyone mentioned in the note is one of the inbox members
+func (m *Message) ValidateMentions(members []User) error {
+	metadataMap, ok := m.Metadata.(map[string]interface{})
+	if !ok || metadataMap["mentions"] == nil {
+		return nil
+	}
+
+	var userIDs []string
+	if !m.decodeMetadata("mentions", &userIDs) {
+		return ErrInvalidMentions
+	}
+
+	for _, userID := range userIDs {
+		isMember := false
+		for _, member := range members {
+			if member.ID == userID {
+				isMember = true
+				break
+			}
+		}
+
+		if !isMember {
+			return ErrMentionNotInboxMember
+		}
+	}
+
+	return nil
+}
+
+// decodeMetadata decodes the metadata stored under key into out
+func (m *Message) decodeMetadata(key string, out interface{}) bool {
+	metadataMap, ok := m.Metadata.(map[string]interface{})
+	if !ok || metadataMap[key] == nil {
+		return false
+	}
+
+	bytes, err := json.Marshal(metadataMap[key])
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal(bytes, out) == nil
+}
+
+// setMetadata stores value under key, keeping any other metadata
+func (m *Message) setMetadata(key string, value interface{}) {
+	metadataMap, ok := m.Metadata.(map[string]interface{})
+	if !ok || metadataMap == nil {
+		metadataMap = make(map[string]interface{})
+	}
+
+	metadataMap[key] = value
+	m.Metadata = metadataMap
+}
+
+// IsTombstone returns whether the sender deleted the message
+func (m *Message) IsTombstone() bool {
+	return m.TombstonedAt != nil
+}
+
+// CanBeChangedBy returns an error if the sender cannot edit or delete the message at the given time.
+// A window of 0 means messages cannot be changed once sent.
+func (m *Message) CanBeChangedBy(senderType SenderType, senderID string, window time.Duration, now time.Time) error {
+	if m.SenderType != senderType || m.SenderID == nil || *m.SenderID != senderID {
+		return ErrMessageNotOwnMessage
+	}
+	if m.IsTombstone() {
+		return ErrMessageDeleted
+	}
+	if now.After(m.CreatedAt.Add(window)) {
+		return ErrMessageEditWindowExpired
+	}
+	return nil
+}
+
+// CanReplyTo returns an error if the message cannot quote the original message
+func (m *Message) CanReplyTo(original *Message) error {
+	if original.ConversationID != m.ConversationID {
+		return ErrReplyToMessageNotFound
+	}
+	if original.Private && !m.Private {
+		return ErrReplyToPrivateMessage
+	}
+	return nil
+}
+
+// ToQuotedPayload converts a Message to the compact preview shown in replies to it.
+// Deleted messages keep their place in the reply without their content.
+func (m *Message) ToQuotedPayload() *types.QuotedMessagePayload {
+	payload := &types.QuotedMessagePayload{
+		ID:     m.ID,
+		Sender: m.toSender(),
+		Type:   string(m.Type),
+	}
+
+	if m.IsTombstone() || m.DeletedAt.Valid {
+		payload.Deleted = true
+		return payload
+	}
+
+	content := m.Content
+	if m.Type == MessageTypeFile || m.Type == MessageTypeImage {
+		content = path.Base(content)
+	}
+
+	if runes := []rune(content); len(runes) > quotedContentLength {
+		content = strings.TrimSpace(string(runes[:quotedContentLength])) + "..."
+	}
+	payload.Content = content
+
+	return payload
+}
+
+// toSender returns who sent the message, as shown to clients
+func (m *Message) toSender() types.Sender {
+	switch {
+	case m.SenderType == SenderTypeSystem:
+		return types.Sender{Type: types.SenderType(m.SenderType), Name: "System"}
+	case m.SenderType == SenderTypeBot:
+		return types.Sender{Type: types.SenderType(m.SenderType), Name: "Bot"}
+	case m.SenderID != nil:
+		return types.Sender{
+			ID:        *m.SenderID,
+			Type:      types.SenderType(m.SenderType),
+			Name:      m.GetSenderName(),
+			AvatarUrl: m.GetSenderAvatarUrl(),
+		}
+	}
+	return types.Sender{}
+}
+
 // ToPayload converts a Message to a payload for API responses
 func (m *Message) ToPayload() types.MessagePayload {
 	// Create the full URL to the content (which holds the path to the file)
-	if m.Type == MessageTypeFile {
+	if (m.Type == MessageTypeFile || m.Type == MessageTypeImage) && !m.IsTombstone() {
 		m.Content = utils.Asset(m.Content)
 
 		if m.Metadata != nil {
@@ -167,6 +394,7 @@ func (m *Message) ToPayload() types.MessagePayload {
 	}
 
 	payload := types.MessagePayload{
+		ID:             m.ID,
 		ConversationID: m.ConversationID,
 		Content:        m.Content,
 		Type:           string(m.Type),
@@ -175,26 +403,26 @@ func (m *Message) ToPayload() types.MessagePayload {
 		Timestamp:      m.CreatedAt.Format("01/02/2006 15:04:05"),
 	}
 
-	// Handle system messages which don't have a sender
-	if m.SenderType == SenderTypeSystem {
-		payload.Name = "System"
-		payload.Sender = types.Sender{
-			Type: types.SenderType(m.SenderType),
-			Name: "System",
-		}
-	} else if m.SenderType == SenderTypeBot {
-		payload.Name = "Bot"
-		payload.Sender = types.Sender{
-			Type: types.SenderType(m.SenderType),
-			Name: "Bot",
-		}
-	} else if m.SenderID != nil {
-		payload.Name = m.GetSenderName()
-		payload.Sender = types.Sender{
-			ID:        *m.SenderID,
-			Type:      types.SenderType(m.SenderType),
-			Name:      m.GetSenderName(),
-			AvatarUrl: m.GetSenderAvatarUrl(),
+	if m.EditedAt != nil {
+		payload.Edited = true
+		payload.EditedAt = m.EditedAt.Format(time.RFC3339)
+	}
+
+	if m.IsTombstone() {
+		payload.Content = ""
+		payload.Metadata = nil
+		payload.Deleted = true
+		payload.DeletedAt = m.TombstonedAt.Format(time.RFC3339)
+	}
+
+	// System and bot messages don't have a sender
+	payload.Sender = m.toSender()
+	payload.Name = payload.Sender.Name
+
+	if m.ReplyToMessageID != nil {
+		payload.ReplyToMessageID = *m.ReplyToMessageID
+		if m.ReplyTo != nil {
+			payload.ReplyTo = m.ReplyTo.ToQuotedPayload()
 		}
 	}
 
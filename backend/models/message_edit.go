@@ -0,0 +1,32 @@
+package models
+
+import (
+	"live-chat-server/types"
+	"time"
+)
+
+// MessageEdit records a change to the content of a message. Deleting a message is recorded
+// as an edit to empty content.
+type MessageEdit struct {
+	ID              string     `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	MessageID       string     `gorm:"type:uuid;not null;index"`
+	EditorType      SenderType `gorm:"type:varchar(10);not null"`
+	EditorID        string     `gorm:"type:uuid;not null"`
+	PreviousContent string     `gorm:"type:text;not null"`
+	Content         string     `gorm:"type:text;not null"`
+	CreatedAt       time.Time
+
+	Message *Message `gorm:"foreignKey:MessageID"`
+}
+
+func (e *MessageEdit) ToResponse() types.MessageEditPayload {
+	return types.MessageEditPayload{
+		ID:              e.ID,
+		MessageID:       e.MessageID,
+		EditorType:      string(e.EditorType),
+		EditorID:        e.EditorID,
+		PreviousContent: e.PreviousContent,
+		Content:         e.Content,
+		CreatedAt:       e.CreatedAt.Format(time.RFC3339),
+	}
+}
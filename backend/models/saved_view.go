@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+type SavedViewVisibility string
+
+const (
+	// SavedViewVisibilityPrivate views are only seen by the agent who saved them
+	SavedViewVisibilityPrivate SavedViewVisibility = "private"
+	// SavedViewVisibilityTeam views are seen by the members of the view's inbox
+	SavedViewVisibilityTeam SavedViewVisibility = "team"
+	// SavedViewVisibilityCompany views are seen by every agent of the company
+	SavedViewVisibilityCompany SavedViewVisibility = "company"
+)
+
+// ConversationFilterDefinition is a conversation filter as agents submit it. Saved views keep
+// it as it was submitted, so "me" stands for whichever agent runs the view.
+type ConversationFilterDefinition struct {
+	Status    string `json:"status,omitempty" validate:"omitempty,oneof=active pending closed resolved snoozed"`
+	InboxID   string `json:"inbox_id,omitempty" validate:"omitempty,uuid"`
+	ContactID string `json:"contact_id,omitempty" validate:"omitempty,uuid"`
+	// Assignee is "me", "unassigned" or an agent ID
+	Assignee string   `json:"assignee,omitempty" validate:"omitempty,oneof=me unassigned|uuid"`
+	LabelIDs []string `json:"label_ids,omitempty" validate:"dive,uuid"`
+	From     string   `json:"from,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	To       string   `json:"to,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Sort     string   `json:"sort,omitempty" validate:"omitempty,oneof=last_activity created_at priority"`
+}
+
+// SavedView is a named conversation filter an agent saved, and possibly shared
+type SavedView struct {
+	ID         string                       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	CompanyID  string                       `gorm:"type:uuid;not null;index" json:"company_id"`
+	UserID     string                       `gorm:"type:uuid;not null;index" json:"user_id"`
+	InboxID    *string                      `gorm:"type:uuid;index" json:"inbox_id"` // The team a team view is shared with
+	Name       string                       `gorm:"type:varchar(100);not null" json:"name"`
+	Visibility SavedViewVisibility          `gorm:"type:varchar(20);not null;default:'private'" json:"visibility"`
+	Filter     ConversationFilterDefinition `gorm:"type:jsonb;serializer:json" json:"filter"`
+	CreatedAt  time.Time                    `json:"created_at"`
+	UpdatedAt  time.Time                    `json:"updated_at"`
+
+	Company *Company `gorm:"foreignKey:CompanyID;constraint:OnDelete:CASCADE" json:"-"`
+	User    *User    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Inbox   *Inbox   `gorm:"foreignKey:InboxID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// IsShared returns whether other agents than its owner see the view
+func (v *SavedView) IsShared() bool {
+	return v.Visibility != SavedViewVisibilityPrivate
+}
+
+// CanManage returns whether the user can change or delete the view, shared views are managed by admins
+func (v *SavedView) CanManage(user *User) bool {
+	if v.IsShared() {
+		return user.Role == string(RoleAdmin) || user.Role == string(RoleSuperAdmin)
+	}
+	return v.UserID == user.ID
+}
+
+func (v *SavedView) ToResponse() interface{} {
+	return map[string]interface{}{
+		"id":         v.ID,
+		"user_id":    v.UserID,
+		"inbox_id":   v.InboxID,
+		"name":       v.Name,
+		"visibility": v.Visibility,
+		"filter":     v.Filter,
+		"created_at": v.CreatedAt,
+		"updated_at": v.UpdatedAt,
+	}
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"live-chat-server/sla"
+	"live-chat-server/types"
+	"time"
+)
+
+// SLATarget is a response or resolution time a conversation is measured against
+type SLATarget string
+
+const (
+	SLATargetFirstResponse SLATarget = "first_response"
+	SLATargetNextResponse  SLATarget = "next_response"
+	SLATargetResolution    SLATarget = "resolution"
+)
+
+// SLAPolicy defines the response and resolution times promised for the conversations of an inbox
+type SLAPolicy struct {
+	ID          string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CompanyID   string `gorm:"type:uuid;not null;index"`
+	Name        string `gorm:"not null"`
+	Description string
+	// Targets in minutes, 0 disables the target
+	FirstResponseMinutes int `gorm:"default:0"`
+	NextResponseMinutes  int `gorm:"default:0"`
+	ResolutionMinutes    int `gorm:"default:0"`
+	// WarningPercent is how far into a target agents are warned, e.g. 80 for 80% of the time elapsed
+	WarningPercent    int                   `gorm:"default:80"`
+	BusinessHoursOnly bool                  `gorm:"default:false"`
+	BusinessHours     types.WorkingHoursMap `gorm:"type:jsonb"`
+	Timezone          string                `gorm:"type:varchar(64);not null;default:'UTC'"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+
+	Company *Company `gorm:"foreignKey:CompanyID"`
+}
+
+// TableName specifies the table name for SLAPolicy
+func (SLAPolicy) TableName() string {
+	return "sla_policies"
+}
+
+// TargetMinutes returns the minutes allowed for the target, or 0 if the policy does not set it
+func (p *SLAPolicy) TargetMinutes(target SLATarget) int {
+	switch target {
+	case SLATargetFirstResponse:
+		return p.FirstResponseMinutes
+	case SLATargetNextResponse:
+		return p.NextResponseMinutes
+	case SLATargetResolution:
+		return p.ResolutionMinutes
+	}
+	return 0
+}
+
+// DueAt returns when the target is due for a clock started at from, or nil if the policy does not set it
+func (p *SLAPolicy) DueAt(target SLATarget, from time.Time) *time.Time {
+	minutes := p.TargetMinutes(target)
+	if minutes <= 0 {
+		return nil
+	}
+
+	// Stored timestamps lose sub-second precision, and the scheduled checks compare against them
+	dueAt := p.addTime(from, time.Duration(minutes)*time.Minute).Truncate(time.Second)
+	return &dueAt
+}
+
+// WarnAt returns when agents are warned about the target, or nil if the policy does not set it
+func (p *SLAPolicy) WarnAt(target SLATarget, from time.Time) *time.Time {
+	minutes := p.TargetMinutes(target)
+	if minutes <= 0 || p.WarningPercent <= 0 || p.WarningPercent >= 100 {
+		return nil
+	}
+
+	warnAt := p.addTime(from, time.Duration(minutes)*time.Minute*time.Duration(p.WarningPercent)/100)
+	return &warnAt
+}
+
+// addTime adds the duration, counting only business hours if the policy is limited to them
+func (p *SLAPolicy) addTime(from time.Time, duration time.Duration) time.Time {
+	if !p.BusinessHoursOnly {
+		return from.Add(duration).UTC()
+	}
+
+	return sla.AddBusinessTime(from, duration, p.BusinessHours, p.Location()).UTC()
+}
+
+// Location returns the time zone the business hours are in
+func (p *SLAPolicy) Location() *time.Location {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func (p *SLAPolicy) ToResponse() interface{} {
+	return map[string]interface{}{
+		"id":                     p.ID,
+		"name":                   p.Name,
+		"description":            p.Description,
+		"first_response_minutes": p.FirstResponseMinutes,
+		"next_response_minutes":  p.NextResponseMinutes,
+		"resolution_minutes":     p.ResolutionMinutes,
+		"warning_percent":        p.WarningPercent,
+		"business_hours_only":    p.BusinessHoursOnly,
+		"business_hours":         p.BusinessHours,
+		"timezone":               p.Timezone,
+		"created_at":             p.CreatedAt,
+		"updated_at":             p.UpdatedAt,
+	}
+}
@@ -10,6 +10,17 @@ const (
 	UserNotificationTypeAssignedConversation UserNotificationType = "assigned_conversation"
 	UserNotificationTypeNewMessage           UserNotificationType = "new_message"
 	UserNotificationTypeMention              UserNotificationType = "mention"
+	UserNotificationTypeSnoozeExpired        UserNotificationType = "snooze_expired"
+	UserNotificationTypeSLAWarning           UserNotificationType = "sla_warning"
+	UserNotificationTypeSLABreached          UserNotificationType = "sla_breached"
+
+	// Notifications of the conversations an agent follows or collaborates on
+	UserNotificationTypeParticipantAdded UserNotificationType = "participant_added"
+	UserNotificationTypeFollowedMessage  UserNotificationType = "followed_message"
+	UserNotificationTypeFollowedStatus   UserNotificationType = "followed_status"
+	UserNotificationTypeFollowedMention  UserNotificationType = "followed_mention"
+
+	UserNotificationTypeTranscriptReady UserNotificationType = "transcript_ready"
 )
 
 type UserNotification struct {
@@ -12,6 +12,7 @@ type AnalyticsRepository interface {
 	GetConversationsByAgent(companyID string, startDate, endDate time.Time) ([]AgentConversationStats, error)
 	GetMessageStats(companyID string, startDate, endDate time.Time) (*MessageStats, error)
 	GetConversationStatusStats(companyID string, startDate, endDate time.Time) (*ConversationStatusStats, error)
+	GetSLAStats(companyID string, startDate, endDate time.Time) (*SLAStats, error)
 }
 
 type ConversationStats struct {
@@ -41,6 +42,21 @@ type ConversationStatusStats struct {
 	Resolved int64 `json:"resolved"`
 }
 
+type SLATargetStats struct {
+	Tracked    int64   `json:"tracked"`
+	Breached   int64   `json:"breached"`
+	BreachRate float64 `json:"breach_rate"`
+}
+
+type SLAStats struct {
+	TrackedConversations  int64          `json:"tracked_conversations"`
+	BreachedConversations int64          `json:"breached_conversations"`
+	BreachRate            float64        `json:"breach_rate"`
+	FirstResponse         SLATargetStats `json:"first_response"`
+	NextResponse          SLATargetStats `json:"next_response"`
+	Resolution            SLATargetStats `json:"resolution"`
+}
+
 type analyticsRepository struct {
 	db *gorm.DB
 }
@@ -152,3 +168,60 @@ func (r *analyticsRepository) GetConversationStatusStats(companyID string, start
 
 	return &stats, err
 }
+
+func (r *analyticsRepository) GetSLAStats(companyID string, startDate, endDate time.Time) (*SLAStats, error) {
+	var counts struct {
+		TrackedConversations  int64
+		BreachedConversations int64
+		FirstResponseTracked  int64
+		FirstResponseBreached int64
+		NextResponseTracked   int64
+		NextResponseBreached  int64
+		ResolutionTracked     int64
+		ResolutionBreached    int64
+	}
+
+	// Next response due dates are cleared once met, so every conversation that has had a first
+	// response counts towards the next response target
+	err := r.db.Raw(`
+		SELECT 
+			COUNT(*) as tracked_conversations,
+			COUNT(CASE WHEN first_response_breached OR next_response_breached OR resolution_breached THEN 1 END) as breached_conversations,
+			COUNT(CASE WHEN first_response_due_at IS NOT NULL THEN 1 END) as first_response_tracked,
+			COUNT(CASE WHEN first_response_breached THEN 1 END) as first_response_breached,
+			COUNT(CASE WHEN first_response_at IS NOT NULL OR next_response_breached THEN 1 END) as next_response_tracked,
+			COUNT(CASE WHEN next_response_breached THEN 1 END) as next_response_breached,
+			COUNT(CASE WHEN resolution_due_at IS NOT NULL THEN 1 END) as resolution_tracked,
+			COUNT(CASE WHEN resolution_breached THEN 1 END) as resolution_breached
+		FROM conversations
+		WHERE company_id = ? AND sla_policy_id IS NOT NULL AND deleted_at IS NULL AND created_at BETWEEN ? AND ?
+	`, companyID, startDate, endDate).Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &SLAStats{
+		TrackedConversations:  counts.TrackedConversations,
+		BreachedConversations: counts.BreachedConversations,
+		BreachRate:            breachRate(counts.BreachedConversations, counts.TrackedConversations),
+		FirstResponse:         newSLATargetStats(counts.FirstResponseTracked, counts.FirstResponseBreached),
+		NextResponse:          newSLATargetStats(counts.NextResponseTracked, counts.NextResponseBreached),
+		Resolution:            newSLATargetStats(counts.ResolutionTracked, counts.ResolutionBreached),
+	}, nil
+}
+
+func newSLATargetStats(tracked, breached int64) SLATargetStats {
+	return SLATargetStats{
+		Tracked:    tracked,
+		Breached:   breached,
+		BreachRate: breachRate(breached, tracked),
+	}
+}
+
+// breachRate returns the percentage of tracked conversations that were breached
+func breachRate(breached, tracked int64) float64 {
+	if tracked == 0 {
+		return 0
+	}
+	return float64(breached) / float64(tracked) * 100
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"errors"
 	"live-chat-server/models"
 
 	"gorm.io/gorm"
@@ -9,6 +10,9 @@ import (
 type ContactRepository interface {
 	GetContactByID(id string) (*models.Contact, error)
 	GetContactByIDAndCompanyID(id string, companyID string) (*models.Contact, error)
+	ResolveContactByID(id string) (*models.Contact, error)
+	GetContactByEmailAndCompanyID(email string, companyID string) (*models.Contact, error)
+	GetContactByPhoneAndCompanyID(phone string, companyID string) (*models.Contact, error)
 	GetContactsByCompanyID(companyID string) ([]models.Contact, error)
 	CreateContact(contact *models.Contact) error
 	UpdateContact(contact *models.Contact) error
@@ -18,6 +22,9 @@ type ContactRepository interface {
 	GetContactNotesByContactID(contactID string, orderBy *string) ([]models.ContactNote, error)
 }
 
+// maxContactMergeDepth limits how many merges are followed when resolving a merged contact
+const maxContactMergeDepth = 10
+
 type contactRepository struct {
 	db *gorm.DB
 }
@@ -43,6 +50,62 @@ func (r *contactRepository) GetContactByIDAndCompanyID(id string, companyID stri
 	return &contact, nil
 }
 
+// ResolveContactByID finds a contact by ID, following merges to the contact it was merged into
+func (r *contactRepository) ResolveContactByID(id string) (*models.Contact, error) {
+	contact, err := r.GetContactByID(id)
+	for depth := 0; errors.Is(err, gorm.ErrRecordNotFound) && depth < maxContactMergeDepth; depth++ {
+		var merge models.ContactMerge
+		if mergeErr := r.db.Where("secondary_contact_id = ? AND undone_at IS NULL", id).Order("created_at DESC").First(&merge).Error; mergeErr != nil {
+			return nil, err
+		}
+
+		id = merge.PrimaryContactID
+		contact, err = r.GetContactByID(id)
+	}
+	return contact, err
+}
+
+// GetContactByEmailAndCompanyID finds a contact by email, falling back to the contact a matching merged contact was merged into
+func (r *contactRepository) GetContactByEmailAndCompanyID(email string, companyID string) (*models.Contact, error) {
+	var contact models.Contact
+	if err := r.db.Order("created_at ASC").First(&contact, "LOWER(email) = LOWER(?) AND company_id = ?", email, companyID).Error; err != nil {
+		if merged, mergedErr := r.findMergedContact("LOWER(email) = LOWER(?) AND company_id = ?", email, companyID); mergedErr == nil {
+			return merged, nil
+		}
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// GetContactByPhoneAndCompanyID finds a contact by phone number, ignoring formatting characters
+func (r *contactRepository) GetContactByPhoneAndCompanyID(phone string, companyID string) (*models.Contact, error) {
+	var contact models.Contact
+	if err := r.db.Order("created_at ASC").First(&contact,
+		"regexp_replace(phone, '[^0-9+]', '', 'g') = regexp_replace(?, '[^0-9+]', '', 'g') AND company_id = ?", phone, companyID,
+	).Error; err != nil {
+		if merged, mergedErr := r.findMergedContact("regexp_replace(phone, '[^0-9+]', '', 'g') = regexp_replace(?, '[^0-9+]', '', 'g') AND company_id = ?", phone, companyID); mergedErr == nil {
+			return merged, nil
+		}
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// findMergedContact finds the most recently merged contact matching the query and resolves it to the contact it was merged into
+func (r *contactRepository) findMergedContact(query string, args ...interface{}) (*models.Contact, error) {
+	mergedIDs := r.db.Model(&models.ContactMerge{}).Select("secondary_contact_id").Where("undone_at IS NULL")
+
+	var merged models.Contact
+	if err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND id IN (?)", mergedIDs).
+		Where(query, args...).
+		Order("deleted_at DESC").
+		First(&merged).Error; err != nil {
+		return nil, err
+	}
+	return r.ResolveContactByID(merged.ID)
+}
+
 func (r *contactRepository) GetContactsByCompanyID(companyID string) ([]models.Contact, error) {
 	var contacts []models.Contact
 	if err := r.db.Where("company_id = ?", companyID).Find(&contacts).Error; err != nil {
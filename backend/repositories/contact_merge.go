@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"live-chat-server/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ContactMergeRepository interface {
+	GetDuplicateContacts(contact *models.Contact) ([]models.Contact, error)
+	GetContactMergeByIDAndCompanyID(id string, companyID string) (*models.ContactMerge, error)
+	GetContactMergesByContactID(contactID string, companyID string) ([]models.ContactMerge, error)
+	MergeContacts(merge *models.ContactMerge, primary *models.Contact, secondary *models.Contact) error
+	UndoContactMerge(merge *models.ContactMerge) error
+}
+
+type contactMergeRepository struct {
+	db *gorm.DB
+}
+
+func NewContactMergeRepository(db *gorm.DB) ContactMergeRepository {
+	return &contactMergeRepository{db: db}
+}
+
+// GetDuplicateContacts finds the company's other contacts sharing the contact's email or phone number
+func (r *contactMergeRepository) GetDuplicateContacts(contact *models.Contact) ([]models.Contact, error) {
+	var conditions []string
+	var args []interface{}
+
+	if contact.Email != nil && *contact.Email != "" {
+		conditions = append(conditions, "LOWER(email) = LOWER(?)")
+		args = append(args, *contact.Email)
+	}
+	if contact.Phone != nil && *contact.Phone != "" {
+		conditions = append(conditions, "(regexp_replace(phone, '[^0-9+]', '', 'g') = regexp_replace(?, '[^0-9+]', '', 'g') AND regexp_replace(phone, '[^0-9+]', '', 'g') <> '')")
+		args = append(args, *contact.Phone)
+	}
+
+	contacts := []models.Contact{}
+	if len(conditions) == 0 {
+		return contacts, nil
+	}
+
+	if err := r.db.Where("company_id = ? AND id <> ?", contact.CompanyID, contact.ID).
+		Where(strings.Join(conditions, " OR "), args...).
+		Order("created_at ASC").
+		Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// unscopedContacts preloads merged contacts even after they have been soft deleted
+func unscopedContacts(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+func (r *contactMergeRepository) GetContactMergeByIDAndCompanyID(id string, companyID string) (*models.ContactMerge, error) {
+	var merge models.ContactMerge
+	if err := r.db.Preload("PrimaryContact", unscopedContacts).Preload("SecondaryContact", unscopedContacts).
+		Where("id = ? AND company_id = ?", id, companyID).
+		First(&merge).Error; err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+// GetContactMergesByContactID returns the merges the contact took part in, newest first
+func (r *contactMergeRepository) GetContactMergesByContactID(contactID string, companyID string) ([]models.ContactMerge, error) {
+	var merges []models.ContactMerge
+	if err := r.db.Preload("PrimaryContact", unscopedContacts).Preload("SecondaryContact", unscopedContacts).
+		Where("company_id = ? AND (primary_contact_id = ? OR secondary_contact_id = ?)", companyID, contactID, contactID).
+		Order("created_at DESC").
+		Find(&merges).Error; err != nil {
+		return nil, err
+	}
+	return merges, nil
+}
+
+// MergeContacts moves the secondary contact's conversations, messages and notes onto the primary contact,
+// fills the primary contact's empty fields and soft deletes the secondary contact.
+// The merge is populated with what was moved so it can be undone.
+func (r *contactMergeRepository) MergeContacts(merge *models.ContactMerge, primary *models.Contact, secondary *models.Contact) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Conversation{}).Where("contact_id = ?", secondary.ID).Pluck("id", &merge.ConversationIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Message{}).Where("sender_type = ? AND sender_id = ?", models.SenderTypeContact, secondary.ID).Pluck("id", &merge.MessageIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ContactNote{}).Where("contact_id = ?", secondary.ID).Pluck("id", &merge.NoteIDs).Error; err != nil {
+			return err
+		}
+
+		if len(merge.ConversationIDs) > 0 {
+			if err := tx.Model(&models.Conversation{}).Where("id IN ?", merge.ConversationIDs).Update("contact_id", primary.ID).Error; err != nil {
+				return err
+			}
+		}
+		if len(merge.MessageIDs) > 0 {
+			if err := tx.Model(&models.Message{}).Where("id IN ?", merge.MessageIDs).Update("sender_id", primary.ID).Error; err != nil {
+				return err
+			}
+		}
+		if len(merge.NoteIDs) > 0 {
+			if err := tx.Model(&models.ContactNote{}).Where("id IN ?", merge.NoteIDs).Update("contact_id", primary.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		merge.FilledFields = models.FillEmptyFields(primary, secondary)
+		if err := tx.Model(primary).Select("name", "email", "phone", "company", "updated_at").Updates(primary).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(secondary).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(merge).Error
+	})
+}
+
+// UndoContactMerge restores the secondary contact and moves back what the merge moved, as long as it is still on the primary contact.
+// The merge must have its contacts loaded.
+func (r *contactMergeRepository) UndoContactMerge(merge *models.ContactMerge) error {
+	primary := merge.PrimaryContact
+	secondary := merge.SecondaryContact
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(merge.ConversationIDs) > 0 {
+			if err := tx.Model(&models.Conversation{}).Where("id IN ? AND contact_id = ?", merge.ConversationIDs, primary.ID).Update("contact_id", secondary.ID).Error; err != nil {
+				return err
+			}
+		}
+		if len(merge.MessageIDs) > 0 {
+			if err := tx.Model(&models.Message{}).Where("id IN ? AND sender_id = ?", merge.MessageIDs, primary.ID).Update("sender_id", secondary.ID).Error; err != nil {
+				return err
+			}
+		}
+		if len(merge.NoteIDs) > 0 {
+			if err := tx.Model(&models.ContactNote{}).Where("id IN ? AND contact_id = ?", merge.NoteIDs, primary.ID).Update("contact_id", secondary.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		models.ClearFilledFields(primary, secondary, merge.FilledFields)
+		if err := tx.Model(primary).Select("name", "email", "phone", "company", "updated_at").Updates(primary).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(secondary).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		secondary.DeletedAt = gorm.DeletedAt{}
+
+		now := time.Now()
+		merge.UndoneAt = &now
+		return tx.Model(merge).Update("undone_at", now).Error
+	})
+}
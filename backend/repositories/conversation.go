@@ -1,26 +1,132 @@
 package repositories
 
 import (
+	"encoding/json"
 	"live-chat-server/models"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ConversationRepository interface {
 	GetConversationsByCompanyID(companyID string, preloads ...string) ([]models.Conversation, error)
-	GetConversationsForUser(userID string, preloads ...string) ([]models.Conversation, error)
+	GetConversationsForUser(userID string, filter ConversationFilter, preloads ...string) ([]models.Conversation, string, error)
+	CountConversationsByStatusForUser(userID string, filter ConversationFilter) (map[models.ConversationStatus]int64, error)
+	CountConversationsForUser(userID string, filter ConversationFilter) (int64, error)
 	GetConversationByIdAndCompanyID(id string, companyID string, preloads ...string) (*models.Conversation, error)
 	GetConversationByID(id string, preloads ...string) (*models.Conversation, error)
 	CreateConversation(conversation *models.Conversation) error
 	UpdateConversation(conversation *models.Conversation) error
+	UpdateConversationSLA(conversation *models.Conversation) error
+	MarkTranscriptRequested(conversationID string, interval time.Duration) (bool, error)
 	CreateMessage(message *models.Message) (*models.Message, error)
+	CreateMessages(messages []*models.Message) ([]*models.Message, error)
 	PopulateSender(message *models.Message) (*models.Message, error)
 	GetActiveAssignedConversationsForUser(userID string) ([]models.Conversation, error)
+	GetUnassignedPendingConversationsByInboxID(inboxID string, preloads ...string) ([]models.Conversation, error)
 	GetConversationsByContactID(contactID string, preloads ...string) ([]models.Conversation, error)
 	DeleteConversationsByInboxID(inboxID string) ([]string, error)
 	GetMessageByID(id string) (*models.Message, error)
+	GetMessageByEmailMessageID(inboxID string, messageIDs ...string) (*models.Message, error)
+	GetLatestEmailMessage(conversationID string, before time.Time) (*models.Message, error)
+	UpdateMessage(message *models.Message) error
+	EditMessage(message *models.Message, edit *models.MessageEdit) error
+	GetMessageEdits(messageID string) ([]models.MessageEdit, error)
+	LoadMessagesPage(conversation *models.Conversation, page MessagePage) error
+	GetMentionsForUser(userID string, companyID string, limit, offset int) ([]models.Message, int64, error)
+	GetLatestMessage(conversationID string, includePrivate bool) (*models.Message, error)
+	GetMessageByChannelMessageID(inboxID string, channel string, messageID string) (*models.Message, error)
+	GetLatestMessageBySenderType(conversationID string, senderType models.SenderType) (*models.Message, error)
+	GetLatestOpenConversationForContact(inboxID string, contactID string, preloads ...string) (*models.Conversation, error)
 }
 
+// ConversationFilter narrows down the conversations listed for an agent
+type ConversationFilter struct {
+	// LabelIDs keeps the conversations having any of the labels
+	LabelIDs []string
+
+	Status    models.ConversationStatus
+	InboxID   string
+	ContactID string
+	// AssignedToID is an agent ID, or "unassigned" for conversations without one
+	AssignedToID string
+	// From and To bound when the conversations were created
+	From *time.Time
+	To   *time.Time
+
+	Sort ConversationSort
+	// Cursor lists the conversations after the conversation with this ID, in the sort order
+	Cursor string
+	Limit  int
+}
+
+// ConversationSort is the order conversations are listed in, newest or most urgent first
+type ConversationSort string
+
+const (
+	ConversationSortLastActivity ConversationSort = "last_activity"
+	ConversationSortCreatedAt    ConversationSort = "created_at"
+	ConversationSortPriority     ConversationSort = "priority"
+)
+
+const (
+	// DefaultConversationPageLimit is how many conversations are listed when no limit is asked for
+	DefaultConversationPageLimit = 25
+	// MaxConversationPageLimit is the most conversations listed at once
+	MaxConversationPageLimit = 100
+)
+
+// conversationActivity is when a conversation last had a message, or was created when it has none
+const conversationActivity = "COALESCE(last_message_at, created_at)"
+
+const (
+	// DefaultMessagePageLimit is how many messages are loaded when no limit is asked for
+	DefaultMessagePageLimit = 50
+	// MaxMessagePageLimit is the most messages loaded at once
+	MaxMessagePageLimit = 200
+)
+
+// MessagePage selects a page of a conversation's messages, ordered by (created_at, id).
+// Without a cursor the page holds the latest messages.
+type MessagePage struct {
+	// Before loads the messages sent before the message with this ID
+	Before string
+	// After loads the messages sent after the message with this ID
+	After string
+	Limit int
+	// IncludePrivate keeps private notes in the page, only agents can see them
+	IncludePrivate bool
+}
+
+// conversationPriorityOrder sorts the most urgent conversations first
+const conversationPriorityOrder = "CASE priority " +
+	"WHEN 'urgent' THEN 1 " +
+	"WHEN 'high' THEN 2 " +
+	"WHEN 'medium' THEN 3 " +
+	"WHEN 'low' THEN 4 " +
+	"ELSE 5 END"
+
+// conversationPriorityRanks matches conversationPriorityOrder, for comparing against a cursor
+var conversationPriorityRanks = map[models.ConversationPriority]int{
+	models.ConversationPriorityUrgent: 1,
+	models.ConversationPriorityHigh:   2,
+	models.ConversationPriorityMedium: 3,
+	models.ConversationPriorityLow:    4,
+}
+
+// conversationSLAColumns are only written by the SLA tracking, so saving a conversation
+// loaded before its SLA changed does not overwrite them
+var conversationSLAColumns = []string{
+	"sla_policy_id", "first_response_due_at", "next_response_due_at", "resolution_due_at", "first_response_at",
+	"first_response_breached", "next_response_breached", "resolution_breached",
+}
+
+// conversationUpdateOmits are left out when saving a conversation. Labels, participants and the other
+// associations have their own repositories, so the preloaded copies are not written back over changes
+// made since they were loaded. The transcript request time is only written by MarkTranscriptRequested.
+var conversationUpdateOmits = append([]string{clause.Associations, "transcript_requested_at"}, conversationSLAColumns...)
+
 type conversationRepository struct {
 	db *gorm.DB
 }
@@ -36,7 +142,7 @@ func (r *conversationRepository) ApplyPreloads(query *gorm.DB, preloads ...strin
 		if preload == "Messages" {
 			query = query.Preload(preload, func(db *gorm.DB) *gorm.DB {
 				return db.Order("created_at ASC")
-			})
+			}).Preload("Messages.ReplyTo", unscopedReplyTo)
 		} else {
 			query = query.Preload(preload)
 		}
@@ -72,9 +178,112 @@ func (r *conversationRepository) GetConversationsByCompanyID(companyID string, p
 	return conversations, nil
 }
 
-func (r *conversationRepository) GetConversationsForUser(userID string, preloads ...string) ([]models.Conversation, error) {
+func (r *conversationRepository) GetConversationsForUser(userID string, filter ConversationFilter, preloads ...string) ([]models.Conversation, string, error) {
+	query, err := r.conversationsForUser(userID, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultConversationPageLimit
+	} else if limit > MaxConversationPageLimit {
+		limit = MaxConversationPageLimit
+	}
+
+	if filter.Cursor != "" {
+		var cursor models.Conversation
+		if err := query.Session(&gorm.Session{}).First(&cursor, "id = ?", filter.Cursor).Error; err != nil {
+			return nil, "", err
+		}
+
+		activity := cursor.CreatedAt
+		if cursor.LastMessageAt != nil {
+			activity = *cursor.LastMessageAt
+		}
+
+		switch filter.Sort {
+		case ConversationSortCreatedAt:
+			query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+		case ConversationSortPriority:
+			rank, ok := conversationPriorityRanks[cursor.Priority]
+			if !ok {
+				rank = len(conversationPriorityRanks) + 1
+			}
+			query = query.Where("("+conversationPriorityOrder+") > ? OR (("+conversationPriorityOrder+") = ? AND ("+conversationActivity+", id) < (?, ?))",
+				rank, rank, activity, cursor.ID)
+		default:
+			query = query.Where("("+conversationActivity+", id) < (?, ?)", activity, cursor.ID)
+		}
+	}
+
+	switch filter.Sort {
+	case ConversationSortCreatedAt:
+		query = query.Order("created_at DESC")
+	case ConversationSortPriority:
+		query = query.Order(conversationPriorityOrder).Order(conversationActivity + " DESC")
+	default:
+		query = query.Order(conversationActivity + " DESC")
+	}
+
 	var conversations []models.Conversation
+	err = r.ApplyPreloads(query, preloads...).Order("id DESC").Limit(limit + 1).Find(&conversations).Error
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(conversations) > limit {
+		conversations = conversations[:limit]
+		nextCursor = conversations[limit-1].ID
+	}
+
+	return conversations, nextCursor, nil
+}
+
+// CountConversationsByStatusForUser counts the conversations matching the filter for each status, whatever status it filters on
+func (r *conversationRepository) CountConversationsByStatusForUser(userID string, filter ConversationFilter) (map[models.ConversationStatus]int64, error) {
+	query, err := r.conversationsForUser(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Status models.ConversationStatus
+		Count  int64
+	}
+	if err := query.Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.ConversationStatus]int64)
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
 
+// CountConversationsForUser counts the conversations matching the filter, including its status
+func (r *conversationRepository) CountConversationsForUser(userID string, filter ConversationFilter) (int64, error) {
+	query, err := r.conversationsForUser(userID, filter)
+	if err != nil {
+		return 0, err
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var count int64
+	err = query.Count(&count).Error
+	return count, err
+}
+
+// conversationsForUser scopes the conversations to the inboxes the agent is a member of, matching the filter but its status
+func (r *conversationRepository) conversationsForUser(userID string, filter ConversationFilter) (*gorm.DB, error) {
 	var user models.User
 
 	err := r.db.Preload("Inboxes").First(&user, "id = ?", userID).Error
@@ -87,25 +296,29 @@ func (r *conversationRepository) GetConversationsForUser(userID string, preloads
 		inboxIDs = append(inboxIDs, inbox.ID)
 	}
 
-	query := r.db.Where("inbox_id IN ?", inboxIDs).Where("company_id = ?", user.CompanyID)
-	query = r.ApplyPreloads(query, preloads...)
-
-	// Custom ordering to prioritize pending and active conversations
-	query = query.Order("CASE " +
-		"WHEN status = 'pending' THEN 1 " +
-		"WHEN status = 'active' THEN 2 " +
-		"WHEN status = 'resolved' THEN 3 " +
-		"WHEN status = 'closed' THEN 4 " +
-		"ELSE 5 END").
-		Order("last_message_at DESC").
-		Order("created_at DESC")
-
-	err = query.Find(&conversations).Error
-	if err != nil {
-		return nil, err
+	query := r.db.Model(&models.Conversation{}).Where("inbox_id IN ?", inboxIDs).Where("company_id = ?", user.CompanyID)
+	if len(filter.LabelIDs) > 0 {
+		query = query.Where("id IN (?)", r.db.Table("conversation_labels").Select("conversation_id").Where("label_id IN ?", filter.LabelIDs))
+	}
+	if filter.InboxID != "" {
+		query = query.Where("inbox_id = ?", filter.InboxID)
+	}
+	if filter.ContactID != "" {
+		query = query.Where("contact_id = ?", filter.ContactID)
+	}
+	if filter.AssignedToID == "unassigned" {
+		query = query.Where("assigned_to_id IS NULL")
+	} else if filter.AssignedToID != "" {
+		query = query.Where("assigned_to_id = ?", filter.AssignedToID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
 	}
 
-	return conversations, nil
+	return query, nil
 }
 
 func (r *conversationRepository) GetConversationByIdAndCompanyID(id string, companyID string, preloads ...string) (*models.Conversation, error) {
@@ -142,6 +355,11 @@ func (r *conversationRepository) CreateConversation(conversation *models.Convers
 	return r.db.Create(conversation).Error
 }
 
+// unscopedReplyTo keeps quoted messages loaded after they have been removed, so replies show them as deleted
+func unscopedReplyTo(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
 func (r *conversationRepository) GetConversationByID(id string, preloads ...string) (*models.Conversation, error) {
 	var conversation models.Conversation
 	query := r.db
@@ -173,7 +391,25 @@ func (r *conversationRepository) GetConversationByID(id string, preloads ...stri
 }
 
 func (r *conversationRepository) UpdateConversation(conversation *models.Conversation) error {
-	return r.db.Save(conversation).Error
+	return r.db.Omit(conversationUpdateOmits...).Save(conversation).Error
+}
+
+func (r *conversationRepository) UpdateConversationSLA(conversation *models.Conversation) error {
+	return r.db.Model(conversation).Select(conversationSLAColumns).Updates(conversation).Error
+}
+
+// MarkTranscriptRequested records a transcript request, unless one was made within the interval.
+// It reports whether the request was recorded, so concurrent requests only let one through.
+func (r *conversationRepository) MarkTranscriptRequested(conversationID string, interval time.Duration) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.Conversation{}).
+		Where("id = ? AND (transcript_requested_at IS NULL OR transcript_requested_at <= ?)", conversationID, now.Add(-interval)).
+		UpdateColumn("transcript_requested_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
 }
 
 func (r *conversationRepository) CreateMessage(message *models.Message) (*models.Message, error) {
@@ -194,9 +430,278 @@ func (r *conversationRepository) CreateMessage(message *models.Message) (*models
 	return message, nil
 }
 
+// CreateMessages stores messages of one conversation in the given order, in a single
+// transaction so either all of them or none are saved
+func (r *conversationRepository) CreateMessages(messages []*models.Message) ([]*models.Message, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	// Consecutive creation times keep the messages in order when they are listed
+	now := time.Now()
+	for i, message := range messages {
+		if message.CreatedAt.IsZero() {
+			message.CreatedAt = now.Add(time.Duration(i) * time.Microsecond)
+		}
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, message := range messages {
+			if err := tx.Create(message).Error; err != nil {
+				return err
+			}
+		}
+
+		last := messages[len(messages)-1]
+		return tx.Model(&models.Conversation{}).Where("id = ?", last.ConversationID).Updates(map[string]interface{}{
+			"last_message":    last.Content,
+			"last_message_at": last.CreatedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload the messages as CreateMessage does, with their quoted messages and decoded metadata
+	stored := make([]*models.Message, 0, len(messages))
+	for _, message := range messages {
+		reloaded, err := r.GetMessageByID(message.ID)
+		if err != nil {
+			return nil, err
+		}
+		stored = append(stored, reloaded)
+	}
+
+	return stored, nil
+}
+
 func (r *conversationRepository) GetMessageByID(id string) (*models.Message, error) {
 	var message models.Message
-	if err := r.db.First(&message, "id = ?", id).Error; err != nil {
+	if err := r.db.Preload("ReplyTo", unscopedReplyTo).First(&message, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// GetMessageByEmailMessageID returns the most recent message in the inbox whose email
+// Message-ID matches one of the given IDs
+func (r *conversationRepository) GetMessageByEmailMessageID(inboxID string, messageIDs ...string) (*models.Message, error) {
+	var message models.Message
+	err := r.db.Joins("JOIN conversations ON conversations.id = messages.conversation_id").
+		Where("conversations.inbox_id = ? AND conversations.deleted_at IS NULL", inboxID).
+		Where("messages.metadata->'email'->>'message_id' IN ?", messageIDs).
+		Order("messages.created_at DESC").
+		First(&message).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// GetLatestEmailMessage returns the most recent message of the conversation sent or
+// received by email before the given time
+func (r *conversationRepository) GetLatestEmailMessage(conversationID string, before time.Time) (*models.Message, error) {
+	var message models.Message
+	err := r.db.Where("conversation_id = ? AND created_at < ?", conversationID, before).
+		Where("metadata->'email'->>'message_id' IS NOT NULL").
+		Order("created_at DESC").
+		First(&message).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// GetMessageByChannelMessageID finds a message of the inbox by the ID a messaging provider
+// assigned to it, stored in the message metadata under the channel's key
+func (r *conversationRepository) GetMessageByChannelMessageID(inboxID string, channel string, messageID string) (*models.Message, error) {
+	var message models.Message
+	err := r.db.Joins("JOIN conversations ON conversations.id = messages.conversation_id").
+		Where("conversations.inbox_id = ? AND conversations.deleted_at IS NULL", inboxID).
+		Where("messages.metadata->?->>'message_id' = ?", channel, messageID).
+		First(&message).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// GetLatestMessageBySenderType returns the most recent message of the conversation sent by the given type of sender
+func (r *conversationRepository) GetLatestMessageBySenderType(conversationID string, senderType models.SenderType) (*models.Message, error) {
+	var message models.Message
+	err := r.db.Where("conversation_id = ? AND sender_type = ?", conversationID, senderType).
+		Order("created_at DESC").
+		First(&message).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// GetLatestOpenConversationForContact returns the contact's most recent conversation in the inbox
+// which has not been closed
+func (r *conversationRepository) GetLatestOpenConversationForContact(inboxID string, contactID string, preloads ...string) (*models.Conversation, error) {
+	var conversation models.Conversation
+	query := r.ApplyPreloads(r.db, preloads...)
+	err := query.Where("inbox_id = ? AND contact_id = ? AND status <> ?", inboxID, contactID, models.ConversationStatusClosed).
+		Order("created_at DESC").
+		First(&conversation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+func (r *conversationRepository) UpdateMessage(message *models.Message) error {
+	return r.db.Omit(clause.Associations).Save(message).Error
+}
+
+// EditMessage saves the changed message along with the edit recording the change
+func (r *conversationRepository) EditMessage(message *models.Message, edit *models.MessageEdit) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(message).Error; err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(edit).Error
+	})
+}
+
+// GetMessageEdits returns the edits of a message, oldest first
+func (r *conversationRepository) GetMessageEdits(messageID string) ([]models.MessageEdit, error) {
+	var edits []models.MessageEdit
+	if err := r.db.Where("message_id = ?", messageID).Order("created_at ASC").Find(&edits).Error; err != nil {
+		return nil, err
+	}
+	return edits, nil
+}
+
+// LoadMessagesPage loads a page of the conversation's messages along with their senders,
+// and sets the cursors of the older and newer pages when there are any
+func (r *conversationRepository) LoadMessagesPage(conversation *models.Conversation, page MessagePage) error {
+	limit := page.Limit
+	if limit <= 0 {
+		limit = DefaultMessagePageLimit
+	} else if limit > MaxMessagePageLimit {
+		limit = MaxMessagePageLimit
+	}
+
+	query := r.db.Where("conversation_id = ?", conversation.ID)
+	if !page.IncludePrivate {
+		query = query.Where("private = ?", false)
+	}
+
+	cursorID := page.Before
+	if page.After != "" {
+		cursorID = page.After
+	}
+
+	if cursorID != "" {
+		var cursor models.Message
+		if err := query.Session(&gorm.Session{}).First(&cursor, "id = ?", cursorID).Error; err != nil {
+			return err
+		}
+
+		if page.After != "" {
+			query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)
+		} else {
+			query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+		}
+	}
+
+	// Older pages are read backwards from the cursor, then put back in order
+	order := "created_at DESC, id DESC"
+	if page.After != "" {
+		order = "created_at ASC, id ASC"
+	}
+
+	var messages []models.Message
+	if err := query.Preload("ReplyTo", unscopedReplyTo).Order(order).Limit(limit + 1).Find(&messages).Error; err != nil {
+		return err
+	}
+
+	hasMore := len(messages) > limit
+	if hasMore {
+		messages = messages[:limit]
+	}
+
+	if page.After == "" {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
+
+	conversation.Messages = messages
+	conversation.MessagesBefore = ""
+	conversation.MessagesAfter = ""
+	if len(messages) > 0 {
+		first, last := messages[0].ID, messages[len(messages)-1].ID
+		if page.After != "" {
+			conversation.MessagesBefore = first
+			if hasMore {
+				conversation.MessagesAfter = last
+			}
+		} else {
+			if hasMore {
+				conversation.MessagesBefore = first
+			}
+			if page.Before != "" {
+				conversation.MessagesAfter = last
+			}
+		}
+	}
+
+	conversations := []models.Conversation{*conversation}
+	if err := r.populateMessageSenders(&conversations); err != nil {
+		return err
+	}
+	conversation.Messages = conversations[0].Messages
+
+	return nil
+}
+
+// GetMentionsForUser returns the private notes of the company mentioning the user, newest first
+func (r *conversationRepository) GetMentionsForUser(userID string, companyID string, limit, offset int) ([]models.Message, int64, error) {
+	mention, err := json.Marshal([]string{userID})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := r.db.Model(&models.Message{}).
+		Joins("JOIN conversations ON conversations.id = messages.conversation_id AND conversations.deleted_at IS NULL").
+		Where("conversations.company_id = ?", companyID).
+		Where("conversations.inbox_id IN (?)", r.db.Table("inbox_users").Select("inbox_id").Where("user_id = ?", userID)).
+		Where("messages.private = ? AND messages.tombstoned_at IS NULL", true).
+		Where("messages.metadata -> 'mentions' @> ?::jsonb", string(mention))
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var messages []models.Message
+	if err := query.Select("messages.*").Preload("ReplyTo", unscopedReplyTo).
+		Order("messages.created_at DESC").Offset(offset).Limit(limit).Find(&messages).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Senders are loaded in one go by treating the notes as a single conversation
+	conversations := []models.Conversation{{Messages: messages}}
+	if err := r.populateMessageSenders(&conversations); err != nil {
+		return nil, 0, err
+	}
+
+	return conversations[0].Messages, total, nil
+}
+
+// GetLatestMessage returns the most recent message of the conversation, leaving out private notes unless included
+func (r *conversationRepository) GetLatestMessage(conversationID string, includePrivate bool) (*models.Message, error) {
+	var message models.Message
+	query := r.db.Where("conversation_id = ?", conversationID)
+	if !includePrivate {
+		query = query.Where("private = ?", false)
+	}
+
+	if err := query.Order("created_at DESC").First(&message).Error; err != nil {
 		return nil, err
 	}
 	return &message, nil
@@ -216,6 +721,11 @@ func (r *conversationRepository) PopulateSender(message *models.Message) (*model
 		}
 		message.ContactSender = &contact
 	}
+
+	// The quoted message is only a preview, so it is shown without a sender rather than failing
+	if message.ReplyTo != nil {
+		r.PopulateSender(message.ReplyTo)
+	}
 	return message, nil
 }
 
@@ -228,6 +738,19 @@ func (r *conversationRepository) GetActiveAssignedConversationsForUser(userID st
 	return conversations, nil
 }
 
+// GetUnassignedPendingConversationsByInboxID returns the conversations waiting for an agent,
+// the most urgent and then the longest waiting first
+func (r *conversationRepository) GetUnassignedPendingConversationsByInboxID(inboxID string, preloads ...string) ([]models.Conversation, error) {
+	var conversations []models.Conversation
+	query := r.db.Where("inbox_id = ? AND status = ? AND assigned_to_id IS NULL", inboxID, models.ConversationStatusPending)
+	query = r.ApplyPreloads(query, preloads...)
+
+	if err := query.Order(conversationPriorityOrder).Order("created_at ASC").Find(&conversations).Error; err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
 // populateMessageSenders populates the AgentSender and ContactSender fields for each message
 func (r *conversationRepository) populateMessageSenders(conversations *[]models.Conversation) error {
 	// Collect all agent and contact IDs
@@ -237,11 +760,15 @@ func (r *conversationRepository) populateMessageSenders(conversations *[]models.
 	for i := range *conversations {
 		conv := &(*conversations)[i]
 		for j := range conv.Messages {
-			msg := &conv.Messages[j]
-			if msg.SenderType == models.SenderTypeAgent {
-				agentIDs = append(agentIDs, *msg.SenderID)
-			} else if msg.SenderType == models.SenderTypeContact {
-				contactIDs = append(contactIDs, *msg.SenderID)
+			for _, msg := range []*models.Message{&conv.Messages[j], conv.Messages[j].ReplyTo} {
+				if msg == nil {
+					continue
+				}
+				if msg.SenderType == models.SenderTypeAgent {
+					agentIDs = append(agentIDs, *msg.SenderID)
+				} else if msg.SenderType == models.SenderTypeContact {
+					contactIDs = append(contactIDs, *msg.SenderID)
+				}
 			}
 		}
 	}
@@ -297,14 +824,18 @@ func (r *conversationRepository) populateMessageSenders(conversations *[]models.
 	for i := range *conversations {
 		conv := &(*conversations)[i]
 		for j := range conv.Messages {
-			msg := &conv.Messages[j]
-			if msg.SenderType == models.SenderTypeAgent {
-				if agent, ok := agents[*msg.SenderID]; ok {
-					msg.AgentSender = &agent
+			for _, msg := range []*models.Message{&conv.Messages[j], conv.Messages[j].ReplyTo} {
+				if msg == nil {
+					continue
 				}
-			} else if msg.SenderType == models.SenderTypeContact {
-				if contact, ok := contacts[*msg.SenderID]; ok {
-					msg.ContactSender = &contact
+				if msg.SenderType == models.SenderTypeAgent {
+					if agent, ok := agents[*msg.SenderID]; ok {
+						msg.AgentSender = &agent
+					}
+				} else if msg.SenderType == models.SenderTypeContact {
+					if contact, ok := contacts[*msg.SenderID]; ok {
+						msg.ContactSender = &contact
+					}
 				}
 			}
 		}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"live-chat-server/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ConversationParticipantRepository interface {
+	GetParticipants(conversationID string) ([]models.ConversationParticipant, error)
+	GetParticipant(conversationID string, userID string) (*models.ConversationParticipant, error)
+	SaveParticipant(participant *models.ConversationParticipant) error
+	DeleteParticipant(participant *models.ConversationParticipant) error
+}
+
+type conversationParticipantRepository struct {
+	db *gorm.DB
+}
+
+func NewConversationParticipantRepository(db *gorm.DB) ConversationParticipantRepository {
+	return &conversationParticipantRepository{db: db}
+}
+
+func (r *conversationParticipantRepository) GetParticipants(conversationID string) ([]models.ConversationParticipant, error) {
+	var participants []models.ConversationParticipant
+	err := r.db.Preload("User.NotificationSettings").
+		Where("conversation_id = ?", conversationID).
+		Order("created_at ASC").
+		Find(&participants).Error
+	return participants, err
+}
+
+func (r *conversationParticipantRepository) GetParticipant(conversationID string, userID string) (*models.ConversationParticipant, error) {
+	var participant models.ConversationParticipant
+	err := r.db.Preload("User.NotificationSettings").
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		First(&participant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+// SaveParticipant adds the agent to the conversation, or changes their role when they already take part in it
+func (r *conversationParticipantRepository) SaveParticipant(participant *models.ConversationParticipant) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "conversation_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "added_by_id", "updated_at"}),
+	}).Omit(clause.Associations).Create(participant).Error
+}
+
+func (r *conversationParticipantRepository) DeleteParticipant(participant *models.ConversationParticipant) error {
+	return r.db.Delete(participant).Error
+}
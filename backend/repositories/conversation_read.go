@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"live-chat-server/models"
+
+	"gorm.io/gorm"
+)
+
+type ConversationReadRepository interface {
+	GetConversationRead(conversationID string, readerType models.SenderType, readerID string) (*models.ConversationRead, error)
+	GetConversationReads(conversationID string) ([]models.ConversationRead, error)
+	AdvanceConversationRead(conversationID string, readerType models.SenderType, readerID string, receipt models.ReceiptType, message *models.Message) (*models.ConversationRead, bool, error)
+	GetUnreadCounts(conversationIDs []string, readerType models.SenderType, readerID string, includePrivate bool) (map[string]int, error)
+}
+
+type conversationReadRepository struct {
+	db *gorm.DB
+}
+
+func NewConversationReadRepository(db *gorm.DB) ConversationReadRepository {
+	return &conversationReadRepository{db: db}
+}
+
+func (r *conversationReadRepository) GetConversationRead(conversationID string, readerType models.SenderType, readerID string) (*models.ConversationRead, error) {
+	var read models.ConversationRead
+	if err := r.db.Where("conversation_id = ? AND reader_type = ? AND reader_id = ?", conversationID, readerType, readerID).First(&read).Error; err != nil {
+		return nil, err
+	}
+	return &read, nil
+}
+
+func (r *conversationReadRepository) GetConversationReads(conversationID string) ([]models.ConversationRead, error) {
+	var reads []models.ConversationRead
+	if err := r.db.Where("conversation_id = ?", conversationID).Order("created_at ASC").Find(&reads).Error; err != nil {
+		return nil, err
+	}
+	return reads, nil
+}
+
+// AdvanceConversationRead moves the reader's delivered or read pointer up to the message.
+// Pointers never move back, reading a message also delivers it. It reports whether a pointer moved.
+func (r *conversationReadRepository) AdvanceConversationRead(conversationID string, readerType models.SenderType, readerID string, receipt models.ReceiptType, message *models.Message) (*models.ConversationRead, bool, error) {
+	read := models.ConversationRead{ConversationID: conversationID, ReaderType: readerType, ReaderID: readerID}
+	if err := r.db.Where(&read).FirstOrCreate(&read).Error; err != nil {
+		// Another connection of the reader may have created it first
+		existing, getErr := r.GetConversationRead(conversationID, readerType, readerID)
+		if getErr != nil {
+			return nil, false, err
+		}
+		read = *existing
+	}
+
+	columns := []string{"delivered"}
+	if receipt == models.ReceiptTypeRead {
+		columns = append(columns, "read")
+	}
+
+	advanced := false
+	for _, column := range columns {
+		result := r.db.Model(&models.ConversationRead{}).
+			Where("id = ? AND (last_"+column+"_at IS NULL OR last_"+column+"_at < ?)", read.ID, message.CreatedAt).
+			Updates(map[string]interface{}{
+				"last_" + column + "_message_id": message.ID,
+				"last_" + column + "_at":         message.CreatedAt,
+			})
+		if result.Error != nil {
+			return nil, false, result.Error
+		}
+		advanced = advanced || result.RowsAffected > 0
+	}
+
+	updated, err := r.GetConversationRead(conversationID, readerType, readerID)
+	if err != nil {
+		return nil, false, err
+	}
+	return updated, advanced, nil
+}
+
+// GetUnreadCounts counts the messages of each conversation the reader has not read yet.
+// The reader's own messages, system messages and deleted messages are never unread.
+func (r *conversationReadRepository) GetUnreadCounts(conversationIDs []string, readerType models.SenderType, readerID string, includePrivate bool) (map[string]int, error) {
+	counts := make(map[string]int, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return counts, nil
+	}
+
+	query := r.db.Table("messages").
+		Select("messages.conversation_id, COUNT(*) AS unread").
+		Joins("LEFT JOIN conversation_reads ON conversation_reads.conversation_id = messages.conversation_id AND conversation_reads.reader_type = ? AND conversation_reads.reader_id = ?", readerType, readerID).
+		Where("messages.conversation_id IN ?", conversationIDs).
+		Where("messages.deleted_at IS NULL AND messages.tombstoned_at IS NULL").
+		Where("messages.sender_type <> ?", models.SenderTypeSystem).
+		Where("NOT (messages.sender_type = ? AND messages.sender_id = ?)", readerType, readerID).
+		Where("conversation_reads.last_read_at IS NULL OR messages.created_at > conversation_reads.last_read_at")
+	if !includePrivate {
+		query = query.Where("messages.private = ?", false)
+	}
+
+	var rows []struct {
+		ConversationID string
+		Unread         int
+	}
+	if err := query.Group("messages.conversation_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.ConversationID] = row.Unread
+	}
+	return counts, nil
+}
@@ -1,7 +1,6 @@
 package repositories
 
 import (
-	"errors"
 	"live-chat-server/models"
 
 	"gorm.io/gorm"
@@ -12,15 +11,15 @@ type InboxRepository interface {
 	GetInboxByIDAndCompanyID(id string, companyID string) (*models.Inbox, error)
 	GetInboxesByCompanyID(companyID string) ([]models.Inbox, error)
 	CreateInbox(inbox *models.Inbox) error
-	CreateInboxWithWebChat(inbox *models.Inbox, webchat *models.InboxWebChat) error
-	CreateInboxWithEmail(inbox *models.Inbox, email *models.InboxEmail) error
+	CreateInboxWithConfig(inbox *models.Inbox, config models.InboxConfig) error
 	UpdateInbox(inbox *models.Inbox, tx *gorm.DB) error
-	UpdateWebChatConfig(webChat *models.InboxWebChat, tx *gorm.DB) error
-	UpdateEmailConfig(email *models.InboxEmail, tx *gorm.DB) error
+	UpdateInboxConfig(config models.InboxConfig, tx *gorm.DB) error
+	GetInboxConfig(inboxID string, config models.InboxConfig) error
 	DeleteInbox(id string) error
 	DeleteInboxByIDAndCompanyID(id string, companyID string) error
 	GetUsersForInbox(inboxID string) ([]models.User, error)
 	GetInboxesForUser(userID string) ([]models.Inbox, error)
+	GetEnabledInboxesByType(inboxType models.InboxType) ([]models.Inbox, error)
 }
 
 type inboxRepository struct {
@@ -56,27 +55,6 @@ func (r *inboxRepository) GetInboxByID(id string) (*models.Inbox, error) {
 		return nil, err
 	}
 
-	// Load WebChat separately if this is a web chat inbox
-	if inbox.Type == models.InboxTypeWebChat {
-		var webChat models.InboxWebChat
-		if err := r.db.Where("inbox_id = ?", inbox.ID).First(&webChat).Error; err != nil {
-			if !errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, err
-			}
-		} else {
-			inbox.WebChat = &webChat
-		}
-	} else if inbox.Type == models.InboxTypeEmail {
-		var email models.InboxEmail
-		if err := r.db.Where("inbox_id = ?", inbox.ID).First(&email).Error; err != nil {
-			if !errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, err
-			}
-		} else {
-			inbox.Email = &email
-		}
-	}
-
 	return &inbox, nil
 }
 
@@ -86,27 +64,6 @@ func (r *inboxRepository) GetInboxByIDAndCompanyID(id string, companyID string)
 		return nil, err
 	}
 
-	// Load WebChat separately if this is a web chat inbox
-	if inbox.Type == models.InboxTypeWebChat {
-		var webChat models.InboxWebChat
-		if err := r.db.Where("inbox_id = ?", inbox.ID).First(&webChat).Error; err != nil {
-			if !errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, err
-			}
-		} else {
-			inbox.WebChat = &webChat
-		}
-	} else if inbox.Type == models.InboxTypeEmail {
-		var email models.InboxEmail
-		if err := r.db.Where("inbox_id = ?", inbox.ID).First(&email).Error; err != nil {
-			if !errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, err
-			}
-		} else {
-			inbox.Email = &email
-		}
-	}
-
 	return &inbox, nil
 }
 
@@ -116,29 +73,6 @@ func (r *inboxRepository) GetInboxesByCompanyID(companyID string) ([]models.Inbo
 		return nil, err
 	}
 
-	// For each inbox, load the type-specific data
-	for i := range inboxes {
-		if inboxes[i].Type == models.InboxTypeWebChat {
-			var webChat models.InboxWebChat
-			if err := r.db.Where("inbox_id = ?", inboxes[i].ID).First(&webChat).Error; err != nil {
-				if !errors.Is(err, gorm.ErrRecordNotFound) {
-					return nil, err
-				}
-			} else {
-				inboxes[i].WebChat = &webChat
-			}
-		} else if inboxes[i].Type == models.InboxTypeEmail {
-			var email models.InboxEmail
-			if err := r.db.Where("inbox_id = ?", inboxes[i].ID).First(&email).Error; err != nil {
-				if !errors.Is(err, gorm.ErrRecordNotFound) {
-					return nil, err
-				}
-			} else {
-				inboxes[i].Email = &email
-			}
-		}
-	}
-
 	return inboxes, nil
 }
 
@@ -146,33 +80,23 @@ func (r *inboxRepository) CreateInbox(inbox *models.Inbox) error {
 	return r.db.Create(inbox).Error
 }
 
-func (r *inboxRepository) CreateInboxWithWebChat(inbox *models.Inbox, webchat *models.InboxWebChat) error {
+func (r *inboxRepository) CreateInboxWithConfig(inbox *models.Inbox, config models.InboxConfig) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		// Create the main inbox first
 		if err := tx.Create(inbox).Error; err != nil {
 			return err
 		}
 
-		// Set the inbox ID on the webchat config
-		webchat.InboxID = inbox.ID
-
-		// Create the webchat configuration
-		return tx.Create(webchat).Error
-	})
-}
+		// Set the inbox ID on the channel config
+		config.SetInboxID(inbox.ID)
 
-func (r *inboxRepository) CreateInboxWithEmail(inbox *models.Inbox, email *models.InboxEmail) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Create the main inbox first
-		if err := tx.Create(inbox).Error; err != nil {
+		// Create the channel configuration
+		if err := tx.Create(config).Error; err != nil {
 			return err
 		}
 
-		// Set the inbox ID on the email config
-		email.InboxID = inbox.ID
-
-		// Create the email configuration
-		return tx.Create(email).Error
+		inbox.SetConfig(config)
+		return nil
 	})
 }
 
@@ -180,18 +104,14 @@ func (r *inboxRepository) UpdateInbox(inbox *models.Inbox, tx *gorm.DB) error {
 	return tx.Save(inbox).Error
 }
 
-func (r *inboxRepository) UpdateWebChatConfig(webChat *models.InboxWebChat, tx *gorm.DB) error {
-	if webChat.ID == "" {
-		return tx.Create(webChat).Error
-	}
-	return tx.Save(webChat).Error
+func (r *inboxRepository) UpdateInboxConfig(config models.InboxConfig, tx *gorm.DB) error {
+	// Save creates the configuration if it doesn't exist yet
+	return tx.Save(config).Error
 }
 
-func (r *inboxRepository) UpdateEmailConfig(email *models.InboxEmail, tx *gorm.DB) error {
-	if email.ID == "" {
-		return tx.Create(email).Error
-	}
-	return tx.Save(email).Error
+// GetInboxConfig loads the type-specific configuration of the inbox into config
+func (r *inboxRepository) GetInboxConfig(inboxID string, config models.InboxConfig) error {
+	return r.db.Where("inbox_id = ?", inboxID).First(config).Error
 }
 
 func (r *inboxRepository) DeleteInbox(id string) error {
@@ -226,3 +146,12 @@ func (r *inboxRepository) GetInboxesForUser(userID string) ([]models.Inbox, erro
 
 	return inboxes, err
 }
+
+func (r *inboxRepository) GetEnabledInboxesByType(inboxType models.InboxType) ([]models.Inbox, error) {
+	var inboxes []models.Inbox
+	if err := r.db.Where("type = ? AND enabled = ?", inboxType, true).Find(&inboxes).Error; err != nil {
+		return nil, err
+	}
+
+	return inboxes, nil
+}
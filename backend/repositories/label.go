@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"live-chat-server/models"
+
+	"gorm.io/gorm"
+)
+
+type LabelRepository interface {
+	CreateLabel(label *models.Label) error
+	GetLabelsByCompanyID(companyID string) ([]models.Label, error)
+	GetLabelByIDAndCompanyID(id string, companyID string) (*models.Label, error)
+	GetLabelsByIDsAndCompanyID(ids []string, companyID string) ([]models.Label, error)
+	GetLabelByNameAndCompanyID(name string, companyID string) (*models.Label, error)
+	UpdateLabel(label *models.Label) error
+	DeleteLabel(label *models.Label) error
+	GetConversationIDsByLabelID(labelID string) ([]string, error)
+	AddLabelsToConversation(conversation *models.Conversation, labels []models.Label) error
+	RemoveLabelFromConversation(conversation *models.Conversation, label *models.Label) error
+}
+
+type labelRepository struct {
+	db *gorm.DB
+}
+
+func NewLabelRepository(db *gorm.DB) LabelRepository {
+	return &labelRepository{db: db}
+}
+
+func (r *labelRepository) CreateLabel(label *models.Label) error {
+	return r.db.Create(label).Error
+}
+
+func (r *labelRepository) GetLabelsByCompanyID(companyID string) ([]models.Label, error) {
+	var labels []models.Label
+	if err := r.db.Where("company_id = ?", companyID).Order("name ASC").Find(&labels).Error; err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (r *labelRepository) GetLabelByIDAndCompanyID(id string, companyID string) (*models.Label, error) {
+	var label models.Label
+	if err := r.db.Where("id = ? AND company_id = ?", id, companyID).First(&label).Error; err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+func (r *labelRepository) GetLabelsByIDsAndCompanyID(ids []string, companyID string) ([]models.Label, error) {
+	var labels []models.Label
+	if err := r.db.Where("id IN ? AND company_id = ?", ids, companyID).Find(&labels).Error; err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (r *labelRepository) GetLabelByNameAndCompanyID(name string, companyID string) (*models.Label, error) {
+	var label models.Label
+	if err := r.db.Where("LOWER(name) = LOWER(?) AND company_id = ?", name, companyID).First(&label).Error; err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+func (r *labelRepository) UpdateLabel(label *models.Label) error {
+	return r.db.Save(label).Error
+}
+
+// DeleteLabel deletes the label and removes it from every conversation
+func (r *labelRepository) DeleteLabel(label *models.Label) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM conversation_labels WHERE label_id = ?", label.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(label).Error
+	})
+}
+
+// GetConversationIDsByLabelID returns the conversations the label is applied to
+func (r *labelRepository) GetConversationIDsByLabelID(labelID string) ([]string, error) {
+	var conversationIDs []string
+	if err := r.db.Table("conversation_labels").Where("label_id = ?", labelID).Pluck("conversation_id", &conversationIDs).Error; err != nil {
+		return nil, err
+	}
+	return conversationIDs, nil
+}
+
+func (r *labelRepository) AddLabelsToConversation(conversation *models.Conversation, labels []models.Label) error {
+	return r.db.Model(conversation).Association("Labels").Append(labels)
+}
+
+func (r *labelRepository) RemoveLabelFromConversation(conversation *models.Conversation, label *models.Label) error {
+	return r.db.Model(conversation).Association("Labels").Delete(label)
+}
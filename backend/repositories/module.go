@@ -46,6 +46,30 @@ func RegisterRepositories(container *dig.Container) {
 		log.Fatalf("Failed to provide canned response repository: %v", err)
 	}
 
+	if err := container.Provide(func(db *gorm.DB) ContactMergeRepository {
+		return NewContactMergeRepository(db)
+	}); err != nil {
+		log.Fatalf("Failed to provide contact merge repository: %v", err)
+	}
+
+	if err := container.Provide(func(db *gorm.DB) ConversationReadRepository {
+		return NewConversationReadRepository(db)
+	}); err != nil {
+		log.Fatalf("Failed to provide conversation read repository: %v", err)
+	}
+
+	if err := container.Provide(func(db *gorm.DB) LabelRepository {
+		return NewLabelRepository(db)
+	}); err != nil {
+		log.Fatalf("Failed to provide label repository: %v", err)
+	}
+
+	if err := container.Provide(func(db *gorm.DB) SLAPolicyRepository {
+		return NewSLAPolicyRepository(db)
+	}); err != nil {
+		log.Fatalf("Failed to provide SLA policy repository: %v", err)
+	}
+
 	if err := container.Provide(func(db *gorm.DB) NotificationRepository {
 		return NewNotificationRepository(db)
 	}); err != nil {
@@ -63,4 +87,22 @@ func RegisterRepositories(container *dig.Container) {
 	}); err != nil {
 		log.Fatalf("Failed to provide analytics repository: %v", err)
 	}
+
+	if err := container.Provide(func(db *gorm.DB) SearchRepository {
+		return NewSearchRepository(db)
+	}); err != nil {
+		log.Fatalf("Failed to provide search repository: %v", err)
+	}
+
+	if err := container.Provide(func(db *gorm.DB) SavedViewRepository {
+		return NewSavedViewRepository(db)
+	}); err != nil {
+		log.Fatalf("Failed to provide saved view repository: %v", err)
+	}
+
+	if err := container.Provide(func(db *gorm.DB) ConversationParticipantRepository {
+		return NewConversationParticipantRepository(db)
+	}); err != nil {
+		log.Fatalf("Failed to provide conversation participant repository: %v", err)
+	}
 }
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"live-chat-server/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SavedViewRepository interface {
+	CreateSavedView(view *models.SavedView) error
+	GetSavedViewsForUser(userID string, companyID string) ([]models.SavedView, error)
+	GetSavedViewForUser(id string, userID string, companyID string) (*models.SavedView, error)
+	UpdateSavedView(view *models.SavedView) error
+	DeleteSavedView(view *models.SavedView) error
+}
+
+type savedViewRepository struct {
+	db *gorm.DB
+}
+
+func NewSavedViewRepository(db *gorm.DB) SavedViewRepository {
+	return &savedViewRepository{db: db}
+}
+
+func (r *savedViewRepository) CreateSavedView(view *models.SavedView) error {
+	return r.db.Create(view).Error
+}
+
+// GetSavedViewsForUser lists the agent's own views, the views of their inboxes' teams and the company's views
+func (r *savedViewRepository) GetSavedViewsForUser(userID string, companyID string) ([]models.SavedView, error) {
+	var views []models.SavedView
+	if err := r.visibleTo(userID, companyID).Order("name ASC").Find(&views).Error; err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+func (r *savedViewRepository) GetSavedViewForUser(id string, userID string, companyID string) (*models.SavedView, error) {
+	var view models.SavedView
+	if err := r.visibleTo(userID, companyID).Where("id = ?", id).First(&view).Error; err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+func (r *savedViewRepository) UpdateSavedView(view *models.SavedView) error {
+	return r.db.Save(view).Error
+}
+
+func (r *savedViewRepository) DeleteSavedView(view *models.SavedView) error {
+	return r.db.Delete(view).Error
+}
+
+func (r *savedViewRepository) visibleTo(userID string, companyID string) *gorm.DB {
+	return r.db.Where("company_id = ?", companyID).
+		Where("(visibility = ? AND user_id = ?) OR visibility = ? OR (visibility = ? AND inbox_id IN (?))",
+			models.SavedViewVisibilityPrivate, userID,
+			models.SavedViewVisibilityCompany,
+			models.SavedViewVisibilityTeam, r.db.Table("inbox_users").Select("inbox_id").Where("user_id = ?", userID))
+}
+
+// NewConversationFilter builds the conversation filter of a definition, as run by the agent
+func NewConversationFilter(definition models.ConversationFilterDefinition, userID string) ConversationFilter {
+	filter := ConversationFilter{
+		LabelIDs:     definition.LabelIDs,
+		Status:       models.ConversationStatus(definition.Status),
+		InboxID:      definition.InboxID,
+		ContactID:    definition.ContactID,
+		AssignedToID: definition.Assignee,
+		Sort:         ConversationSort(definition.Sort),
+	}
+	if definition.Assignee == "me" {
+		filter.AssignedToID = userID
+	}
+	if from, err := time.Parse("2006-01-02", definition.From); err == nil {
+		filter.From = &from
+	}
+	if to, err := time.Parse("2006-01-02", definition.To); err == nil {
+		// The end date is inclusive
+		to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		filter.To = &to
+	}
+	return filter
+}
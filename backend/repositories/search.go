@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"live-chat-server/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultSearchLimit is how many results of each type are returned when no limit is asked for
+	DefaultSearchLimit = 10
+	// MaxSearchLimit is the most results of each type returned at once
+	MaxSearchLimit = 50
+)
+
+// searchHeadlineOptions marks the matches in snippets, the searched text is HTML escaped first
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" ... \""
+
+type SearchRepository interface {
+	SearchMessages(query SearchQuery) ([]MessageSearchResult, error)
+	SearchContacts(query SearchQuery) ([]ContactSearchResult, error)
+	SearchContactNotes(query SearchQuery) ([]ContactNoteSearchResult, error)
+}
+
+// SearchQuery is a full-text search made by an agent within their company.
+// Messages are only searched in the inboxes the agent is a member of. The conversation filters
+// also narrow down contacts and notes to those of contacts with a matching conversation.
+type SearchQuery struct {
+	CompanyID string
+	UserID    string
+	Query     string
+	// IncludePrivate keeps private notes in the results, only agents can see them
+	IncludePrivate bool
+
+	InboxID string
+	Status  models.ConversationStatus
+	// AssignedToID is an agent ID, or "unassigned" for conversations without one
+	AssignedToID string
+	From         *time.Time
+	To           *time.Time
+	Limit        int
+}
+
+type MessageSearchResult struct {
+	ID                 string    `json:"id"`
+	ConversationID     string    `json:"conversation_id"`
+	InboxID            string    `json:"inbox_id"`
+	ConversationStatus string    `json:"conversation_status"`
+	ContactID          string    `json:"contact_id"`
+	ContactName        *string   `json:"contact_name"`
+	SenderType         string    `json:"sender_type"`
+	Private            bool      `json:"private"`
+	Snippet            string    `json:"snippet"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type ContactSearchResult struct {
+	ID        string    `json:"id"`
+	Name      *string   `json:"name"`
+	Email     *string   `json:"email"`
+	Phone     *string   `json:"phone"`
+	Company   *string   `json:"company"`
+	Snippet   string    `json:"snippet"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ContactNoteSearchResult struct {
+	ID          string    `json:"id"`
+	ContactID   string    `json:"contact_id"`
+	ContactName *string   `json:"contact_name"`
+	UserID      string    `json:"user_id"`
+	Snippet     string    `json:"snippet"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type searchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+func (r *searchRepository) SearchMessages(query SearchQuery) ([]MessageSearchResult, error) {
+	var results []MessageSearchResult
+
+	db := r.db.Table("messages").
+		Select("messages.id, messages.conversation_id, conversations.inbox_id, conversations.status AS conversation_status, "+
+			"conversations.contact_id, contacts.name AS contact_name, messages.sender_type, messages.private, messages.created_at, "+
+			"ts_headline('simple', "+escapedHTML("messages.content")+", search_query, ?) AS snippet", searchHeadlineOptions).
+		Joins("CROSS JOIN websearch_to_tsquery('simple', ?) AS search_query", query.Query).
+		Joins("JOIN conversations ON conversations.id = messages.conversation_id AND conversations.deleted_at IS NULL").
+		Joins("LEFT JOIN contacts ON contacts.id = conversations.contact_id").
+		Where("messages.search_vector @@ search_query").
+		Where("messages.deleted_at IS NULL AND messages.tombstoned_at IS NULL").
+		Where("messages.type = ?", models.MessageTypeText)
+	db = r.scopeConversations(db, query)
+	if !query.IncludePrivate {
+		db = db.Where("messages.private = ?", false)
+	}
+	db = scopeCreatedAt(db, "messages", query)
+
+	err := db.Order("ts_rank(messages.search_vector, search_query) DESC, messages.created_at DESC").
+		Limit(searchLimit(query)).Scan(&results).Error
+	return results, err
+}
+
+func (r *searchRepository) SearchContacts(query SearchQuery) ([]ContactSearchResult, error) {
+	var results []ContactSearchResult
+
+	details := "concat_ws(' ', contacts.name, contacts.email, contacts.phone, contacts.company)"
+	db := r.db.Table("contacts").
+		Select("contacts.id, contacts.name, contacts.email, contacts.phone, contacts.company, contacts.created_at, "+
+			"ts_headline('simple', "+escapedHTML(details)+", search_query, ?) AS snippet", searchHeadlineOptions).
+		Joins("CROSS JOIN websearch_to_tsquery('simple', ?) AS search_query", query.Query).
+		Where("contacts.search_vector @@ search_query").
+		Where("contacts.company_id = ? AND contacts.deleted_at IS NULL", query.CompanyID)
+	db = r.scopeContacts(db, "contacts.id", query)
+	db = scopeCreatedAt(db, "contacts", query)
+
+	err := db.Order("ts_rank(contacts.search_vector, search_query) DESC, contacts.created_at DESC").
+		Limit(searchLimit(query)).Scan(&results).Error
+	return results, err
+}
+
+func (r *searchRepository) SearchContactNotes(query SearchQuery) ([]ContactNoteSearchResult, error) {
+	var results []ContactNoteSearchResult
+
+	db := r.db.Table("contact_notes").
+		Select("contact_notes.id, contact_notes.contact_id, contacts.name AS contact_name, contact_notes.user_id, contact_notes.created_at, "+
+			"ts_headline('simple', "+escapedHTML("contact_notes.content")+", search_query, ?) AS snippet", searchHeadlineOptions).
+		Joins("CROSS JOIN websearch_to_tsquery('simple', ?) AS search_query", query.Query).
+		Joins("JOIN contacts ON contacts.id = contact_notes.contact_id AND contacts.deleted_at IS NULL").
+		Where("contact_notes.search_vector @@ search_query").
+		Where("contacts.company_id = ? AND contact_notes.deleted_at IS NULL", query.CompanyID)
+	db = r.scopeContacts(db, "contact_notes.contact_id", query)
+	db = scopeCreatedAt(db, "contact_notes", query)
+
+	err := db.Order("ts_rank(contact_notes.search_vector, search_query) DESC, contact_notes.created_at DESC").
+		Limit(searchLimit(query)).Scan(&results).Error
+	return results, err
+}
+
+// scopeConversations keeps the conversations of the company's inboxes the agent is a member of, matching the filters
+func (r *searchRepository) scopeConversations(db *gorm.DB, query SearchQuery) *gorm.DB {
+	db = db.Where("conversations.company_id = ?", query.CompanyID).
+		Where("conversations.inbox_id IN (?)", r.db.Table("inbox_users").Select("inbox_id").Where("user_id = ?", query.UserID))
+
+	if query.InboxID != "" {
+		db = db.Where("conversations.inbox_id = ?", query.InboxID)
+	}
+	if query.Status != "" {
+		db = db.Where("conversations.status = ?", query.Status)
+	}
+	if query.AssignedToID == "unassigned" {
+		db = db.Where("conversations.assigned_to_id IS NULL")
+	} else if query.AssignedToID != "" {
+		db = db.Where("conversations.assigned_to_id = ?", query.AssignedToID)
+	}
+	return db
+}
+
+// scopeContacts keeps the contacts having a conversation matching the filters, when any are set
+func (r *searchRepository) scopeContacts(db *gorm.DB, column string, query SearchQuery) *gorm.DB {
+	if query.InboxID == "" && query.Status == "" && query.AssignedToID == "" {
+		return db
+	}
+
+	conversations := r.scopeConversations(r.db.Table("conversations").Select("conversations.contact_id").Where("conversations.deleted_at IS NULL"), query)
+	return db.Where(column+" IN (?)", conversations)
+}
+
+func scopeCreatedAt(db *gorm.DB, table string, query SearchQuery) *gorm.DB {
+	if query.From != nil {
+		db = db.Where(table+".created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where(table+".created_at <= ?", *query.To)
+	}
+	return db
+}
+
+func searchLimit(query SearchQuery) int {
+	if query.Limit <= 0 {
+		return DefaultSearchLimit
+	}
+	if query.Limit > MaxSearchLimit {
+		return MaxSearchLimit
+	}
+	return query.Limit
+}
+
+// escapedHTML escapes a text column in SQL, so only the marks added around matches in snippets are HTML
+func escapedHTML(column string) string {
+	return "replace(replace(replace(coalesce(" + column + ", ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+}
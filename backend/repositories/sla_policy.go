@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"live-chat-server/models"
+
+	"gorm.io/gorm"
+)
+
+type SLAPolicyRepository interface {
+	CreateSLAPolicy(policy *models.SLAPolicy) error
+	GetSLAPoliciesByCompanyID(companyID string) ([]models.SLAPolicy, error)
+	GetSLAPolicyByID(id string) (*models.SLAPolicy, error)
+	GetSLAPolicyByIDAndCompanyID(id string, companyID string) (*models.SLAPolicy, error)
+	UpdateSLAPolicy(policy *models.SLAPolicy) error
+	DeleteSLAPolicy(policy *models.SLAPolicy) error
+}
+
+type slaPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewSLAPolicyRepository(db *gorm.DB) SLAPolicyRepository {
+	return &slaPolicyRepository{db: db}
+}
+
+func (r *slaPolicyRepository) CreateSLAPolicy(policy *models.SLAPolicy) error {
+	return r.db.Create(policy).Error
+}
+
+func (r *slaPolicyRepository) GetSLAPoliciesByCompanyID(companyID string) ([]models.SLAPolicy, error) {
+	var policies []models.SLAPolicy
+	if err := r.db.Where("company_id = ?", companyID).Order("name ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *slaPolicyRepository) GetSLAPolicyByID(id string) (*models.SLAPolicy, error) {
+	var policy models.SLAPolicy
+	if err := r.db.Where("id = ?", id).First(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *slaPolicyRepository) GetSLAPolicyByIDAndCompanyID(id string, companyID string) (*models.SLAPolicy, error) {
+	var policy models.SLAPolicy
+	if err := r.db.Where("id = ? AND company_id = ?", id, companyID).First(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *slaPolicyRepository) UpdateSLAPolicy(policy *models.SLAPolicy) error {
+	return r.db.Save(policy).Error
+}
+
+// DeleteSLAPolicy deletes the policy and detaches it from its inboxes.
+// Conversations keep the due dates they were given.
+func (r *slaPolicyRepository) DeleteSLAPolicy(policy *models.SLAPolicy) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Inbox{}).Where("sla_policy_id = ?", policy.ID).Update("sla_policy_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(policy).Error
+	})
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"live-chat-server/disk"
 	handler "live-chat-server/handlers"
+	"live-chat-server/interfaces"
 	"live-chat-server/middleware"
 
 	"github.com/gofiber/fiber/v2"
@@ -27,10 +28,15 @@ type DIParams struct {
 	AuthHandler           *handler.AuthHandler
 	UserHandler           *handler.UserHandler
 	CannedResponseHandler *handler.CannedResponseHandler
+	LabelHandler          *handler.LabelHandler
+	SLAPolicyHandler      *handler.SLAPolicyHandler
 	NotificationHandler   *handler.NotificationHandler
 	SuperAdminHandler     *handler.SuperAdminHandler
 	HealthHandler         *handler.HealthHandler
 	AnalyticsHandler      *handler.AnalyticsHandler
+	SearchHandler         *handler.SearchHandler
+	SavedViewHandler      *handler.SavedViewHandler
+	Channels              interfaces.ChannelRegistry
 }
 
 // SetupRoutesWithDI sets up the routes using the dependencies provided by Dig
@@ -93,6 +99,9 @@ func SetupRoutesWithDI(params DIParams) {
 	publicGroup.Get("/inbox/:id", params.PublicHandler.HandleGetInboxDetails)
 	publicGroup.Get("/conversations/:id/:contact_id", params.PublicHandler.HandleGetConversationDetails)
 
+	// Inbound routes of the inbox channels, e.g. provider webhooks and the API inbox endpoints
+	params.Channels.RegisterRoutes(apiGroup)
+
 	onboardingGroup := apiGroup.Group("/onboarding")
 	onboardingGroup.Post("/user", params.OnboardingHandler.HandleCreateUser)
 
@@ -116,6 +125,10 @@ func SetupRoutesWithDI(params DIParams) {
 	contactGroup.Post("/:id/notes", params.ContactHandler.HandleCreateContactNote)
 	contactGroup.Get("/:id/notes", params.ContactHandler.HandleListContactNotes)
 	contactGroup.Get("/:id/conversations", params.ContactHandler.HandleGetContactConversations)
+	contactGroup.Get("/:id/duplicates", params.ContactHandler.HandleGetContactDuplicates)
+	contactGroup.Get("/:id/merges", params.ContactHandler.HandleListContactMerges)
+	contactGroup.Post("/:id/merge", params.ContactHandler.HandleMergeContact)
+	contactGroup.Post("/merges/:mergeId/undo", params.ContactHandler.HandleUndoContactMerge)
 
 	companyGroup := apiGroup.Group("/companies")
 	companyGroup.Get("/invite/:token", params.CompanyHandler.GetInvite)
@@ -159,12 +172,41 @@ func SetupRoutesWithDI(params DIParams) {
 
 	conversationGroup := apiGroup.Group("/conversations", middleware.Auth(), middleware.RequireCompany())
 	conversationGroup.Get("/", params.ConversationHandler.HandleListConversations)
+	conversationGroup.Get("/mentions", params.ConversationHandler.HandleListMentions)
 	conversationGroup.Get("/:id/assignable-agents", params.ConversationHandler.HandleGetAssignableAgents)
+	conversationGroup.Get("/:id/participants", params.ConversationHandler.HandleGetParticipants)
+	conversationGroup.Post("/:id/participants", params.ConversationHandler.HandleAddParticipant)
+	conversationGroup.Delete("/:id/participants/:userId", params.ConversationHandler.HandleRemoveParticipant)
 	conversationGroup.Get("/:id", params.ConversationHandler.HandleGetConversation)
 	conversationGroup.Get("/:id/messages", params.ConversationHandler.HandleGetConversationMessages)
+	conversationGroup.Put("/:id/messages/:messageId", params.ConversationHandler.HandleEditMessage)
+	conversationGroup.Delete("/:id/messages/:messageId", params.ConversationHandler.HandleDeleteMessage)
+	conversationGroup.Get("/:id/messages/:messageId/edits", params.ConversationHandler.HandleGetMessageEdits)
+	conversationGroup.Post("/:id/read", params.ConversationHandler.HandleMarkConversationRead)
 	conversationGroup.Post("/:id/assign", params.ConversationHandler.HandleAssignConversation)
 	conversationGroup.Post("/:id/close", params.ConversationHandler.HandleCloseConversation)
+	conversationGroup.Post("/:id/resolve", params.ConversationHandler.HandleResolveConversation)
+	conversationGroup.Post("/:id/reopen", params.ConversationHandler.HandleReopenConversation)
+	conversationGroup.Post("/:id/pending", params.ConversationHandler.HandlePendingConversation)
+	conversationGroup.Post("/:id/snooze", params.ConversationHandler.HandleSnoozeConversation)
+	conversationGroup.Post("/:id/wake", params.ConversationHandler.HandleWakeConversation)
+	conversationGroup.Put("/:id/priority", params.ConversationHandler.HandleSetConversationPriority)
+	conversationGroup.Post("/:id/transfer", params.ConversationHandler.HandleTransferConversation)
 	conversationGroup.Post("/:id/attachments", params.ConversationHandler.HandleSendMessageAttachment)
+	conversationGroup.Post("/:id/transcript", params.ConversationHandler.HandleExportTranscript)
+	conversationGroup.Get("/:id/transcripts/:scope/:filename", params.ConversationHandler.HandleDownloadTranscript)
+	conversationGroup.Post("/:id/labels", params.LabelHandler.HandleAddConversationLabels)
+	conversationGroup.Delete("/:id/labels/:labelId", params.LabelHandler.HandleRemoveConversationLabel)
+
+	savedViewGroup := apiGroup.Group("/saved-views", middleware.Auth(), middleware.RequireCompany())
+	savedViewGroup.Get("/", params.SavedViewHandler.HandleListSavedViews)
+	savedViewGroup.Post("/", params.SavedViewHandler.HandleCreateSavedView)
+	savedViewGroup.Get("/:id/run", params.SavedViewHandler.HandleRunSavedView)
+	savedViewGroup.Put("/:id", params.SavedViewHandler.HandleUpdateSavedView)
+	savedViewGroup.Delete("/:id", params.SavedViewHandler.HandleDeleteSavedView)
+
+	searchGroup := apiGroup.Group("/search", middleware.Auth(), middleware.RequireCompany())
+	searchGroup.Get("/", params.SearchHandler.HandleSearch)
 
 	cannedResponseGroup := apiGroup.Group("/canned-responses", middleware.Auth(), middleware.RequireCompany())
 	cannedResponseGroup.Get("/", params.CannedResponseHandler.HandleListCannedResponses)
@@ -172,6 +214,20 @@ func SetupRoutesWithDI(params DIParams) {
 	cannedResponseGroup.Put("/:id", params.CannedResponseHandler.HandleUpdateCannedResponse)
 	cannedResponseGroup.Delete("/:id", params.CannedResponseHandler.HandleDeleteCannedResponse)
 
+	labelGroup := apiGroup.Group("/labels", middleware.Auth(), middleware.RequireCompany())
+	labelGroup.Get("/", params.LabelHandler.HandleListLabels)
+	labelGroup.Post("/", middleware.IsAdmin(), params.LabelHandler.HandleCreateLabel)
+	labelGroup.Put("/:id", middleware.IsAdmin(), params.LabelHandler.HandleUpdateLabel)
+	labelGroup.Delete("/:id", middleware.IsAdmin(), params.LabelHandler.HandleDeleteLabel)
+
+	// SLA policy routes
+	slaPolicyGroup := apiGroup.Group("/sla-policies", middleware.Auth(), middleware.RequireCompany())
+	slaPolicyGroup.Get("/", params.SLAPolicyHandler.HandleListSLAPolicies)
+	slaPolicyGroup.Get("/:id", params.SLAPolicyHandler.HandleGetSLAPolicy)
+	slaPolicyGroup.Post("/", middleware.IsAdmin(), params.SLAPolicyHandler.HandleCreateSLAPolicy)
+	slaPolicyGroup.Put("/:id", middleware.IsAdmin(), params.SLAPolicyHandler.HandleUpdateSLAPolicy)
+	slaPolicyGroup.Delete("/:id", middleware.IsAdmin(), params.SLAPolicyHandler.HandleDeleteSLAPolicy)
+
 	// Analytics routes (Admin only)
 	analyticsGroup := apiGroup.Group("/analytics", middleware.Auth(), middleware.RequireCompany(), middleware.IsAdmin())
 	analyticsGroup.Get("/dashboard", params.AnalyticsHandler.HandleGetAnalyticsDashboard)
@@ -179,6 +235,7 @@ func SetupRoutesWithDI(params DIParams) {
 	analyticsGroup.Get("/agents", params.AnalyticsHandler.HandleGetAgentStats)
 	analyticsGroup.Get("/messages", params.AnalyticsHandler.HandleGetMessageStats)
 	analyticsGroup.Get("/status", params.AnalyticsHandler.HandleGetStatusStats)
+	analyticsGroup.Get("/sla", params.AnalyticsHandler.HandleGetSLAStats)
 
 	// SuperAdmin routes
 	superAdminGroup := apiGroup.Group("/superadmin", middleware.Auth(), middleware.IsSuperAdmin())
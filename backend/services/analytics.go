@@ -10,6 +10,7 @@ type AnalyticsService interface {
 	GetConversationsByAgent(companyID string, startDate, endDate time.Time) ([]repositories.AgentConversationStats, error)
 	GetMessageStats(companyID string, startDate, endDate time.Time) (*repositories.MessageStats, error)
 	GetConversationStatusStats(companyID string, startDate, endDate time.Time) (*repositories.ConversationStatusStats, error)
+	GetSLAStats(companyID string, startDate, endDate time.Time) (*repositories.SLAStats, error)
 	GetAnalyticsDashboard(companyID string, days int) (*AnalyticsDashboard, error)
 }
 
@@ -18,6 +19,7 @@ type AnalyticsDashboard struct {
 	MessageStats            *repositories.MessageStats            `json:"message_stats"`
 	ConversationStatusStats *repositories.ConversationStatusStats `json:"conversation_status_stats"`
 	AgentStats              []repositories.AgentConversationStats `json:"agent_stats"`
+	SLAStats                *repositories.SLAStats                `json:"sla_stats"`
 	DateRange               DateRange                             `json:"date_range"`
 }
 
@@ -53,6 +55,10 @@ func (s *analyticsService) GetConversationStatusStats(companyID string, startDat
 	return s.analyticsRepo.GetConversationStatusStats(companyID, startDate, endDate)
 }
 
+func (s *analyticsService) GetSLAStats(companyID string, startDate, endDate time.Time) (*repositories.SLAStats, error) {
+	return s.analyticsRepo.GetSLAStats(companyID, startDate, endDate)
+}
+
 func (s *analyticsService) GetAnalyticsDashboard(companyID string, days int) (*AnalyticsDashboard, error) {
 	// Calculate date range
 	endDate := time.Now()
@@ -79,11 +85,17 @@ func (s *analyticsService) GetAnalyticsDashboard(companyID string, days int) (*A
 		return nil, err
 	}
 
+	slaStats, err := s.GetSLAStats(companyID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
 	return &AnalyticsDashboard{
 		ConversationStats:       conversationStats,
 		MessageStats:            messageStats,
 		ConversationStatusStats: statusStats,
 		AgentStats:              agentStats,
+		SLAStats:                slaStats,
 		DateRange: DateRange{
 			StartDate: startDate.Format("2006-01-02"),
 			EndDate:   endDate.Format("2006-01-02"),
@@ -28,6 +28,8 @@ func (s *NotificationService) CreateNotification(user *models.User, notification
 	message := ""
 	subject := ""
 
+	// Snooze reminders, SLA alerts and ready transcripts were asked for or are operational,
+	// so they are sent regardless of the user's settings
 	switch notificationType {
 	case models.UserNotificationTypeNewMessage:
 		if !notificationSettings.NewMessage {
@@ -47,6 +49,42 @@ func (s *NotificationService) CreateNotification(user *models.User, notification
 		}
 		message = "notification_content_mention"
 		subject = "notification_subject_mention"
+	case models.UserNotificationTypeSnoozeExpired:
+		message = "notification_content_snooze_expired"
+		subject = "notification_subject_snooze_expired"
+	case models.UserNotificationTypeSLAWarning:
+		message = "notification_content_sla_warning"
+		subject = "notification_subject_sla_warning"
+	case models.UserNotificationTypeSLABreached:
+		message = "notification_content_sla_breached"
+		subject = "notification_subject_sla_breached"
+	case models.UserNotificationTypeParticipantAdded:
+		if !notificationSettings.NewConversation {
+			return nil
+		}
+		message = "notification_content_participant_added"
+		subject = "notification_subject_participant_added"
+	case models.UserNotificationTypeFollowedMessage:
+		if !notificationSettings.NewMessage {
+			return nil
+		}
+		message = "notification_content_followed_message"
+		subject = "notification_subject_followed_message"
+	case models.UserNotificationTypeFollowedStatus:
+		if !notificationSettings.NewConversation {
+			return nil
+		}
+		message = "notification_content_followed_status"
+		subject = "notification_subject_followed_status"
+	case models.UserNotificationTypeFollowedMention:
+		if !notificationSettings.Mentions {
+			return nil
+		}
+		message = "notification_content_followed_mention"
+		subject = "notification_subject_followed_mention"
+	case models.UserNotificationTypeTranscriptReady:
+		message = "notification_content_transcript_ready"
+		subject = "notification_subject_transcript_ready"
 	}
 
 	if notificationSettings.EmailEnabled {
@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"path/filepath"
 	"strings"
@@ -50,15 +51,8 @@ func NewUploadService(storage storage.Manager, config UploadConfig) *UploadServi
 
 // UploadFile handles the upload of an attachment
 func (s *UploadService) UploadFile(fileHeader *multipart.FileHeader, diskLocation string) (*types.UploadResult, error) {
-	// Check file size
-	if fileHeader.Size > s.config.MaxFileSize {
-		return nil, fmt.Errorf("file size exceeds maximum allowed size of %d bytes", s.config.MaxFileSize)
-	}
-
-	// Check file type
-	extension := strings.ToLower(filepath.Ext(fileHeader.Filename))
-	if s.config.AllowedTypes != nil && !s.config.AllowedTypes[extension] {
-		return nil, errors.New("file type not allowed")
+	if err := s.validateFile(fileHeader.Filename, fileHeader.Size); err != nil {
+		return nil, err
 	}
 
 	file, err := fileHeader.Open()
@@ -67,6 +61,39 @@ func (s *UploadService) UploadFile(fileHeader *multipart.FileHeader, diskLocatio
 	}
 	defer file.Close()
 
+	return s.store(fileHeader.Filename, fileHeader.Size, file, diskLocation)
+}
+
+// StoreFile stores a file which didn't come from a multipart upload, such as an email attachment,
+// applying the same size and type limits as uploads
+func (s *UploadService) StoreFile(filename string, size int64, reader io.Reader, diskLocation string) (*types.UploadResult, error) {
+	if err := s.validateFile(filename, size); err != nil {
+		return nil, err
+	}
+
+	return s.store(filename, size, reader, diskLocation)
+}
+
+// validateFile checks the file against the configured size and type limits
+func (s *UploadService) validateFile(filename string, size int64) error {
+	// Check file size
+	if size > s.config.MaxFileSize {
+		return fmt.Errorf("file size exceeds maximum allowed size of %d bytes", s.config.MaxFileSize)
+	}
+
+	// Check file type
+	extension := strings.ToLower(filepath.Ext(filename))
+	if s.config.AllowedTypes != nil && !s.config.AllowedTypes[extension] {
+		return errors.New("file type not allowed")
+	}
+
+	return nil
+}
+
+// store writes the file to storage under a unique name in the disk location
+func (s *UploadService) store(filename string, size int64, reader io.Reader, diskLocation string) (*types.UploadResult, error) {
+	extension := strings.ToLower(filepath.Ext(filename))
+
 	// Get file type category
 	fileType := getFileTypeFromExtension(extension)
 
@@ -75,16 +102,16 @@ func (s *UploadService) UploadFile(fileHeader *multipart.FileHeader, diskLocatio
 	pathLocation := fmt.Sprintf("%s/%d%s", diskLocation, timestamp, extension)
 
 	// Store the file using the storage manager
-	path, err := s.storage.Store(pathLocation, file)
+	path, err := s.storage.Store(pathLocation, reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to store file: %w", err)
 	}
 
 	return &types.UploadResult{
-		Filename:  fileHeader.Filename,
+		Filename:  filename,
 		Path:      path,
 		Extension: extension,
-		Size:      fileHeader.Size,
+		Size:      size,
 		Type:      fileType,
 	}, nil
 }
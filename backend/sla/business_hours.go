@@ -0,0 +1,84 @@
+package sla
+
+import (
+	"live-chat-server/types"
+	"strings"
+	"time"
+)
+
+// maxSearchDays bounds how far ahead AddBusinessTime looks for working hours
+const maxSearchDays = 366
+
+// AddBusinessTime returns the time at which the duration has elapsed counting only the
+// working hours in the location. If no day has working hours the duration is added as is.
+func AddBusinessTime(from time.Time, duration time.Duration, hours types.WorkingHoursMap, loc *time.Location) time.Time {
+	if !hasWorkingHours(hours) {
+		return from.Add(duration)
+	}
+
+	cursor := from.In(loc)
+	remaining := duration
+
+	for day := 0; day < maxSearchDays; day++ {
+		start, end, ok := workingWindow(cursor, hours, loc)
+		if ok {
+			if cursor.Before(start) {
+				cursor = start
+			}
+
+			if cursor.Before(end) {
+				available := end.Sub(cursor)
+				if remaining <= available {
+					return cursor.Add(remaining)
+				}
+				remaining -= available
+			}
+		}
+
+		year, month, date := cursor.Date()
+		cursor = time.Date(year, month, date+1, 0, 0, 0, 0, loc)
+	}
+
+	return from.Add(duration)
+}
+
+// workingWindow returns the working hours of the day the time falls on
+func workingWindow(t time.Time, hours types.WorkingHoursMap, loc *time.Location) (time.Time, time.Time, bool) {
+	wh, ok := hours[strings.ToLower(t.Weekday().String())]
+	if !ok || !wh.Enabled {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start, err := clockOn(t, wh.StartTime, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	end, err := clockOn(t, wh.EndTime, loc)
+	if err != nil || !end.After(start) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return start, end, true
+}
+
+// clockOn returns the "HH:mm" time of day on the date of t
+func clockOn(t time.Time, clock string, loc *time.Location) (time.Time, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	year, month, day := t.Date()
+	return time.Date(year, month, day, parsed.Hour(), parsed.Minute(), 0, 0, loc), nil
+}
+
+// hasWorkingHours reports whether any day of the week has working hours enabled
+func hasWorkingHours(hours types.WorkingHoursMap) bool {
+	for _, wh := range hours {
+		if wh.Enabled {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package sla
+
+import (
+	"live-chat-server/types"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestAddBusinessTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+
+	weekdays := types.WorkingHoursMap{
+		"monday":    {StartTime: "09:00", EndTime: "17:00", Enabled: true},
+		"tuesday":   {StartTime: "09:00", EndTime: "17:00", Enabled: true},
+		"wednesday": {StartTime: "09:00", EndTime: "17:00", Enabled: true},
+		"thursday":  {StartTime: "09:00", EndTime: "17:00", Enabled: true},
+		"friday":    {StartTime: "09:00", EndTime: "17:00", Enabled: true},
+		"saturday":  {StartTime: "10:00", EndTime: "14:00", Enabled: false},
+	}
+
+	// 2024-01-08 is a Monday
+	at := func(day, hour, minute int, loc *time.Location) time.Time {
+		return time.Date(2024, time.January, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		duration time.Duration
+		hours    types.WorkingHoursMap
+		loc      *time.Location
+		want     time.Time
+	}{
+		{
+			name:     "within the working day",
+			from:     at(8, 10, 0, time.UTC),
+			duration: 2 * time.Hour,
+			hours:    weekdays,
+			loc:      time.UTC,
+			want:     at(8, 12, 0, time.UTC),
+		},
+		{
+			name:     "ending exactly at closing time",
+			from:     at(8, 15, 0, time.UTC),
+			duration: 2 * time.Hour,
+			hours:    weekdays,
+			loc:      time.UTC,
+			want:     at(8, 17, 0, time.UTC),
+		},
+		{
+			name:     "before opening",
+			from:     at(8, 7, 0, time.UTC),
+			duration: time.Hour,
+			hours:    weekdays,
+			loc:      time.UTC,
+			want:     at(8, 10, 0, time.UTC),
+		},
+		{
+			name:     "after closing",
+			from:     at(8, 18, 0, time.UTC),
+			duration: time.Hour,
+			hours:    weekdays,
+			loc:      time.UTC,
+			want:     at(9, 10, 0, time.UTC),
+		},
+		{
+			name:     "across days",
+			from:     at(8, 16, 0, time.UTC),
+			duration: 3 * time.Hour,
+			hours:    weekdays,
+			loc:      time.UTC,
+			want:     at(9, 11, 0, time.UTC),
+		},
+		{
+			name:     "several working days",
+			from:     at(8, 9, 0, time.UTC),
+			duration: 24 * time.Hour,
+			hours:    weekdays,
+			loc:      time.UTC,
+			want:     at(10, 17, 0, time.UTC),
+		},
+		{
+			name:     "across the weekend",
+			from:     at(12, 16, 0, time.UTC),
+			duration: 2 * time.Hour,
+			hours:    weekdays,
+			loc:      time.UTC,
+			want:     at(15, 10, 0, time.UTC),
+		},
+		{
+			name:     "starting on a disabled day",
+			from:     at(13, 12, 0, time.UTC),
+			duration: 30 * time.Minute,
+			hours:    weekdays,
+			loc:      time.UTC,
+			want:     at(15, 9, 30, time.UTC),
+		},
+		{
+			name:     "no working hours",
+			from:     at(13, 12, 0, time.UTC),
+			duration: 30 * time.Minute,
+			hours:    types.WorkingHoursMap{"monday": {StartTime: "09:00", EndTime: "17:00", Enabled: false}},
+			loc:      time.UTC,
+			want:     at(13, 12, 30, time.UTC),
+		},
+		{
+			name:     "working hours in the company's timezone",
+			from:     at(8, 13, 0, time.UTC),
+			duration: time.Hour,
+			hours:    weekdays,
+			loc:      newYork,
+			want:     at(8, 10, 0, newYork),
+		},
+		{
+			name:     "previous local day in the company's timezone",
+			from:     at(9, 3, 0, time.UTC),
+			duration: time.Hour,
+			hours:    weekdays,
+			loc:      newYork,
+			want:     at(9, 10, 0, newYork),
+		},
+		{
+			name:     "across a daylight saving change",
+			from:     time.Date(2024, time.March, 8, 16, 0, 0, 0, newYork),
+			duration: 2 * time.Hour,
+			hours:    weekdays,
+			loc:      newYork,
+			want:     time.Date(2024, time.March, 11, 10, 0, 0, 0, newYork),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AddBusinessTime(tt.from, tt.duration, tt.hours, tt.loc); !got.Equal(tt.want) {
+				t.Errorf("AddBusinessTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package sms
+
+import (
+	"fmt"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+)
+
+const (
+	ProviderTwilio = "twilio"
+)
+
+// NewProvider creates the SMS provider configured for an SMS inbox
+func NewProvider(config *models.InboxSMS, logger interfaces.Logger) (interfaces.SMSProvider, error) {
+	if config == nil {
+		return nil, fmt.Errorf("inbox has no sms configuration")
+	}
+
+	switch config.Provider {
+	case ProviderTwilio, "":
+		return NewTwilioProvider(config.AccountSID, config.AuthToken, config.PhoneNumber, config.APIBaseURL, logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported sms provider: %s", config.Provider)
+	}
+}
@@ -0,0 +1,160 @@
+package sms
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"live-chat-server/interfaces"
+	"live-chat-server/types"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TwilioAPIBaseURL is the default endpoint of the Twilio REST API
+const TwilioAPIBaseURL = "https://api.twilio.com"
+
+// TwilioProvider sends and receives text messages through the Twilio API.
+// Any gateway implementing the same API can be used by overriding the base URL.
+type TwilioProvider struct {
+	accountSID  string
+	authToken   string
+	phoneNumber string
+	baseURL     string
+	client      *http.Client
+	logger      interfaces.Logger
+}
+
+// NewTwilioProvider creates a new Twilio provider
+func NewTwilioProvider(accountSID, authToken, phoneNumber, baseURL string, logger interfaces.Logger) *TwilioProvider {
+	if baseURL == "" {
+		baseURL = TwilioAPIBaseURL
+	}
+
+	return &TwilioProvider{
+		accountSID:  accountSID,
+		authToken:   authToken,
+		phoneNumber: phoneNumber,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		client:      &http.Client{Timeout: 15 * time.Second},
+		logger:      logger,
+	}
+}
+
+// Send sends a text message from the inbox's phone number
+func (p *TwilioProvider) Send(to, body string) (string, error) {
+	form := url.Values{}
+	form.Set("From", p.phoneNumber)
+	form.Set("To", to)
+	form.Set("Body", body)
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", p.baseURL, url.PathEscape(p.accountSID))
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		p.logger.Error("Failed to send SMS to %s: %v", to, err)
+		return "", fmt.Errorf("failed to send sms: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiError struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(respBody, &apiError); err == nil && apiError.Message != "" {
+			return "", fmt.Errorf("sms provider returned %d: %s (code %d)", resp.StatusCode, apiError.Message, apiError.Code)
+		}
+		return "", fmt.Errorf("sms provider returned %d", resp.StatusCode)
+	}
+
+	var result struct {
+		SID string `json:"sid"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.SID, nil
+}
+
+// SignatureHeader returns the header Twilio signs webhook requests with
+func (p *TwilioProvider) SignatureHeader() string {
+	return "X-Twilio-Signature"
+}
+
+// VerifySignature checks the base64 HMAC-SHA1 of the webhook URL followed by
+// the sorted POST parameters, keyed with the account's auth token.
+// The values of a repeated parameter are signed one after the other, sorted.
+func (p *TwilioProvider) VerifySignature(webhookURL string, params url.Values, signature string) bool {
+	if signature == "" || p.authToken == "" {
+		return false
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var data strings.Builder
+	data.WriteString(webhookURL)
+	for _, key := range keys {
+		values := append([]string(nil), params[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			data.WriteString(key)
+			data.WriteString(value)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(p.authToken))
+	mac.Write([]byte(data.String()))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// ParseInbound extracts the message from the parameters of a Twilio messaging webhook
+func (p *TwilioProvider) ParseInbound(params url.Values) (*types.InboundSMS, error) {
+	message := &types.InboundSMS{
+		MessageID: params.Get("MessageSid"),
+		From:      params.Get("From"),
+		To:        params.Get("To"),
+		Body:      params.Get("Body"),
+	}
+
+	if message.MessageID == "" {
+		message.MessageID = params.Get("SmsSid")
+	}
+
+	if message.From == "" {
+		return nil, fmt.Errorf("inbound sms has no sender")
+	}
+
+	numMedia, _ := strconv.Atoi(params.Get("NumMedia"))
+	for i := 0; i < numMedia; i++ {
+		if mediaURL := params.Get("MediaUrl" + strconv.Itoa(i)); mediaURL != "" {
+			message.MediaURLs = append(message.MediaURLs, mediaURL)
+		}
+	}
+
+	return message, nil
+}
@@ -0,0 +1,213 @@
+package sms
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"live-chat-server/interfaces"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	testAuthToken  = "test_auth_token"
+	testWebhookURL = "https://chat.example.com/api/public/sms/inbox-1/webhook"
+)
+
+// sign returns the signature of the data Twilio signs, as written out in the test case
+func sign(authToken, data string) string {
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestTwilioVerifySignature(t *testing.T) {
+	params := url.Values{
+		"MessageSid": {"SM123"},
+		"From":       {"+15005550006"},
+		"Body":       {"Hello"},
+	}
+	signed := testWebhookURL + "BodyHello" + "From+15005550006" + "MessageSidSM123"
+
+	repeated := url.Values{
+		"From":     {"+15005550006"},
+		"MediaTag": {"b", "a"},
+	}
+	repeatedSigned := testWebhookURL + "From+15005550006" + "MediaTaga" + "MediaTagb"
+
+	tests := []struct {
+		name      string
+		authToken string
+		url       string
+		params    url.Values
+		signature string
+		want      bool
+	}{
+		{
+			name:      "valid",
+			authToken: testAuthToken,
+			url:       testWebhookURL,
+			params:    params,
+			signature: sign(testAuthToken, signed),
+			want:      true,
+		},
+		{
+			name:      "tampered parameter",
+			authToken: testAuthToken,
+			url:       testWebhookURL,
+			params:    url.Values{"MessageSid": {"SM123"}, "From": {"+15005550006"}, "Body": {"Goodbye"}},
+			signature: sign(testAuthToken, signed),
+			want:      false,
+		},
+		{
+			name:      "other webhook url",
+			authToken: testAuthToken,
+			url:       "https://chat.example.com/api/public/sms/inbox-2/webhook",
+			params:    params,
+			signature: sign(testAuthToken, signed),
+			want:      false,
+		},
+		{
+			name:      "signed with another token",
+			authToken: testAuthToken,
+			url:       testWebhookURL,
+			params:    params,
+			signature: sign("another_token", signed),
+			want:      false,
+		},
+		{
+			name:      "repeated parameter",
+			authToken: testAuthToken,
+			url:       testWebhookURL,
+			params:    repeated,
+			signature: sign(testAuthToken, repeatedSigned),
+			want:      true,
+		},
+		{
+			name:      "repeated parameter with a value dropped",
+			authToken: testAuthToken,
+			url:       testWebhookURL,
+			params:    url.Values{"From": {"+15005550006"}, "MediaTag": {"a"}},
+			signature: sign(testAuthToken, repeatedSigned),
+			want:      false,
+		},
+		{
+			name:      "missing signature",
+			authToken: testAuthToken,
+			url:       testWebhookURL,
+			params:    params,
+			signature: "",
+			want:      false,
+		},
+		{
+			name:      "inbox without auth token",
+			authToken: "",
+			url:       testWebhookURL,
+			params:    params,
+			signature: sign("", signed),
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewTwilioProvider("AC123", tt.authToken, "+15005550006", "", nopLogger{})
+			if got := provider.VerifySignature(tt.url, tt.params, tt.signature); got != tt.want {
+				t.Errorf("VerifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTwilioParseInbound(t *testing.T) {
+	provider := NewTwilioProvider("AC123", testAuthToken, "+15005550006", "", nopLogger{})
+
+	message, err := provider.ParseInbound(url.Values{
+		"SmsSid":    {"SM456"},
+		"From":      {"+15551234567"},
+		"To":        {"+15005550006"},
+		"Body":      {"Photos attached"},
+		"NumMedia":  {"2"},
+		"MediaUrl0": {"https://media.example.com/0"},
+		"MediaUrl1": {"https://media.example.com/1"},
+	})
+	if err != nil {
+		t.Fatalf("ParseInbound() error = %v", err)
+	}
+
+	if message.MessageID != "SM456" || message.From != "+15551234567" || message.To != "+15005550006" || message.Body != "Photos attached" {
+		t.Errorf("ParseInbound() = %+v", message)
+	}
+	if want := []string{"https://media.example.com/0", "https://media.example.com/1"}; !reflect.DeepEqual(message.MediaURLs, want) {
+		t.Errorf("MediaURLs = %v, want %v", message.MediaURLs, want)
+	}
+
+	if _, err := provider.ParseInbound(url.Values{"Body": {"Hello"}}); err == nil {
+		t.Error("ParseInbound() without a sender succeeded")
+	}
+}
+
+func TestTwilioSend(t *testing.T) {
+	var received url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if user, password, ok := r.BasicAuth(); !ok || user != "AC123" || password != testAuthToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = r.PostForm
+
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.HasPrefix(r.PostForm.Get("To"), "+") {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code": 21211, "message": "Invalid 'To' Phone Number"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid": "SM789"}`))
+	}))
+	defer server.Close()
+
+	provider := NewTwilioProvider("AC123", testAuthToken, "+15005550006", server.URL, nopLogger{})
+
+	sid, err := provider.Send("+15551234567", "Thanks for your message")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sid != "SM789" {
+		t.Errorf("Send() = %q, want SM789", sid)
+	}
+	if received.Get("From") != "+15005550006" || received.Get("To") != "+15551234567" || received.Get("Body") != "Thanks for your message" {
+		t.Errorf("provider received %v", received)
+	}
+
+	_, err = provider.Send("5551234567", "Thanks for your message")
+	if err == nil || !strings.Contains(err.Error(), "Invalid 'To' Phone Number") {
+		t.Errorf("Send() error = %v, want the provider's error message", err)
+	}
+}
+
+// nopLogger discards every log entry
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{})                  {}
+func (nopLogger) Info(msg string, args ...interface{})                   {}
+func (nopLogger) Warn(msg string, args ...interface{})                   {}
+func (nopLogger) Error(msg string, args ...interface{})                  {}
+func (nopLogger) Fatal(msg string, args ...interface{})                  {}
+func (l nopLogger) With(fields map[string]interface{}) interfaces.Logger { return l }
+func (l nopLogger) Named(name string) interfaces.Logger                  { return l }
@@ -0,0 +1,49 @@
+package transcript
+
+import (
+	"bytes"
+	"html/template"
+)
+
+var htmlTemplate = template.Must(template.New("transcript").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Arial, Helvetica, sans-serif; color: #1f2937; max-width: 760px; margin: 32px auto; padding: 0 16px; }
+h1 { font-size: 20px; margin-bottom: 4px; }
+.generated { color: #6b7280; font-size: 13px; margin-bottom: 24px; }
+.entry { border-left: 3px solid #e5e7eb; padding: 8px 12px; margin-bottom: 12px; }
+.entry.contact { border-color: #3b82f6; }
+.entry.agent { border-color: #10b981; }
+.entry.private { background: #fef3c7; border-color: #f59e0b; }
+.label { color: #6b7280; font-size: 12px; margin-bottom: 4px; }
+.content { white-space: pre-wrap; }
+.deleted { color: #9ca3af; font-style: italic; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="generated">Generated {{.Generated}}</div>
+{{range .Entries}}<div class="entry {{.SenderType}}{{if .Private}} private{{end}}">
+<div class="label">{{.Label}}</div>
+{{if .Deleted}}<div class="deleted">This message was deleted</div>{{else if .AttachmentURL}}<div class="content">Attachment: <a href="{{.AttachmentURL}}">{{.AttachmentName}}</a></div>{{else}}<div class="content">{{.Content}}</div>{{end}}
+</div>
+{{end}}</body>
+</html>
+`))
+
+func (t *Transcript) renderHTML() ([]byte, error) {
+	var buf bytes.Buffer
+	err := htmlTemplate.Execute(&buf, map[string]interface{}{
+		"Title":     t.Title(),
+		"Generated": t.GeneratedAt.Format(timeLayout),
+		"Entries":   t.Entries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
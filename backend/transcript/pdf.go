@@ -0,0 +1,52 @@
+package transcript
+
+import (
+	"bytes"
+
+	"github.com/go-pdf/fpdf"
+)
+
+func (t *Transcript) renderPDF() ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	// The core fonts only cover Windows-1252, so the text is translated to it
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.MultiCell(0, 7, tr(t.Title()), "", "L", false)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(107, 114, 128)
+	pdf.MultiCell(0, 5, tr("Generated "+t.GeneratedAt.Format(timeLayout)), "", "L", false)
+	pdf.Ln(4)
+
+	for _, entry := range t.Entries {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetTextColor(107, 114, 128)
+		pdf.MultiCell(0, 5, tr(entry.Label()), "", "L", false)
+
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.SetTextColor(31, 41, 55)
+		switch {
+		case entry.Deleted:
+			pdf.SetFont("Helvetica", "I", 10)
+			pdf.MultiCell(0, 5, tr(entry.Body()), "", "L", false)
+		case entry.AttachmentURL != "":
+			pdf.SetTextColor(37, 99, 235)
+			pdf.WriteLinkString(5, tr("Attachment: "+entry.AttachmentName), entry.AttachmentURL)
+			pdf.Ln(5)
+		default:
+			pdf.MultiCell(0, 5, tr(entry.Content), "", "L", false)
+		}
+		pdf.Ln(3)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,19 @@
+package transcript
+
+import (
+	"strings"
+)
+
+func (t *Transcript) renderText() []byte {
+	var b strings.Builder
+
+	b.WriteString(t.Title() + "\n")
+	b.WriteString("Generated " + t.GeneratedAt.Format(timeLayout) + "\n")
+
+	for _, entry := range t.Entries {
+		b.WriteString("\n" + entry.Label() + "\n")
+		b.WriteString(entry.Body() + "\n")
+	}
+
+	return []byte(b.String())
+}
@@ -0,0 +1,166 @@
+package transcript
+
+import (
+	"fmt"
+	"live-chat-server/models"
+	"live-chat-server/utils"
+	"path"
+	"time"
+)
+
+// Format is the kind of file a transcript is rendered to
+type Format string
+
+const (
+	FormatHTML Format = "html"
+	FormatText Format = "text"
+	FormatPDF  Format = "pdf"
+)
+
+// timeLayout is how message times are shown, in the reader's timezone
+const timeLayout = "2006-01-02 15:04:05 MST"
+
+// Extension returns the file extension of the format
+func (f Format) Extension() string {
+	if f == FormatText {
+		return ".txt"
+	}
+	return "." + string(f)
+}
+
+// Entry is a message of the transcript
+type Entry struct {
+	SenderName     string
+	SenderType     models.SenderType
+	Private        bool
+	SentAt         time.Time
+	Content        string
+	AttachmentName string
+	AttachmentURL  string
+	Edited         bool
+	Deleted        bool
+}
+
+// Transcript is a conversation's messages as they are rendered for a reader
+type Transcript struct {
+	ConversationID string
+	InboxName      string
+	ContactName    string
+	Location       *time.Location
+	GeneratedAt    time.Time
+	Entries        []Entry
+}
+
+// New builds the transcript of a conversation's messages, with the times in the location.
+// Private notes are left out unless includePrivate is set.
+func New(conversation *models.Conversation, messages []models.Message, loc *time.Location, includePrivate bool) *Transcript {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	t := &Transcript{
+		ConversationID: conversation.ID,
+		InboxName:      conversation.Inbox.Name,
+		ContactName:    utils.GetStringValue(conversation.Contact.Name),
+		Location:       loc,
+		GeneratedAt:    time.Now().In(loc),
+		Entries:        make([]Entry, 0, len(messages)),
+	}
+
+	for _, message := range messages {
+		if message.Private && !includePrivate {
+			continue
+		}
+
+		entry := Entry{
+			SenderName: senderName(&message),
+			SenderType: message.SenderType,
+			Private:    message.Private,
+			SentAt:     message.CreatedAt.In(loc),
+			Edited:     message.EditedAt != nil,
+			Deleted:    message.IsTombstone(),
+		}
+
+		if !entry.Deleted {
+			if message.Type == models.MessageTypeFile || message.Type == models.MessageTypeImage {
+				entry.AttachmentName = attachmentName(&message)
+				entry.AttachmentURL = utils.Asset(message.Content)
+			} else {
+				entry.Content = message.Content
+			}
+		}
+
+		t.Entries = append(t.Entries, entry)
+	}
+
+	return t
+}
+
+// Render renders the transcript to the format
+func (t *Transcript) Render(format Format) ([]byte, error) {
+	switch format {
+	case FormatHTML:
+		return t.renderHTML()
+	case FormatText:
+		return t.renderText(), nil
+	case FormatPDF:
+		return t.renderPDF()
+	}
+	return nil, fmt.Errorf("unknown transcript format %q", format)
+}
+
+// Title is the heading of the transcript
+func (t *Transcript) Title() string {
+	if t.ContactName == "" {
+		return "Conversation with " + t.InboxName
+	}
+	return fmt.Sprintf("Conversation between %s and %s", t.ContactName, t.InboxName)
+}
+
+// Body is what an entry shows in place of its content
+func (e Entry) Body() string {
+	switch {
+	case e.Deleted:
+		return "This message was deleted"
+	case e.AttachmentURL != "":
+		return fmt.Sprintf("Attachment: %s (%s)", e.AttachmentName, e.AttachmentURL)
+	}
+	return e.Content
+}
+
+// Label is the sender and time line of an entry
+func (e Entry) Label() string {
+	label := fmt.Sprintf("%s - %s", e.SenderName, e.SentAt.Format(timeLayout))
+	if e.Private {
+		label += " (private note)"
+	}
+	if e.Edited && !e.Deleted {
+		label += " (edited)"
+	}
+	return label
+}
+
+func senderName(message *models.Message) string {
+	if name := message.GetSenderName(); name != "" {
+		return name
+	}
+
+	switch message.SenderType {
+	case models.SenderTypeContact:
+		return "Contact"
+	case models.SenderTypeAgent:
+		return "Agent"
+	case models.SenderTypeBot:
+		return "Bot"
+	}
+	return "System"
+}
+
+func attachmentName(message *models.Message) string {
+	if metadata, ok := message.Metadata.(map[string]interface{}); ok {
+		if filename, ok := metadata["filename"].(string); ok && filename != "" {
+			return filename
+		}
+	}
+	return path.Base(message.Content)
+}
@@ -0,0 +1,58 @@
+package types
+
+import "time"
+
+// InboundEmail represents an email fetched from an email inbox mailbox
+type InboundEmail struct {
+	UID         uint32
+	MessageID   string
+	InReplyTo   string
+	References  []string
+	FromName    string
+	FromEmail   string
+	To          []string
+	Subject     string
+	TextBody    string
+	HTMLBody    string
+	Date        time.Time
+	Attachments []InboundAttachment
+}
+
+// InboundAttachment represents a file attached to, or an image embedded in, an inbound email
+type InboundAttachment struct {
+	Filename    string
+	ContentType string
+	ContentID   string
+	Inline      bool
+	Data        []byte
+}
+
+// ThreadMessageIDs returns the message IDs this email refers to, most recent first
+func (e *InboundEmail) ThreadMessageIDs() []string {
+	ids := make([]string, 0, len(e.References)+1)
+	if e.InReplyTo != "" {
+		ids = append(ids, e.InReplyTo)
+	}
+
+	for i := len(e.References) - 1; i >= 0; i-- {
+		if e.References[i] != "" && e.References[i] != e.InReplyTo {
+			ids = append(ids, e.References[i])
+		}
+	}
+
+	return ids
+}
+
+// EmailMessageMetadata is stored on messages that were received or sent by email
+type EmailMessageMetadata struct {
+	MessageID  string   `json:"message_id"`
+	InReplyTo  string   `json:"in_reply_to,omitempty"`
+	References []string `json:"references,omitempty"`
+	Subject    string   `json:"subject,omitempty"`
+	From       string   `json:"from,omitempty"`
+	TextBody   string   `json:"text_body,omitempty"`
+	HTMLBody   string   `json:"html_body,omitempty"`
+
+	// Pending is set on a reply whose headers were generated but which wasn't delivered yet
+	Pending bool `json:"pending,omitempty"`
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestThreadMessageIDs(t *testing.T) {
+	tests := []struct {
+		name  string
+		email InboundEmail
+		want  []string
+	}{
+		{
+			name:  "no thread headers",
+			email: InboundEmail{},
+			want:  []string{},
+		},
+		{
+			name:  "in-reply-to only",
+			email: InboundEmail{InReplyTo: "b@example.com"},
+			want:  []string{"b@example.com"},
+		},
+		{
+			name: "references are most recent first",
+			email: InboundEmail{
+				References: []string{"a@example.com", "b@example.com", "c@example.com"},
+			},
+			want: []string{"c@example.com", "b@example.com", "a@example.com"},
+		},
+		{
+			name: "in-reply-to comes first and isn't repeated",
+			email: InboundEmail{
+				InReplyTo:  "b@example.com",
+				References: []string{"a@example.com", "b@example.com", ""},
+			},
+			want: []string{"b@example.com", "a@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.email.ThreadMessageIDs(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ThreadMessageIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
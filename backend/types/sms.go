@@ -0,0 +1,17 @@
+package types
+
+// InboundSMS represents a text message received by an SMS inbox
+type InboundSMS struct {
+	MessageID string
+	From      string
+	To        string
+	Body      string
+	MediaURLs []string
+}
+
+// SMSMessageMetadata is stored on messages that were received or sent by SMS
+type SMSMessageMetadata struct {
+	MessageID string `json:"message_id"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+}
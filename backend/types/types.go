@@ -17,6 +17,27 @@ type ContactPayload struct {
 	UpdatedAt string `json:"updated_at"`
 }
 
+type ContactMergePayload struct {
+	ID                 string          `json:"id"`
+	PrimaryContactID   string          `json:"primary_contact_id"`
+	SecondaryContactID string          `json:"secondary_contact_id"`
+	PrimaryContact     *ContactPayload `json:"primary_contact,omitempty"`
+	SecondaryContact   *ContactPayload `json:"secondary_contact,omitempty"`
+	UserID             string          `json:"user_id"`
+	ConversationCount  int             `json:"conversation_count"`
+	NoteCount          int             `json:"note_count"`
+	FilledFields       []string        `json:"filled_fields"`
+	UndoableUntil      string          `json:"undoable_until"`
+	UndoneAt           string          `json:"undone_at,omitempty"`
+	CreatedAt          string          `json:"created_at"`
+}
+
+// ContactDuplicatePayload is a contact suggested as a duplicate, with the details it shares
+type ContactDuplicatePayload struct {
+	Contact   ContactPayload `json:"contact"`
+	MatchedOn []string       `json:"matched_on"`
+}
+
 type UserInboxPayload struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -42,30 +63,50 @@ type InboxPayload struct {
 	MaxAutoAssignments    int                `json:"max_auto_assignments"`
 	AutoResponderEnabled  bool               `json:"auto_responder_enabled"`
 	AutoResponderMessage  string             `json:"auto_responder_message"`
+	SendTranscriptOnClose bool               `json:"send_transcript_on_close"`
+	SLAPolicyID           string             `json:"sla_policy_id,omitempty"`
 	UserCount             int                `json:"user_count"`
 	CreatedAt             string             `json:"created_at"`
 	UpdatedAt             string             `json:"updated_at"`
 	Users                 []UserInboxPayload `json:"users"`
 
-	// Web Chat specific fields
-	WelcomeMessage      string              `json:"welcome_message,omitempty"`
-	WorkingHours        WorkingHoursMap     `json:"working_hours,omitempty"`
-	WorkingHoursEnabled bool                `json:"working_hours_enabled,omitempty"`
-	OutsideHoursMessage string              `json:"outside_hours_message,omitempty"`
-	WidgetCustomization WidgetCustomization `json:"widget_customization,omitempty"`
-	PreChatForm         *PreChatForm        `json:"pre_chat_form,omitempty"`
-
-	// Email specific fields
-	ImapServer string `json:"imap_server,omitempty"`
-	ImapPort   int    `json:"imap_port,omitempty"`
-	SmtpServer string `json:"smtp_server,omitempty"`
-	SmtpPort   int    `json:"smtp_port,omitempty"`
-	Username   string `json:"username,omitempty"`
+	// Config holds the fields of the inbox's channel, added to the payload by the channel.
+	// They are serialized next to the common fields.
+	Config InboxTypeConfig `json:"-"`
 
 	// Used for any custom inbox-type configurations that don't have dedicated fields
 	TypeConfig InboxTypeConfig `json:"type_config,omitempty"`
 }
 
+// MarshalJSON serializes the payload with the channel fields next to the common fields
+func (p InboxPayload) MarshalJSON() ([]byte, error) {
+	type inboxPayload InboxPayload
+	data, err := json.Marshal(inboxPayload(p))
+	if err != nil || len(p.Config) == 0 {
+		return data, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for key, value := range p.Config {
+		// The common fields can't be overridden by a channel
+		if _, ok := fields[key]; ok {
+			continue
+		}
+
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = raw
+	}
+
+	return json.Marshal(fields)
+}
+
 type InboxDeletedPayload struct {
 	ID string `json:"id"`
 }
@@ -92,6 +133,7 @@ type Sender struct {
 }
 
 type MessagePayload struct {
+	ID             string      `json:"id"`
 	ConversationID string      `json:"conversation_id"`
 	Name           string      `json:"name"`
 	Content        string      `json:"content"`
@@ -100,6 +142,38 @@ type MessagePayload struct {
 	Private        bool        `json:"private"`
 	Metadata       interface{} `json:"metadata,omitempty"`
 	Timestamp      string      `json:"timestamp"`
+	Edited         bool        `json:"edited"`
+	EditedAt       string      `json:"edited_at,omitempty"`
+	Deleted        bool        `json:"deleted"`
+	DeletedAt      string      `json:"deleted_at,omitempty"`
+
+	ReplyToMessageID string                `json:"reply_to_message_id,omitempty"`
+	ReplyTo          *QuotedMessagePayload `json:"reply_to,omitempty"`
+}
+
+// MentionPayload is a private note mentioning the agent, read once they have read the conversation up to it
+type MentionPayload struct {
+	Message MessagePayload `json:"message"`
+	Read    bool           `json:"read"`
+}
+
+// QuotedMessagePayload is the compact preview of the message a reply quotes
+type QuotedMessagePayload struct {
+	ID      string `json:"id"`
+	Sender  Sender `json:"sender"`
+	Content string `json:"content"`
+	Type    string `json:"type"`
+	Deleted bool   `json:"deleted"`
+}
+
+type MessageEditPayload struct {
+	ID              string `json:"id"`
+	MessageID       string `json:"message_id"`
+	EditorType      string `json:"editor_type"`
+	EditorID        string `json:"editor_id"`
+	PreviousContent string `json:"previous_content"`
+	Content         string `json:"content"`
+	CreatedAt       string `json:"created_at"`
 }
 
 type AgentPayload struct {
@@ -113,6 +187,7 @@ type ConversationPayload struct {
 	InboxID        string      `json:"inbox_id"`
 	ConversationID string      `json:"conversation_id"`
 	Status         string      `json:"status"`
+	Priority       string      `json:"priority"`
 	Metadata       interface{} `json:"metadata"`
 	AssignedTo     *struct {
 		ID   string `json:"id"`
@@ -134,10 +209,71 @@ type ConversationPayload struct {
 		ID   string `json:"id"`
 		Name string `json:"name"`
 	} `json:"inbox"`
-	UpdatedAt     string `json:"updated_at"`
-	CreatedAt     string `json:"created_at"`
-	LastMessage   string `json:"last_message"`
-	LastMessageAt string `json:"last_message_at"`
+	UpdatedAt     string                  `json:"updated_at"`
+	CreatedAt     string                  `json:"created_at"`
+	LastMessage   string                  `json:"last_message"`
+	LastMessageAt string                  `json:"last_message_at"`
+	SnoozedUntil  string                  `json:"snoozed_until,omitempty"`
+	Labels        []LabelPayload          `json:"labels"`
+	SLA           *ConversationSLAPayload `json:"sla,omitempty"`
+
+	// UnreadCount and Receipts are only set when the conversation is loaded for a reader
+	UnreadCount *int                         `json:"unread_count,omitempty"`
+	Receipts    []ConversationReceiptPayload `json:"receipts,omitempty"`
+
+	// MessagesBefore and MessagesAfter are the cursors of the older and newer pages of messages
+	MessagesBefore string `json:"messages_before,omitempty"`
+	MessagesAfter  string `json:"messages_after,omitempty"`
+
+	// Participants are the agents following or collaborating on the conversation, only set when they are loaded
+	Participants []ConversationParticipantPayload `json:"participants,omitempty"`
+}
+
+// MessagePagePayload is a page of a conversation's messages, Before and After are the cursors
+// of the older and newer pages and are empty when there are none
+type MessagePagePayload struct {
+	Messages []MessagePayload `json:"messages"`
+	Before   string           `json:"before,omitempty"`
+	After    string           `json:"after,omitempty"`
+}
+
+// ConversationParticipantPayload is an agent following or collaborating on a conversation
+type ConversationParticipantPayload struct {
+	UserID    string  `json:"user_id"`
+	Name      string  `json:"name"`
+	Avatar    string  `json:"avatar"`
+	Role      string  `json:"role"`
+	AddedByID *string `json:"added_by_id"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// ConversationReceiptPayload tells how far a reader has received and read a conversation
+type ConversationReceiptPayload struct {
+	ConversationID         string `json:"conversation_id"`
+	ReaderType             string `json:"reader_type"`
+	ReaderID               string `json:"reader_id"`
+	LastDeliveredMessageID string `json:"last_delivered_message_id,omitempty"`
+	LastDeliveredAt        string `json:"last_delivered_at,omitempty"`
+	LastReadMessageID      string `json:"last_read_message_id,omitempty"`
+	LastReadAt             string `json:"last_read_at,omitempty"`
+}
+
+type ConversationSLAPayload struct {
+	PolicyID              string `json:"policy_id"`
+	FirstResponseDueAt    string `json:"first_response_due_at,omitempty"`
+	NextResponseDueAt     string `json:"next_response_due_at,omitempty"`
+	ResolutionDueAt       string `json:"resolution_due_at,omitempty"`
+	FirstResponseAt       string `json:"first_response_at,omitempty"`
+	FirstResponseBreached bool   `json:"first_response_breached"`
+	NextResponseBreached  bool   `json:"next_response_breached"`
+	ResolutionBreached    bool   `json:"resolution_breached"`
+}
+
+type LabelPayload struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
 }
 
 type ContactNotePayload struct {
@@ -188,6 +324,22 @@ type PreChatFormField struct {
 	Options      []string `json:"options,omitempty"`       // For select fields
 	ContactField string   `json:"contact_field,omitempty"` // Maps to a contact property: "name", "email", "phone"
 	Value        string   `json:"value,omitempty"`         // The actual value of the field
+	// ConversationField maps the answer to a conversation property: "priority"
+	ConversationField string `json:"conversation_field,omitempty"`
+	// PriorityOptions maps the options of a select field to a priority, e.g. "Billing outage" to "urgent"
+	PriorityOptions map[string]string `json:"priority_options,omitempty"`
+}
+
+// Priority returns the conversation priority the field's answer sets, or an empty string if it sets none
+func (f PreChatFormField) Priority() string {
+	if f.ConversationField != "priority" {
+		return ""
+	}
+
+	if priority, ok := f.PriorityOptions[f.Value]; ok {
+		return priority
+	}
+	return f.Value
 }
 
 // PreChatForm represents the pre-chat form configuration
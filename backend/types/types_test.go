@@ -0,0 +1,40 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInboxPayloadMarshalJSONInlinesConfig(t *testing.T) {
+	payload := InboxPayload{
+		ID:   "inbox-1",
+		Name: "Support",
+		Type: "email",
+		Config: InboxTypeConfig{
+			"imap_server": "imap.example.com",
+			"imap_port":   993,
+			// Common fields can't be overridden
+			"name": "Overridden",
+		},
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	if fields["imap_server"] != "imap.example.com" || fields["imap_port"] != float64(993) {
+		t.Errorf("channel fields = %v, %v", fields["imap_server"], fields["imap_port"])
+	}
+	if fields["name"] != "Support" {
+		t.Errorf("name = %v, want Support", fields["name"])
+	}
+	if _, ok := fields["Config"]; ok {
+		t.Error("Config is serialized as a field")
+	}
+}
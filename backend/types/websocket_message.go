@@ -1,7 +1,6 @@
 package types
 
 import (
-	"encoding/json"
 	"time"
 )
 
@@ -23,23 +22,59 @@ const (
 	EventTypeConversationTyping      EventType = "conversation_typing"
 	EventTypeConversationTypingStop  EventType = "conversation_typing_stop"
 	EventTypeConversationClose       EventType = "conversation_close"
+	EventTypeConversationResolve     EventType = "conversation_resolve"
+	EventTypeConversationReopen      EventType = "conversation_reopen"
+	EventTypeConversationPending     EventType = "conversation_pending"
+	EventTypeConversationSnooze      EventType = "conversation_snooze"
+	EventTypeConversationWake        EventType = "conversation_wake"
+	EventTypeConversationLabels      EventType = "conversation_labels_updated"
+	EventTypeConversationPriority    EventType = "conversation_priority"
+	EventTypeConversationTransfer    EventType = "conversation_transfer"
+	EventTypeConversationRead        EventType = "conversation_read"
+	EventTypeConversationReceipt     EventType = "conversation_receipt"
+
+	EventTypeConversationParticipants EventType = "conversation_participants_updated"
+
+	// Transcript events, the widget asks for its conversation by email and is told once it is sent
+	EventTypeConversationSendTranscript EventType = "conversation_send_transcript"
+	EventTypeConversationTranscriptSent EventType = "conversation_transcript_sent"
+
+	// Message events
+	EventTypeMessageEdit    EventType = "message_edit"
+	EventTypeMessageDelete  EventType = "message_delete"
+	EventTypeMessageUpdated EventType = "message_updated"
+	EventTypeMessageDeleted EventType = "message_deleted"
 
 	// Contact events
 	EventTypeContactUpdated     EventType = "contact_updated"
 	EventTypeContactCreated     EventType = "contact_created"
 	EventTypeContactDeleted     EventType = "contact_deleted"
 	EventTypeContactNoteCreated EventType = "contact_note_created"
+	EventTypeContactMerged      EventType = "contact_merged"
+	EventTypeContactMergeUndone EventType = "contact_merge_undone"
 	// Inbox events
 	EventTypeInboxUpdated EventType = "inbox_updated"
 	EventTypeInboxCreated EventType = "inbox_created"
 	EventTypeInboxDeleted EventType = "inbox_deleted"
+	// Label events
+	EventTypeLabelCreated EventType = "label_created"
+	EventTypeLabelUpdated EventType = "label_updated"
+	EventTypeLabelDeleted EventType = "label_deleted"
+
+	// SLA events
+	EventTypeSLAWarning  EventType = "sla_warning"
+	EventTypeSLABreached EventType = "sla_breached"
 
 	// User notification events
 	EventTypeUserNotificationCreated EventType = "user_notification_created"
+	EventTypeMention                 EventType = "mention"
 
 	// Company events
 	EventTypeCompanyUpdated EventType = "company_updated"
 
+	// Saved view events
+	EventTypeSavedViewCounts EventType = "saved_view_counts"
+
 	// Error events
 	EventTypeError EventType = "connection_error"
 )
@@ -73,20 +108,56 @@ type IncomingStartConversationPayload struct {
 	PreChatFormData map[string]interface{} `mapstructure:"pre_chat_form_data,omitempty"`
 }
 
-type IncomingCloseConversationPayload struct {
+type IncomingConversationTransitionPayload struct {
+	ConversationID string `mapstructure:"conversation_id"`
+}
+
+type IncomingSnoozeConversationPayload struct {
+	ConversationID string `mapstructure:"conversation_id"`
+	SnoozedUntil   string `mapstructure:"snoozed_until"`
+}
+
+type IncomingConversationPriorityPayload struct {
 	ConversationID string `mapstructure:"conversation_id"`
+	Priority       string `mapstructure:"priority"`
 }
 
 type IncomingSendMessagePayload struct {
-	ConversationID string          `mapstructure:"conversation_id"`
-	Content        string          `mapstructure:"content"`
-	Type           string          `mapstructure:"type"`
-	Private        bool            `mapstructure:"private,omitempty"`
-	Metadata       json.RawMessage `mapstructure:"metadata,omitempty"`
+	ConversationID string                 `mapstructure:"conversation_id"`
+	Content        string                 `mapstructure:"content"`
+	Type           string                 `mapstructure:"type"`
+	Private        bool                   `mapstructure:"private,omitempty"`
+	Metadata       map[string]interface{} `mapstructure:"metadata,omitempty"`
+
+	ReplyToMessageID string `mapstructure:"reply_to_message_id,omitempty"`
+}
+
+type IncomingEditMessagePayload struct {
+	MessageID string `mapstructure:"message_id"`
+	Content   string `mapstructure:"content"`
+}
+
+type IncomingDeleteMessagePayload struct {
+	MessageID string `mapstructure:"message_id"`
+}
+
+type IncomingConversationReadPayload struct {
+	ConversationID string `mapstructure:"conversation_id"`
+	MessageID      string `mapstructure:"message_id,omitempty"`
+}
+
+type IncomingConversationTranscriptPayload struct {
+	ConversationID string `mapstructure:"conversation_id"`
+	// Timezone is the IANA name of the contact's timezone the message times are shown in
+	Timezone string `mapstructure:"timezone,omitempty"`
 }
 
 type IncomingGetConversationByIDPayload struct {
 	ConversationID string `mapstructure:"conversation_id"`
+	// Before and After are message IDs to load the older or newer page of messages from
+	Before string `mapstructure:"before,omitempty"`
+	After  string `mapstructure:"after,omitempty"`
+	Limit  int    `mapstructure:"limit,omitempty"`
 }
 
 type IncomingConversationTypingPayload struct {
@@ -106,6 +177,25 @@ type OutgoingMessagePayload struct {
 	SenderType     string      `json:"sender_type"`
 }
 
+type OutgoingConversationParticipantsPayload struct {
+	ConversationID string                           `json:"conversation_id"`
+	Participants   []ConversationParticipantPayload `json:"participants"`
+}
+
+type OutgoingConversationTransferPayload struct {
+	ConversationID string               `json:"conversation_id"`
+	FromInboxID    string               `json:"from_inbox_id"`
+	ToInboxID      string               `json:"to_inbox_id"`
+	Conversation   *ConversationPayload `json:"conversation"`
+}
+
+type OutgoingSLAAlertPayload struct {
+	ConversationID string               `json:"conversation_id"`
+	Target         string               `json:"target"`
+	DueAt          string               `json:"due_at"`
+	Conversation   *ConversationPayload `json:"conversation"`
+}
+
 type OutgoingUserNotificationPayload struct {
 	NotificationID string `json:"notification_id"`
 	Type           string `json:"type"`
@@ -113,6 +203,17 @@ type OutgoingUserNotificationPayload struct {
 	Read           bool   `json:"read"`
 }
 
+type OutgoingMentionPayload struct {
+	ConversationID string         `json:"conversation_id"`
+	MessageID      string         `json:"message_id"`
+	Message        MessagePayload `json:"message"`
+}
+
+// OutgoingSavedViewCountsPayload holds how many conversations match each of the agent's saved views, by view ID
+type OutgoingSavedViewCountsPayload struct {
+	Counts map[string]int64 `json:"counts"`
+}
+
 type OutgoingCreateConversationPayload = ConversationPayload
 
 type OutGoingInboxCreatedPayload struct {
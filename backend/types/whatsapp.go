@@ -0,0 +1,34 @@
+package types
+
+import "time"
+
+// InboundWhatsAppMessage represents a message received by a WhatsApp inbox
+type InboundWhatsAppMessage struct {
+	ID          string
+	From        string
+	ProfileName string
+	Timestamp   time.Time
+	Type        string
+	Text        string
+	Caption     string
+	MediaID     string
+	MimeType    string
+	Filename    string
+}
+
+// WhatsAppMessageMetadata is stored on messages that were received or sent through WhatsApp
+type WhatsAppMessageMetadata struct {
+	MessageID string `json:"message_id"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// WhatsAppTemplate is a pre-approved template message, which may be sent
+// outside the customer service window. Agents attach it to a message's
+// metadata under the "whatsapp_template" key.
+type WhatsAppTemplate struct {
+	Name       string                   `json:"name" mapstructure:"name"`
+	Language   string                   `json:"language" mapstructure:"language"`
+	Components []map[string]interface{} `json:"components,omitempty" mapstructure:"components"`
+}
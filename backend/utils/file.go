@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"live-chat-server/config"
+	"mime"
 	"os"
 	"path/filepath"
 	"strings"
@@ -50,3 +51,37 @@ func DeleteFile(filepath string) error {
 func Asset(filePath string) string {
   return config.App.BaseURL + "/" + filePath
 }
+
+// APIURL returns the absolute URL of a path served by this server
+func APIURL(path string) string {
+	return strings.TrimRight(config.App.BaseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// FrontendURL returns the absolute URL of a page of the agent dashboard
+func FrontendURL(path string) string {
+	return strings.TrimRight(config.App.FrontendURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// commonExtensions maps the usual attachment content types to their preferred extension
+var commonExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+}
+
+// ExtensionForContentType returns the preferred file extension for a content type,
+// or an empty string if it is unknown
+func ExtensionForContentType(contentType string) string {
+	if extension, ok := commonExtensions[contentType]; ok {
+		return extension
+	}
+
+	if extensions, err := mime.ExtensionsByType(contentType); err == nil && len(extensions) > 0 {
+		return extensions[0]
+	}
+
+	return ""
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	cryptorand "crypto/rand"
+	"encoding/hex"
 	"math/rand"
 )
 
@@ -13,3 +15,12 @@ func GenerateRandomString(length int) string {
 	}
 	return string(result)
 }
+
+// GenerateSecureToken generates a hex encoded token from the given number of cryptographically random bytes
+func GenerateSecureToken(length int) (string, error) {
+	tokenBytes := make([]byte, length)
+	if _, err := cryptorand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(tokenBytes), nil
+}
@@ -0,0 +1,222 @@
+package whatsapp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/types"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// GraphAPIBaseURL is the default endpoint of the WhatsApp Cloud API
+	GraphAPIBaseURL = "https://graph.facebook.com"
+	// GraphAPIVersion is the Graph API version requests are made against
+	GraphAPIVersion = "v19.0"
+	// SessionWindow is how long after the contact's last message free-form replies may be sent
+	SessionWindow = 24 * time.Hour
+	// MaxMediaSize is the largest media file downloaded from the Cloud API
+	MaxMediaSize = 100 << 20
+)
+
+// Client sends messages and downloads media through the WhatsApp Cloud API
+// for a single WhatsApp inbox
+type Client struct {
+	phoneNumberID string
+	accessToken   string
+	appSecret     string
+	baseURL       string
+	httpClient    *http.Client
+	logger        interfaces.Logger
+}
+
+// NewClient creates a new Cloud API client for the inbox's WhatsApp configuration
+func NewClient(config *models.InboxWhatsApp, logger interfaces.Logger) (*Client, error) {
+	if config == nil || config.PhoneNumberID == "" {
+		return nil, fmt.Errorf("inbox has no whatsapp configuration")
+	}
+
+	baseURL := config.APIBaseURL
+	if baseURL == "" {
+		baseURL = GraphAPIBaseURL
+	}
+
+	return &Client{
+		phoneNumberID: config.PhoneNumberID,
+		accessToken:   config.AccessToken,
+		appSecret:     config.AppSecret,
+		baseURL:       strings.TrimRight(baseURL, "/") + "/" + GraphAPIVersion,
+		httpClient:    &http.Client{Timeout: 30 * time.Second},
+		logger:        logger,
+	}, nil
+}
+
+// SendText sends a free-form text message and returns its WhatsApp message ID
+func (c *Client) SendText(to, body string) (string, error) {
+	return c.sendMessage(to, "text", map[string]interface{}{
+		"body":        body,
+		"preview_url": true,
+	})
+}
+
+// SendMedia sends an image or document hosted at link and returns its WhatsApp message ID
+func (c *Client) SendMedia(to, mediaType, link, filename string) (string, error) {
+	media := map[string]interface{}{
+		"link": link,
+	}
+	if mediaType == "document" && filename != "" {
+		media["filename"] = filename
+	}
+
+	return c.sendMessage(to, mediaType, media)
+}
+
+// SendTemplate sends a template message and returns its WhatsApp message ID
+func (c *Client) SendTemplate(to string, template types.WhatsAppTemplate) (string, error) {
+	body := map[string]interface{}{
+		"name": template.Name,
+		"language": map[string]string{
+			"code": template.Language,
+		},
+	}
+	if len(template.Components) > 0 {
+		body["components"] = template.Components
+	}
+
+	return c.sendMessage(to, "template", body)
+}
+
+// sendMessage posts a message of the given type to the phone number's messages endpoint
+func (c *Client) sendMessage(to, messageType string, body interface{}) (string, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                strings.TrimPrefix(to, "+"),
+		"type":              messageType,
+		messageType:         body,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/"+c.phoneNumberID+"/messages", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var result struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	if err := c.do(req, &result); err != nil {
+		c.logger.Error("Failed to send WhatsApp %s message to %s: %v", messageType, to, err)
+		return "", err
+	}
+
+	if len(result.Messages) == 0 {
+		return "", fmt.Errorf("whatsapp returned no message id")
+	}
+
+	return result.Messages[0].ID, nil
+}
+
+// DownloadMedia fetches the contents of a media object received in a message
+func (c *Client) DownloadMedia(mediaID string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/"+mediaID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	var media struct {
+		URL string `json:"url"`
+	}
+	if err := c.do(req, &media); err != nil {
+		return nil, fmt.Errorf("failed to get media %s: %w", mediaID, err)
+	}
+
+	req, err = http.NewRequest(http.MethodGet, media.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media %s: %w", mediaID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download media %s: status %d", mediaID, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxMediaSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media %s: %w", mediaID, err)
+	}
+
+	if len(data) > MaxMediaSize {
+		return nil, fmt.Errorf("media %s exceeds %d bytes", mediaID, MaxMediaSize)
+	}
+
+	return data, nil
+}
+
+// VerifySignature checks the X-Hub-Signature-256 header of a webhook request,
+// the hex HMAC-SHA256 of the raw body keyed with the app secret
+func (c *Client) VerifySignature(body []byte, signature string) bool {
+	if c.appSecret == "" || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(c.appSecret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// do sends an authenticated Graph API request and decodes the JSON response into out
+func (c *Client) do(req *http.Request, out interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiError struct {
+			Error struct {
+				Message string `json:"message"`
+				Code    int    `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(body, &apiError); err == nil && apiError.Error.Message != "" {
+			return fmt.Errorf("graph api returned %d: %s (code %d)", resp.StatusCode, apiError.Error.Message, apiError.Error.Code)
+		}
+		return fmt.Errorf("graph api returned %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package whatsapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"live-chat-server/types"
+	"strconv"
+	"time"
+)
+
+// webhookPayload is the body of a WhatsApp Business Account webhook notification
+type webhookPayload struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		Changes []struct {
+			Field string `json:"field"`
+			Value struct {
+				Metadata struct {
+					PhoneNumberID string `json:"phone_number_id"`
+				} `json:"metadata"`
+				Contacts []struct {
+					WaID    string `json:"wa_id"`
+					Profile struct {
+						Name string `json:"name"`
+					} `json:"profile"`
+				} `json:"contacts"`
+				Messages []webhookMessage `json:"messages"`
+			} `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+type webhookMedia struct {
+	ID       string `json:"id"`
+	MimeType string `json:"mime_type"`
+	Caption  string `json:"caption"`
+	Filename string `json:"filename"`
+}
+
+type webhookMessage struct {
+	ID        string `json:"id"`
+	From      string `json:"from"`
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"`
+	Text      struct {
+		Body string `json:"body"`
+	} `json:"text"`
+	Image    webhookMedia `json:"image"`
+	Document webhookMedia `json:"document"`
+}
+
+// ParseWebhook extracts the messages sent to the given phone number from a webhook notification.
+// Status updates and messages for other phone numbers of the account are ignored.
+func ParseWebhook(body []byte, phoneNumberID string) ([]types.InboundWhatsAppMessage, error) {
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	if payload.Object != "whatsapp_business_account" {
+		return nil, fmt.Errorf("unexpected webhook object: %s", payload.Object)
+	}
+
+	var messages []types.InboundWhatsAppMessage
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "messages" || change.Value.Metadata.PhoneNumberID != phoneNumberID {
+				continue
+			}
+
+			profileNames := make(map[string]string, len(change.Value.Contacts))
+			for _, contact := range change.Value.Contacts {
+				profileNames[contact.WaID] = contact.Profile.Name
+			}
+
+			for _, message := range change.Value.Messages {
+				inbound := types.InboundWhatsAppMessage{
+					ID:          message.ID,
+					From:        message.From,
+					ProfileName: profileNames[message.From],
+					Type:        message.Type,
+					Timestamp:   time.Now(),
+				}
+
+				if seconds, err := strconv.ParseInt(message.Timestamp, 10, 64); err == nil {
+					inbound.Timestamp = time.Unix(seconds, 0)
+				}
+
+				switch message.Type {
+				case "text":
+					inbound.Text = message.Text.Body
+				case "image":
+					inbound.MediaID = message.Image.ID
+					inbound.MimeType = message.Image.MimeType
+					inbound.Caption = message.Image.Caption
+				case "document":
+					inbound.MediaID = message.Document.ID
+					inbound.MimeType = message.Document.MimeType
+					inbound.Caption = message.Document.Caption
+					inbound.Filename = message.Document.Filename
+				}
+
+				messages = append(messages, inbound)
+			}
+		}
+	}
+
+	return messages, nil
+}
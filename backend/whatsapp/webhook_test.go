@@ -0,0 +1,183 @@
+package whatsapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"live-chat-server/interfaces"
+	"live-chat-server/models"
+	"live-chat-server/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// notification wraps a change value in a webhook notification of the WhatsApp Business Account
+func notification(value string) []byte {
+	return []byte(`{"object": "whatsapp_business_account", "entry": [{"id": "WABA1", "changes": [{"field": "messages", "value": ` + value + `}]}]}`)
+}
+
+func TestParseWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    []byte
+		want    []types.InboundWhatsAppMessage
+		wantErr bool
+	}{
+		{
+			name: "text",
+			body: notification(`{
+				"messaging_product": "whatsapp",
+				"metadata": {"display_phone_number": "15550001111", "phone_number_id": "PN1"},
+				"contacts": [{"wa_id": "15551234567", "profile": {"name": "Ada"}}],
+				"messages": [{"id": "wamid.1", "from": "15551234567", "timestamp": "1700000000", "type": "text", "text": {"body": "Hello"}}]
+			}`),
+			want: []types.InboundWhatsAppMessage{
+				{ID: "wamid.1", From: "15551234567", ProfileName: "Ada", Timestamp: time.Unix(1700000000, 0), Type: "text", Text: "Hello"},
+			},
+		},
+		{
+			name: "image",
+			body: notification(`{
+				"metadata": {"phone_number_id": "PN1"},
+				"contacts": [{"wa_id": "15551234567", "profile": {"name": "Ada"}}],
+				"messages": [{"id": "wamid.2", "from": "15551234567", "timestamp": "1700000000", "type": "image", "image": {"id": "MEDIA1", "mime_type": "image/jpeg", "caption": "Receipt"}}]
+			}`),
+			want: []types.InboundWhatsAppMessage{
+				{ID: "wamid.2", From: "15551234567", ProfileName: "Ada", Timestamp: time.Unix(1700000000, 0), Type: "image", MediaID: "MEDIA1", MimeType: "image/jpeg", Caption: "Receipt"},
+			},
+		},
+		{
+			name: "document",
+			body: notification(`{
+				"metadata": {"phone_number_id": "PN1"},
+				"messages": [{"id": "wamid.3", "from": "15551234567", "timestamp": "1700000000", "type": "document", "document": {"id": "MEDIA2", "mime_type": "application/pdf", "filename": "invoice.pdf"}}]
+			}`),
+			want: []types.InboundWhatsAppMessage{
+				{ID: "wamid.3", From: "15551234567", Timestamp: time.Unix(1700000000, 0), Type: "document", MediaID: "MEDIA2", MimeType: "application/pdf", Filename: "invoice.pdf"},
+			},
+		},
+		{
+			name: "status update",
+			body: notification(`{
+				"metadata": {"phone_number_id": "PN1"},
+				"statuses": [{"id": "wamid.4", "status": "delivered", "timestamp": "1700000000", "recipient_id": "15551234567"}]
+			}`),
+			want: nil,
+		},
+		{
+			name: "other phone number",
+			body: notification(`{
+				"metadata": {"phone_number_id": "PN2"},
+				"messages": [{"id": "wamid.5", "from": "15551234567", "timestamp": "1700000000", "type": "text", "text": {"body": "Hello"}}]
+			}`),
+			want: nil,
+		},
+		{
+			name:    "other object",
+			body:    []byte(`{"object": "page", "entry": []}`),
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			body:    []byte(`{"object": `),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWebhook(tt.body, "PN1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseWebhook() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := notification(`{"metadata": {"phone_number_id": "PN1"}, "messages": []}`)
+
+	sign := func(secret string, body []byte) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name      string
+		appSecret string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{
+			name:      "valid",
+			appSecret: "app_secret",
+			body:      body,
+			signature: sign("app_secret", body),
+			want:      true,
+		},
+		{
+			name:      "tampered body",
+			appSecret: "app_secret",
+			body:      notification(`{"metadata": {"phone_number_id": "PN2"}, "messages": []}`),
+			signature: sign("app_secret", body),
+			want:      false,
+		},
+		{
+			name:      "signed with another secret",
+			appSecret: "app_secret",
+			body:      body,
+			signature: sign("another_secret", body),
+			want:      false,
+		},
+		{
+			name:      "sha1 signature",
+			appSecret: "app_secret",
+			body:      body,
+			signature: "sha1=" + sign("app_secret", body)[len("sha256="):],
+			want:      false,
+		},
+		{
+			name:      "missing signature",
+			appSecret: "app_secret",
+			body:      body,
+			signature: "",
+			want:      false,
+		},
+		{
+			name:      "inbox without app secret",
+			appSecret: "",
+			body:      body,
+			signature: sign("", body),
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(&models.InboxWhatsApp{PhoneNumberID: "PN1", AppSecret: tt.appSecret}, nopLogger{})
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			if got := client.VerifySignature(tt.body, tt.signature); got != tt.want {
+				t.Errorf("VerifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// nopLogger discards every log entry
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{})                  {}
+func (nopLogger) Info(msg string, args ...interface{})                   {}
+func (nopLogger) Warn(msg string, args ...interface{})                   {}
+func (nopLogger) Error(msg string, args ...interface{})                  {}
+func (nopLogger) Fatal(msg string, args ...interface{})                  {}
+func (l nopLogger) With(fields map[string]interface{}) interfaces.Logger { return l }
+func (l nopLogger) Named(name string) interfaces.Logger                  { return l }
@@ -16,6 +16,8 @@ export type EventType =
   | "conversation_send_message"
   | "conversation_get_by_id"
   | "conversation_close"
+  | "conversation_send_transcript"
+  | "conversation_transcript_sent"
   | "agent_assigned"
   | "contact_updated"
   | "contact_created"
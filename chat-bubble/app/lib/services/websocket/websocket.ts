@@ -179,6 +179,19 @@ export class WebSocketService {
     this.connectionManager.send(message);
   }
 
+  public sendTranscript(conversationId: string) {
+    const payload = MessageFactory.preparePayload({
+      conversation_id: conversationId,
+      timezone: Intl.DateTimeFormat().resolvedOptions().timeZone,
+    });
+
+    const message = MessageFactory.createMessage(
+      "conversation_send_transcript",
+      payload
+    );
+    this.connectionManager.send(message);
+  }
+
   public getInboxDetails() {
     const payload = MessageFactory.preparePayload({
       inbox_id: this.connectionManager.getInboxId(),
@@ -123,7 +123,7 @@ export function Message({ message }: MessageProps) {
                 : "bg-primary text-primary-foreground rounded-tr-none"
             )}
           >
-            {message.type === "file" ? (
+            {message.type === "file" || message.type === "image" ? (
               <FileMessage
                 content={message.content}
                 metadata={message.metadata}
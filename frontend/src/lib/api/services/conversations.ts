@@ -2,9 +2,16 @@ import apiClient from "@/lib/api/client";
 import { APIResponse } from "@/lib/api/types";
 import { Agent, Conversation, Message } from "@/lib/interfaces";
 
+export interface ConversationList {
+  conversations: Conversation[];
+  counts: Record<string, number>;
+  next_cursor: string;
+  limit: number;
+}
+
 export const conversationService = {
-  async getConversations(): Promise<APIResponse<Conversation[]>> {
-    const response = await apiClient.get<APIResponse<Conversation[]>>(
+  async getConversations(): Promise<APIResponse<ConversationList>> {
+    const response = await apiClient.get<APIResponse<ConversationList>>(
       "/conversations"
     );
     return response.data;
@@ -36,7 +36,7 @@ export const useConversationsStore = create<ConversationsState>()(
 
       fetchConversations: async () => {
         const response = await conversationService.getConversations();
-        set({ conversations: response.data });
+        set({ conversations: response.data.conversations });
       },
 
       fetchConversation: async (conversationId: string) => {